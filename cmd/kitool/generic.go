@@ -0,0 +1,475 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// Generic is a generic untyped Ki node, used to load saved trees whose
+// node types are not registered in kit.Types (i.e., not compiled into
+// kitool).  It records the saved type name, and the values of all the
+// saved fields in their original order, as raw JSON, so the tree can be
+// written back out without loss.  The Props are decoded as plain values
+// for display, and the original Props JSON is kept for writing.
+type Generic struct {
+	ki.Node
+
+	// registered type name of the node as saved, e.g., ki.NodeEmbed
+	TypeName string
+
+	// the saved fields in order, including placeholders with nil Value for Nm, Props and Kids, which come from the node itself
+	Fields []Field
+
+	// the saved Props JSON, written back out as is
+	RawProps json.RawMessage
+}
+
+var KiT_Generic = kit.Types.AddType(&Generic{}, nil)
+
+// Field is a saved field of a Generic node
+type Field struct {
+	Name  string
+	Value json.RawMessage
+}
+
+// nodeFields are the saved fields that are handled by the Node itself
+var nodeFields = map[string]bool{"Nm": true, "Props": true, "Kids": true}
+
+// ValueFields returns the saved fields other than those of the Node itself
+func (gn *Generic) ValueFields() []Field {
+	var fs []Field
+	for _, f := range gn.Fields {
+		if !nodeFields[f.Name] {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+// OpenTree opens a tree saved in JSON format by ki.Node.SaveJSON, as a
+// regular typed tree if all the types in it are registered in kit.Types,
+// and otherwise as a tree of Generic nodes.  Trees saved in XML format by
+// ki.Node.WriteXML are also read, if all of their types are registered.
+func OpenTree(filename string) (ki.Ki, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ReadTree(b)
+}
+
+// ReadTree reads a tree saved in JSON or XML format from given bytes --
+// see OpenTree.
+func ReadTree(b []byte) (ki.Ki, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("<")) {
+		return ReadXMLTree(b)
+	}
+	root, types, err := ReadGeneric(b)
+	if err != nil {
+		return nil, err
+	}
+	for _, tn := range types {
+		if kit.Types.Type(tn) == nil {
+			return root, nil
+		}
+	}
+	root.Destroy()
+	troot, err := ki.ReadNewJSON(bytes.NewReader(b))
+	if errors.Is(err, ki.ErrNodeIDInUse) { // e.g., same tree opened twice
+		err = nil
+	}
+	return troot, err
+}
+
+// ReadXMLTree reads a tree saved in XML format by ki.Node.WriteXML from
+// given bytes.  The root element is named by the Go type name of the root
+// (without the package), which must be a unique registered Ki type, and
+// the types of all the other nodes must be registered as well.
+func ReadXMLTree(b []byte) (ki.Ki, error) {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	var start xml.StartElement
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, fmt.Errorf("reading XML: %w", err)
+		}
+		if st, ok := tok.(xml.StartElement); ok {
+			start = st
+			break
+		}
+	}
+	var typ reflect.Type
+	for _, tn := range sortedKeys(kit.Types.Types) {
+		t := kit.Types.Types[tn]
+		if t.Name() != start.Name.Local || !reflect.PtrTo(t).Implements(ki.KiType) {
+			continue
+		}
+		if typ != nil {
+			return nil, fmt.Errorf("XML root type %v is ambiguous: %v or %v", start.Name.Local, kit.Types.TypeName(typ), tn)
+		}
+		typ = t
+	}
+	if typ == nil {
+		return nil, fmt.Errorf("XML root type %v is not registered", start.Name.Local)
+	}
+	root := ki.NewOfType(typ)
+	ki.InitNode(root)
+	if err := root.ReadXML(bytes.NewReader(b)); err != nil && !errors.Is(err, ki.ErrNodeIDInUse) {
+		root.Destroy()
+		return nil, fmt.Errorf("reading XML: %w", err)
+	}
+	return root, nil
+}
+
+// ReadGeneric reads a tree saved in JSON format from given bytes as a tree
+// of Generic nodes, regardless of whether its types are registered,
+// returning the root and the list of type names used in the tree.
+func ReadGeneric(b []byte) (*Generic, []string, error) {
+	if !bytes.HasPrefix(b, ki.JSONTypePrefix) {
+		return nil, nil, errors.New("not a ki tree JSON file -- missing root type record at start")
+	}
+	eidx := bytes.Index(b, ki.JSONTypeSuffix)
+	var rt map[string]string
+	if err := json.Unmarshal(b[:eidx+1], &rt); err != nil {
+		return nil, nil, fmt.Errorf("invalid root type record: %w", err)
+	}
+	gr := genericReader{dec: json.NewDecoder(bytes.NewReader(b[eidx+len(ki.JSONTypeSuffix):])), types: make(map[string]bool)}
+	root := &Generic{}
+	root.InitName(root, "")
+	if err := gr.readNode(root, rt["ki.RootType"]); err != nil {
+		return nil, nil, err
+	}
+	ki.UnmarshalPost(root)
+	types := make([]string, 0, len(gr.types))
+	for tn := range gr.types {
+		types = append(types, tn)
+	}
+	return root, types, nil
+}
+
+// genericReader reads Generic nodes from a JSON token stream, which is
+// needed because the kids header records have repeated keys
+type genericReader struct {
+	dec   *json.Decoder
+	types map[string]bool
+}
+
+// delim reads the given delimiter token
+func (gr *genericReader) delim(d json.Delim) error {
+	tok, err := gr.dec.Token()
+	if err != nil {
+		return err
+	}
+	if td, ok := tok.(json.Delim); !ok || td != d {
+		return fmt.Errorf("expected %v, got: %v", d, tok)
+	}
+	return nil
+}
+
+// readNode reads the JSON object for given node of given type name
+func (gr *genericReader) readNode(gn *Generic, typeName string) error {
+	gn.TypeName = typeName
+	gr.types[typeName] = true
+	if err := gr.delim('{'); err != nil {
+		return err
+	}
+	for gr.dec.More() {
+		tok, err := gr.dec.Token()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+		gn.Fields = append(gn.Fields, Field{Name: key})
+		switch key {
+		case "Nm":
+			if err := gr.dec.Decode(&gn.Nm); err != nil {
+				return err
+			}
+		case "Props":
+			var raw json.RawMessage
+			if err := gr.dec.Decode(&raw); err != nil {
+				return err
+			}
+			var cb bytes.Buffer
+			json.Compact(&cb, raw)
+			gn.RawProps = cb.Bytes()
+			gn.Props = plainProps(gn.RawProps)
+		case "Kids":
+			if err := gr.readKids(gn); err != nil {
+				return fmt.Errorf("%v: %w", gn.Nm, err)
+			}
+		default:
+			var raw json.RawMessage
+			if err := gr.dec.Decode(&raw); err != nil {
+				return err
+			}
+			var cb bytes.Buffer // so values do not depend on indenting
+			json.Compact(&cb, raw)
+			gn.Fields[len(gn.Fields)-1].Value = cb.Bytes()
+		}
+	}
+	return gr.delim('}')
+}
+
+// readKids reads the Kids: null, or an array of a header record with the
+// number, types and names of the kids, followed by the kids
+func (gr *genericReader) readKids(gn *Generic) error {
+	tok, err := gr.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if td, ok := tok.(json.Delim); !ok || td != '[' {
+		return fmt.Errorf("expected Kids array, got: %v", tok)
+	}
+	if err := gr.delim('{'); err != nil {
+		return err
+	}
+	var types, names []string
+	for gr.dec.More() {
+		ktok, err := gr.dec.Token()
+		if err != nil {
+			return err
+		}
+		vtok, err := gr.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch ktok {
+		case "type":
+			types = append(types, fmt.Sprint(vtok))
+		case "name":
+			names = append(names, fmt.Sprint(vtok))
+		}
+	}
+	if err := gr.delim('}'); err != nil {
+		return err
+	}
+	for i := 0; gr.dec.More(); i++ {
+		if i >= len(types) {
+			return errors.New("more kids than in the Kids header")
+		}
+		kid := &Generic{}
+		kid.InitName(kid, names[i])
+		if err := gr.readNode(kid, types[i]); err != nil {
+			return err
+		}
+		gn.AddChild(kid)
+	}
+	return gr.delim(']')
+}
+
+// plainProps decodes given Props JSON into plain values, dropping the
+// type records for typed values (which may not be registered)
+func plainProps(raw json.RawMessage) ki.Props {
+	var tmp map[string]any
+	if json.Unmarshal(raw, &tmp) != nil || tmp == nil {
+		return nil
+	}
+	pr := make(ki.Props, len(tmp))
+	for k, v := range tmp {
+		if !strings.HasPrefix(k, "__type:") {
+			pr[k] = v
+		}
+	}
+	return pr
+}
+
+// WriteGenericJSON writes a tree of Generic nodes in the same JSON format
+// as ki.Node.WriteJSON.
+func WriteGenericJSON(w io.Writer, root *Generic, indent bool) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s%q}\n", ki.JSONTypePrefix, root.TypeName)
+	var body bytes.Buffer
+	writeGenericNode(&body, root)
+	if indent {
+		if err := json.Indent(&b, body.Bytes(), "", "  "); err != nil {
+			return err
+		}
+	} else {
+		b.Write(body.Bytes())
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// writeGenericNode writes the JSON object for given node
+func writeGenericNode(b *bytes.Buffer, gn *Generic) {
+	b.WriteString("{")
+	for i, f := range gn.Fields {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(b, "%q:", f.Name)
+		switch f.Name {
+		case "Nm":
+			nb, _ := json.Marshal(gn.Nm)
+			b.Write(nb)
+		case "Props":
+			if len(gn.RawProps) == 0 {
+				b.WriteString("null")
+			} else {
+				b.Write(gn.RawProps)
+			}
+		case "Kids":
+			writeGenericKids(b, gn)
+		default:
+			b.Write(f.Value)
+		}
+	}
+	b.WriteString("}")
+}
+
+// writeGenericKids writes the Kids header and kids of given node
+func writeGenericKids(b *bytes.Buffer, gn *Generic) {
+	if len(gn.Kids) == 0 {
+		b.WriteString("null")
+		return
+	}
+	fmt.Fprintf(b, `[{"n":%d`, len(gn.Kids))
+	for _, kid := range gn.Kids {
+		nb, _ := json.Marshal(kid.Name())
+		fmt.Fprintf(b, `,"type":%q,"name":%s`, kid.(*Generic).TypeName, nb)
+	}
+	b.WriteString("}")
+	for _, kid := range gn.Kids {
+		b.WriteString(",")
+		writeGenericNode(b, kid.(*Generic))
+	}
+	b.WriteString("]")
+}
+
+// WriteGenericXML writes a tree of Generic nodes in XML, following the
+// format of ki.Node.WriteXML: each node is an element named by its short
+// type name, and fields holding JSON objects are written as nested
+// elements, with array elements repeated under the field name.
+func WriteGenericXML(w io.Writer, root *Generic, indent bool) error {
+	enc := xml.NewEncoder(w)
+	if indent {
+		enc.Indent("", "  ")
+	}
+	if err := writeGenericXMLNode(enc, root, shortName(root.TypeName)); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+// shortName returns the type name without the package
+func shortName(typeName string) string {
+	return typeName[strings.LastIndex(typeName, ".")+1:]
+}
+
+// writeGenericXMLNode writes given node as an element with given name
+func writeGenericXMLNode(enc *xml.Encoder, gn *Generic, name string) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, f := range gn.Fields {
+		var err error
+		switch f.Name {
+		case "Nm":
+			err = enc.EncodeElement(gn.Nm, xml.StartElement{Name: xml.Name{Local: "Nm"}})
+		case "Props": // not saved in XML
+		case "Kids":
+			err = writeGenericXMLKids(enc, gn)
+		default:
+			err = writeXMLRaw(enc, f.Name, f.Value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// writeGenericXMLKids writes the Kids element of given node
+func writeGenericXMLKids(enc *xml.Encoder, gn *Generic) error {
+	start := xml.StartElement{Name: xml.Name{Local: "Kids"}}
+	enc.EncodeToken(start)
+	enc.EncodeElement(len(gn.Kids), xml.StartElement{Name: xml.Name{Local: "N"}})
+	for _, kid := range gn.Kids {
+		enc.EncodeElement(kid.(*Generic).TypeName, xml.StartElement{Name: xml.Name{Local: "Type"}})
+	}
+	for _, kid := range gn.Kids {
+		kg := kid.(*Generic)
+		if err := writeGenericXMLNode(enc, kg, shortName(kg.TypeName)); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// writeXMLRaw writes a JSON value as an element with given name: objects
+// that are saved Ki nodes (i.e., Ki fields) are written as nodes, other
+// objects as nested elements in order, and arrays as repeated elements.
+func writeXMLRaw(enc *xml.Encoder, name string, raw json.RawMessage) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		var obj map[string]json.RawMessage
+		json.Unmarshal(raw, &obj)
+		_, hasNm := obj["Nm"]
+		if _, hasKids := obj["Kids"]; hasNm && hasKids {
+			gn := &Generic{}
+			gn.InitName(gn, "")
+			gr := genericReader{dec: json.NewDecoder(bytes.NewReader(raw)), types: make(map[string]bool)}
+			if err := gr.readNode(gn, ""); err != nil {
+				return err
+			}
+			return writeGenericXMLNode(enc, gn, name)
+		}
+		enc.EncodeToken(start)
+		for dec.More() {
+			ktok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			var sub json.RawMessage
+			if err := dec.Decode(&sub); err != nil {
+				return err
+			}
+			if err := writeXMLRaw(enc, ktok.(string), sub); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	case json.Delim('['):
+		for dec.More() {
+			var sub json.RawMessage
+			if err := dec.Decode(&sub); err != nil {
+				return err
+			}
+			if err := writeXMLRaw(enc, name, sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	case nil:
+		enc.EncodeToken(start)
+		return enc.EncodeToken(start.End())
+	}
+	return enc.EncodeElement(tok, start)
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+type testNode struct {
+	ki.Node
+	Val int
+	Str string
+}
+
+var KiT_testNode = kit.Types.AddType(&testNode{}, nil)
+
+// saveTestTree saves a test tree to a file in dir, with the type names
+// replaced by unknown ones if generic, and returns the file name
+func saveTestTree(t *testing.T, dir, name string, val int, generic bool) string {
+	root := &testNode{}
+	root.InitName(root, "root")
+	for i, nm := range []string{"a", "b", "c"} {
+		kid := root.AddNewChild(KiT_testNode, nm).(*testNode)
+		kid.Val = i
+		kid.Str = nm
+		kid.SetProp("idx", i)
+	}
+	root.Child(1).(*testNode).Val = val
+	root.Child(2).AddNewChild(KiT_testNode, "d")
+	var b bytes.Buffer
+	if err := root.WriteJSON(&b, ki.Indent); err != nil {
+		t.Fatal(err)
+	}
+	str := b.String()
+	if generic {
+		str = strings.ReplaceAll(str, "kitool.testNode", "other.Unknown")
+	}
+	fnm := filepath.Join(dir, name)
+	if err := os.WriteFile(fnm, []byte(str), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fnm
+}
+
+func runTest(args ...string) (string, int) {
+	var out, errs bytes.Buffer
+	st := run(args, &out, &errs)
+	return out.String() + errs.String(), st
+}
+
+func TestGenericRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	fnm := saveTestTree(t, dir, "g.json", 1, true)
+	orig, _ := os.ReadFile(fnm)
+	root, err := OpenTree(fnm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gn, ok := root.(*Generic)
+	if !ok {
+		t.Fatalf("expected Generic tree, got: %T", root)
+	}
+	if gn.TypeName != "other.Unknown" || gn.NumChildren() != 3 {
+		t.Errorf("generic root: %v %v", gn.TypeName, gn.NumChildren())
+	}
+	var b bytes.Buffer
+	if err := WriteGenericJSON(&b, gn, true); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != string(orig) {
+		t.Errorf("generic round trip differs:\n%v\nvs:\n%v", b.String(), string(orig))
+	}
+
+	typed, err := OpenTree(saveTestTree(t, dir, "t.json", 1, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := typed.(*testNode); !ok {
+		t.Errorf("expected typed tree, got: %T", typed)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	dir := t.TempDir()
+	a := saveTestTree(t, dir, "a.json", 1, false)
+	b := saveTestTree(t, dir, "b.json", 5, false)
+	g := saveTestTree(t, dir, "g.json", 1, true)
+
+	out, st := runTest("ls", a)
+	if st != 0 || !strings.Contains(out, "c") || strings.Count(out, "\n") != 3 {
+		t.Errorf("ls: %v %v", st, out)
+	}
+	out, st = runTest("cat", g, "/root/b")
+	if st != 0 || !strings.Contains(out, "Val") || !strings.Contains(out, "idx") {
+		t.Errorf("cat: %v %v", st, out)
+	}
+	if out, st = runTest("validate", a); st != 0 || out != "ok: 5 nodes\n" {
+		t.Errorf("validate: %v %v", st, out)
+	}
+	if out, st = runTest("stats", g); st != 0 || !regexp.MustCompile(`nodes +5\n`).MatchString(out) || !regexp.MustCompile(`other.Unknown +5\n`).MatchString(out) {
+		t.Errorf("stats: %v %v", st, out)
+	}
+	if out, st = runTest("diff", a, a); st != 0 || out != "" {
+		t.Errorf("diff same: %v %v", st, out)
+	}
+	if out, st = runTest("diff", a, b); st != 1 || !strings.Contains(out, "~ /root/b: field Val: 1 -> 5") {
+		t.Errorf("diff: %v %v", st, out)
+	}
+	if _, st = runTest("cat", a, "/root/nope"); st != 2 {
+		t.Errorf("cat of missing path should fail: %v", st)
+	}
+	if _, st = runTest("bogus"); st != 2 {
+		t.Errorf("unknown command should fail: %v", st)
+	}
+
+	xfn := filepath.Join(dir, "g.xml")
+	if out, st = runTest("convert", g, xfn); st != 0 {
+		t.Fatalf("convert: %v %v", st, out)
+	}
+	xb, _ := os.ReadFile(xfn)
+	if !strings.HasPrefix(string(xb), "<Unknown>") || !strings.Contains(string(xb), "<Val>1</Val>") {
+		t.Errorf("convert xml: %v", string(xb))
+	}
+	out, st = runTest("convert", "-format", "json", g)
+	if st != 0 || strings.Count(strings.TrimSpace(out), "\n") != 1 {
+		t.Errorf("convert json: %v %v", st, out)
+	}
+}
+
+func TestXMLRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	a := saveTestTree(t, dir, "a.json", 1, false)
+	xfn := filepath.Join(dir, "a.xml")
+	if out, st := runTest("convert", a, xfn); st != 0 {
+		t.Fatalf("convert to xml: %v %v", st, out)
+	}
+	root, err := OpenTree(xfn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tn, ok := root.(*testNode)
+	if !ok {
+		t.Fatalf("expected typed tree, got: %T", root)
+	}
+	if tn.NumChildren() != 3 || tn.Child(1).(*testNode).Val != 1 || tn.Child(2).(*testNode).Str != "c" {
+		t.Errorf("xml tree: %v", tn.Kids)
+	}
+	// props are not saved in XML
+	if out, st := runTest("diff", a, xfn); st != 1 || strings.Count(out, "prop idx removed\n") != 3 || strings.Count(out, "\n") != 3 {
+		t.Errorf("diff json vs xml: %v %v", st, out)
+	}
+	if _, st := runTest("convert", "-format", "json", filepath.Join(dir, "nope.xml")); st == 0 {
+		t.Errorf("convert of missing file should fail")
+	}
+	os.WriteFile(xfn, []byte("<Unknown></Unknown>"), 0644)
+	if _, err := OpenTree(xfn); err == nil {
+		t.Errorf("xml of unregistered type should fail")
+	}
+}
@@ -0,0 +1,432 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command kitool inspects, validates, converts and diffs Ki trees saved in
+// JSON format by ki.Node.SaveJSON, or in XML format by ki.Node.WriteXML.
+// JSON trees with node types that are not compiled into kitool are loaded
+// as trees of Generic untyped nodes, which preserve all the saved
+// information.
+//
+// Usage:
+//
+//	kitool ls <file> [path]        list the children of the node at path
+//	kitool cat <file> [path]       show the fields and props of the node at path
+//	kitool validate <file>         check the integrity of the tree
+//	kitool convert [-format f] <in> [out]
+//	                               convert to json, indent (indented json) or xml
+//	kitool diff <a> <b>            show the differences between two trees
+//	kitool stats [-json] <file>    show statistics about the tree
+//
+// Paths are ki paths (see ki.Node.FindPath), either starting with the
+// root name, as in /root/child, or relative to the root.  The default
+// path is the root.  Files starting with < are read as XML, which is only
+// supported for trees of node types compiled into kitool, and does not
+// include node properties.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// usage is the usage message
+const usage = `usage: kitool <command> [arguments]
+
+commands:
+  ls <file> [path]                     list the children of the node at path
+  cat <file> [path]                    show the fields and props of the node at path
+  validate <file>                      check the integrity of the tree
+  convert [-format json|indent|xml] <in> [out]
+                                       convert the tree (default format: indent,
+                                       or xml for an out file ending in .xml)
+  diff <a> <b>                         show the differences between two trees
+  stats [-json] <file>                 show statistics about the tree
+`
+
+// errDiffers is returned by commands that succeed but should exit with
+// status 1, e.g., diff when the trees differ
+var errDiffers = errors.New("differences found")
+
+// run runs the command in given args, returning the exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	cmds := map[string]func([]string, io.Writer) error{
+		"ls":       cmdLs,
+		"cat":      cmdCat,
+		"validate": cmdValidate,
+		"convert":  cmdConvert,
+		"diff":     cmdDiff,
+		"stats":    cmdStats,
+	}
+	cmd, ok := cmds[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "kitool: unknown command: %v\n\n%v", args[0], usage)
+		return 2
+	}
+	err := cmd(args[1:], stdout)
+	switch {
+	case err == nil:
+		return 0
+	case err == errDiffers:
+		return 1
+	}
+	fmt.Fprintf(stderr, "kitool %v: %v\n", args[0], err)
+	return 2
+}
+
+// openNode opens the tree in given file and returns the node at given
+// path, or the root if path is empty
+func openNode(filename, path string) (ki.Ki, error) {
+	root, err := OpenTree(filename)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return root, nil
+	}
+	k := root.FindPath(path)
+	if k == nil {
+		return nil, fmt.Errorf("path not found: %v", path)
+	}
+	return k, nil
+}
+
+// fileAndPath returns the file and optional path arguments
+func fileAndPath(args []string) (string, string, error) {
+	switch len(args) {
+	case 1:
+		return args[0], "", nil
+	case 2:
+		return args[0], args[1], nil
+	}
+	return "", "", errors.New("expected <file> [path]")
+}
+
+func cmdLs(args []string, w io.Writer) error {
+	fnm, path, err := fileAndPath(args)
+	if err != nil {
+		return err
+	}
+	k, err := openNode(fnm, path)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, kid := range *k.Children() {
+		fmt.Fprintf(tw, "%v\t%v\t%d\n", kid.Name(), typeName(kid), kid.NumChildren())
+	}
+	return tw.Flush()
+}
+
+func cmdCat(args []string, w io.Writer) error {
+	fnm, path, err := fileAndPath(args)
+	if err != nil {
+		return err
+	}
+	k, err := openNode(fnm, path)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "path: %v\ntype: %v\nchildren: %d\n", k.Path(), typeName(k), k.NumChildren())
+	if fs := valueFields(k); len(fs) > 0 {
+		fmt.Fprintf(w, "fields:\n")
+		for _, f := range fs {
+			fmt.Fprintf(w, "  %v: %s\n", f.Name, f.Value)
+		}
+	}
+	if pr := propsJSON(k); len(pr) > 0 {
+		fmt.Fprintf(w, "props:\n")
+		for _, key := range sortedKeys(pr) {
+			fmt.Fprintf(w, "  %v: %v\n", key, pr[key])
+		}
+	}
+	return nil
+}
+
+func cmdValidate(args []string, w io.Writer) error {
+	if len(args) != 1 {
+		return errors.New("expected <file>")
+	}
+	root, err := OpenTree(args[0])
+	if err != nil {
+		return err
+	}
+	viols := ki.Validate(root)
+	for i := range viols {
+		fmt.Fprintln(w, viols[i].String())
+	}
+	if len(viols) > 0 {
+		return fmt.Errorf("%d problems found", len(viols))
+	}
+	nodes := 0
+	root.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d any) bool {
+		nodes++
+		return ki.Continue
+	})
+	fmt.Fprintf(w, "ok: %d nodes\n", nodes)
+	return nil
+}
+
+func cmdConvert(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := fs.String("format", "", "output format: json, indent or xml")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("expected [-format json|indent|xml] <in> [out]")
+	}
+	if *format == "" {
+		*format = "indent"
+		if len(args) == 2 && strings.EqualFold(filepath.Ext(args[1]), ".xml") {
+			*format = "xml"
+		}
+	}
+	root, err := OpenTree(args[0])
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	gn, generic := root.(*Generic)
+	switch *format {
+	case "json", "indent":
+		if generic {
+			err = WriteGenericJSON(&b, gn, *format == "indent")
+		} else {
+			err = root.WriteJSON(&b, *format == "indent")
+		}
+	case "xml":
+		if generic {
+			err = WriteGenericXML(&b, gn, true)
+		} else {
+			err = root.WriteXML(&b, true)
+		}
+		b.WriteString("\n")
+	default:
+		return fmt.Errorf("unknown format: %v", *format)
+	}
+	if err != nil {
+		return err
+	}
+	if len(args) == 2 {
+		return os.WriteFile(args[1], b.Bytes(), 0644)
+	}
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+func cmdDiff(args []string, w io.Writer) error {
+	if len(args) != 2 {
+		return errors.New("expected <a> <b>")
+	}
+	a, err := OpenTree(args[0])
+	if err != nil {
+		return err
+	}
+	b, err := OpenTree(args[1])
+	if err != nil {
+		return err
+	}
+	if diffTrees(w, a, b) > 0 {
+		return errDiffers
+	}
+	return nil
+}
+
+func cmdStats(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "output the stats as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected [-json] <file>")
+	}
+	root, err := OpenTree(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	ts := ki.Stats(root)
+	if _, generic := root.(*Generic); generic { // count the saved types
+		ts.Types = make(map[string]int)
+		root.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d any) bool {
+			ts.Types[typeName(k)]++
+			return ki.Continue
+		})
+		for i := range ts.Subtrees {
+			ts.Subtrees[i].Type = typeName(root.FindPath(ts.Subtrees[i].Path))
+		}
+	}
+	if *asJSON {
+		b, err := ts.JSON()
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	}
+	_, err = io.WriteString(w, ts.Table())
+	return err
+}
+
+// diffTrees writes the differences between the two trees, matching nodes
+// by path, and returns the number of differences
+func diffTrees(w io.Writer, a, b ki.Ki) int {
+	ndiff := 0
+	diff := func(format string, args ...any) {
+		ndiff++
+		fmt.Fprintf(w, format+"\n", args...)
+	}
+	var walk func(pth string, a, b ki.Ki)
+	walk = func(pth string, a, b ki.Ki) {
+		if ta, tb := typeName(a), typeName(b); ta != tb {
+			diff("~ %v: type %v -> %v", pth, ta, tb)
+			return
+		}
+		af, bf := valueFields(a), valueFields(b)
+		bfm := make(map[string]string, len(bf))
+		for _, f := range bf {
+			bfm[f.Name] = string(f.Value)
+		}
+		for _, f := range af {
+			if bv, has := bfm[f.Name]; !has {
+				diff("~ %v: field %v removed", pth, f.Name)
+			} else if bv != string(f.Value) {
+				diff("~ %v: field %v: %s -> %s", pth, f.Name, f.Value, bv)
+			}
+			delete(bfm, f.Name)
+		}
+		for _, f := range bf {
+			if _, has := bfm[f.Name]; has {
+				diff("~ %v: field %v added: %s", pth, f.Name, f.Value)
+			}
+		}
+		ap, bp := propsJSON(a), propsJSON(b)
+		for _, key := range sortedKeys(ap) {
+			if bv, has := bp[key]; !has {
+				diff("~ %v: prop %v removed", pth, key)
+			} else if bv != ap[key] {
+				diff("~ %v: prop %v: %v -> %v", pth, key, ap[key], bv)
+			}
+		}
+		for _, key := range sortedKeys(bp) {
+			if _, has := ap[key]; !has {
+				diff("~ %v: prop %v added: %v", pth, key, bp[key])
+			}
+		}
+		var common []string
+		for _, kid := range *a.Children() {
+			kp := pth + "/" + ki.EscapePathName(kid.Name())
+			if bk := b.ChildByName(kid.Name(), 0); bk != nil {
+				common = append(common, kid.Name())
+				walk(kp, kid, bk)
+			} else {
+				diff("- %v (%v)", kp, typeName(kid))
+			}
+		}
+		var bcommon []string
+		for _, kid := range *b.Children() {
+			if a.ChildByName(kid.Name(), 0) == nil {
+				diff("+ %v (%v)", pth+"/"+ki.EscapePathName(kid.Name()), typeName(kid))
+			} else {
+				bcommon = append(bcommon, kid.Name())
+			}
+		}
+		if !reflect.DeepEqual(common, bcommon) {
+			diff("~ %v: children reordered: %v -> %v", pth, strings.Join(common, ","), strings.Join(bcommon, ","))
+		}
+	}
+	walk("/"+ki.EscapePathName(a.Name()), a, b)
+	return ndiff
+}
+
+// typeName returns the registered type name of given node, or the saved
+// type name for a Generic node
+func typeName(k ki.Ki) string {
+	if gn, ok := k.(*Generic); ok {
+		return gn.TypeName
+	}
+	return kit.Types.TypeName(ki.Type(k))
+}
+
+// valueFields returns the fields of given node other than those of the Node
+// itself, with values as JSON, in the same form as for Generic nodes
+func valueFields(k ki.Ki) []Field {
+	if gn, ok := k.(*Generic); ok {
+		return gn.ValueFields()
+	}
+	var fs []Field
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		typ := v.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.PkgPath != "" || f.Tag.Get("json") == "-" || f.Type == ki.KiT_Signal {
+				continue
+			}
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				if kit.ShortTypeName(f.Type) != "ki.Node" {
+					walk(v.Field(i))
+				}
+				continue
+			}
+			jb, err := json.Marshal(kit.PtrValue(v.Field(i)).Interface())
+			if err != nil {
+				jb = []byte(fmt.Sprintf("%q", err.Error()))
+			}
+			fs = append(fs, Field{Name: f.Name, Value: jb})
+		}
+	}
+	walk(reflect.ValueOf(k).Elem())
+	return fs
+}
+
+// propsJSON returns the props of given node with values as JSON
+func propsJSON(k ki.Ki) map[string]string {
+	pr := *k.Properties()
+	if len(pr) == 0 {
+		return nil
+	}
+	pj := make(map[string]string, len(pr))
+	for key, val := range pr {
+		jb, err := json.Marshal(val)
+		if err != nil {
+			jb = []byte(fmt.Sprintf("%v", val))
+		}
+		pj[key] = string(jb)
+	}
+	return pj
+}
+
+// sortedKeys returns the sorted keys of given map
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
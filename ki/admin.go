@@ -9,7 +9,6 @@ import (
 	"log"
 	"reflect"
 	"strings"
-	"sync"
 	"unsafe"
 
 	"github.com/goki/ki/kit"
@@ -34,6 +33,9 @@ func InitNode(this Ki) {
 		n.Ths.OnInit()
 		KiInitKiFields(this)
 	}
+	if UseNodeIDs && n.UID == 0 {
+		registerNodeID(n, 0)
+	}
 }
 
 // ThisCheck checks that the This pointer is set and issues a warning to
@@ -53,7 +55,16 @@ func ThisCheck(k Ki) error {
 // Assumes not already in a tree or anything.
 func SetParent(kid Ki, parent Ki) {
 	n := kid.AsNode()
+	if parent != nil {
+		setTreeMu(n, parent.AsNode().treeMu) // no-op if already added by parent
+	}
+	mu := n.lockTree()
 	n.Par = parent
+	unlockTree(mu)
+	n.propsChanged() // inherited props
+	if parent != nil {
+		transferDelMgr(n) // no longer a root
+	}
 	kid.OnAdd()
 	n.FuncUpParent(0, nil, func(k Ki, level int, data any) bool {
 		k.OnChildAdded(kid)
@@ -61,10 +72,10 @@ func SetParent(kid Ki, parent Ki) {
 	})
 	if parent != nil && !parent.OnlySelfUpdate() {
 		parup := parent.IsUpdating()
-		n.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		n.funcDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
 			k.SetFlagState(parup, int(Updating))
 			return true
-		})
+		}, false)
 	}
 }
 
@@ -121,10 +132,10 @@ func UpdateReset(kn Ki) {
 	if kn.OnlySelfUpdate() {
 		kn.ClearFlag(int(Updating))
 	} else {
-		kn.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		kn.AsNode().funcDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
 			k.ClearFlag(int(Updating))
 			return true
-		})
+		}, false)
 	}
 }
 
@@ -229,8 +240,13 @@ func KiField(n *Node, idx int) Ki {
 	if idx >= len(foffs) || idx < 0 {
 		return nil
 	}
-	fn := (*Node)(unsafe.Pointer(uintptr(unsafe.Pointer(n)) + foffs[idx]))
-	return fn.This()
+	return kiFieldNode(n, foffs[idx]).This()
+}
+
+// kiFieldNode returns the Node of the Ki field at given offset within the
+// struct that n is embedded in (n must be at the start of the struct)
+func kiFieldNode(n *Node, off uintptr) *Node {
+	return (*Node)(unsafe.Add(unsafe.Pointer(n), off))
 }
 
 // KiFieldByName returns field Ki element by name -- returns false if not found.
@@ -238,10 +254,8 @@ func KiFieldByName(n *Node, name string) Ki {
 	if !KiHasKiFields(n) {
 		return nil
 	}
-	foffs := KiFieldOffs(n)
-	op := uintptr(unsafe.Pointer(n))
-	for _, fo := range foffs {
-		fn := (*Node)(unsafe.Pointer(op + fo))
+	for _, fo := range KiFieldOffs(n) {
+		fn := kiFieldNode(n, fo)
 		if fn.Nm == name {
 			return fn.This()
 		}
@@ -436,42 +450,3 @@ func UniquifyNamesAll(kn Ki) {
 		return Continue
 	})
 }
-
-//////////////////////////////////////////////////////////////////////////////
-//  Deletion manager
-
-// Deleted manages all the deleted Ki elements, that are destined to then be
-// destroyed, without having an additional pointer on the Ki object
-type Deleted struct {
-	Dels []Ki
-	Mu   sync.Mutex
-}
-
-// DelMgr is the manager of all deleted items
-var DelMgr = Deleted{}
-
-// Add the Ki elements to the deleted list
-func (dm *Deleted) Add(kis ...Ki) {
-	dm.Mu.Lock()
-	if dm.Dels == nil {
-		dm.Dels = make([]Ki, 0)
-	}
-	dm.Dels = append(dm.Dels, kis...)
-	dm.Mu.Unlock()
-}
-
-// DestroyDeleted destroys any deleted items in list
-func (dm *Deleted) DestroyDeleted() {
-	// pr := prof.Start("ki.DestroyDeleted")
-	// defer pr.End()
-	dm.Mu.Lock()
-	curdels := dm.Dels
-	dm.Dels = make([]Ki, 0)
-	dm.Mu.Unlock()
-	for _, k := range curdels {
-		if k == nil {
-			continue
-		}
-		k.Destroy() // destroy will add to the dels so we need to do this outside of lock
-	}
-}
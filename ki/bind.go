@@ -0,0 +1,177 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/goki/ki/kit"
+)
+
+// BindModes are the modes of a Binding between fields of two nodes
+type BindModes int32
+
+//go:generate stringer -type=BindModes
+
+var KiT_BindModes = kit.Enums.AddEnum(BindModesN, kit.NotBitFlag, nil)
+
+const (
+	// BindOneWay sets the destination field when the source field changes.
+	BindOneWay BindModes = iota
+
+	// BindTwoWay also sets the source field when the destination field
+	// changes.
+	BindTwoWay
+
+	BindModesN
+)
+
+// BindConvertFunc converts a value of the field on one side of a Binding
+// to the value to set on the other side -- the returned value is set
+// using kit.SetRobust, so it only needs to be convertible to the field.
+type BindConvertFunc func(val any) (any, error)
+
+// BindOpts are the options for a Binding, see Bind
+type BindOpts struct {
+
+	// whether changes are propagated in one or both directions
+	Mode BindModes `desc:"whether changes are propagated in one or both directions"`
+
+	// converts source values to destination values -- if nil, values are converted by kit.SetRobust
+	Convert BindConvertFunc `desc:"converts source values to destination values -- if nil, values are converted by kit.SetRobust"`
+
+	// converts destination values to source values, for BindTwoWay -- if nil, values are converted by kit.SetRobust
+	ConvertBack BindConvertFunc `desc:"converts destination values to source values, for BindTwoWay -- if nil, values are converted by kit.SetRobust"`
+
+	// do not set the destination field from the source field when binding
+	NoInit bool `desc:"do not set the destination field from the source field when binding"`
+}
+
+// Binding keeps a field of a destination node in sync with a field of a
+// source node, and the reverse for BindTwoWay, see Bind.
+type Binding struct {
+
+	// source node
+	Src Ki `desc:"source node"`
+
+	// field name or path of the source node
+	SrcPath string `desc:"field name or path of the source node"`
+
+	// destination node
+	Dst Ki `desc:"destination node"`
+
+	// field name or path of the destination node
+	DstPath string `desc:"field name or path of the destination node"`
+
+	// options of the binding
+	Opts BindOpts `desc:"options of the binding"`
+
+	// guards the state of the binding
+	mu sync.Mutex
+
+	// field watcher IDs on the source and destination nodes
+	srcID, dstID int
+
+	// whether the binding is setting a field, to prevent loops
+	setting bool
+
+	// whether the binding was removed
+	unbound bool
+}
+
+// Bind binds the field at dstPath of dst to the field at srcPath of src,
+// setting the destination field whenever the source field changes, and
+// also the reverse if Mode is BindTwoWay.  Field paths are field names or
+// dot-separated paths into struct fields, as for WatchField, which is used
+// to detect changes, so changes are propagated when made by SetField,
+// CopyFrom or ReadJSON, or reported by MarkFieldChanged.  Fields are set
+// with SetField, after converting values with the Convert or ConvertBack
+// option if set.  Changes made while propagating a change are not
+// propagated back, to prevent loops.  Unless NoInit is set, the
+// destination field is set from the source field initially.  The binding
+// is removed when either node is destroyed, or by calling Unbind.
+func Bind(src Ki, srcPath string, dst Ki, dstPath string, opts BindOpts) (*Binding, error) {
+	if _, err := fieldPathValue(dst, dstPath); err != nil {
+		return nil, fmt.Errorf("ki.Bind: destination: %v", err)
+	}
+	b := &Binding{Src: src, SrcPath: srcPath, Dst: dst, DstPath: dstPath, Opts: opts}
+	srcID, err := watchField(src, srcPath, func(k Ki, path string, old, new any) {
+		b.propagate(new, false)
+	}, b.Unbind)
+	if err != nil {
+		return nil, fmt.Errorf("ki.Bind: source: %v", err)
+	}
+	// the destination is also watched for one-way, to unbind when destroyed
+	dstID, _ := watchField(dst, dstPath, func(k Ki, path string, old, new any) {
+		if opts.Mode == BindTwoWay {
+			b.propagate(new, true)
+		}
+	}, b.Unbind)
+	b.mu.Lock()
+	b.srcID, b.dstID = srcID, dstID
+	b.mu.Unlock()
+	if !opts.NoInit {
+		val, _ := fieldWatchValue(src, srcPath)
+		b.propagate(val, false)
+	}
+	return b, nil
+}
+
+// propagate sets the field on the other side of the binding to given
+// value of the field on one side (the destination if back)
+func (b *Binding) propagate(val any, back bool) {
+	b.mu.Lock()
+	if b.setting || b.unbound {
+		b.mu.Unlock()
+		return
+	}
+	b.setting = true
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.setting = false
+		b.mu.Unlock()
+	}()
+
+	to, path, conv := b.Dst, b.DstPath, b.Opts.Convert
+	if back {
+		to, path, conv = b.Src, b.SrcPath, b.Opts.ConvertBack
+	}
+	if conv != nil {
+		cv, err := conv(val)
+		if err != nil {
+			log.Printf("ki.Binding: converting value for %v on node %v: %v\n", path, to.Name(), err)
+			return
+		}
+		val = cv
+	}
+	if err := to.SetField(path, val); err != nil {
+		log.Printf("ki.Binding: %v\n", err)
+	}
+}
+
+// Unbind removes the binding, so changes are no longer propagated.
+func (b *Binding) Unbind() {
+	b.mu.Lock()
+	if b.unbound {
+		b.mu.Unlock()
+		return
+	}
+	b.unbound = true
+	srcID, dstID := b.srcID, b.dstID
+	b.srcID, b.dstID = 0, 0
+	b.mu.Unlock()
+	UnwatchField(b.Src, srcID)
+	UnwatchField(b.Dst, dstID)
+}
+
+// IsBound returns true if the binding has not been removed.
+func (b *Binding) IsBound() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.unbound
+}
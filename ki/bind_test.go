@@ -0,0 +1,85 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestBind(t *testing.T) {
+	par := &Node{}
+	par.InitName(par, "par")
+	model := par.AddNewChild(KiT_watchTestNode, "model").(*watchTestNode)
+	view := par.AddNewChild(KiT_watchTestNode, "view").(*watchTestNode)
+	model.Size = 4
+
+	// one-way, with default conversion from int to float32
+	b1, err := Bind(model, "Size", view, "Pos.X", BindOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.Pos.X != 4 {
+		t.Errorf("initial value not set: %v", view.Pos.X)
+	}
+	model.SetField("Size", 5)
+	view.SetField("Pos.X", 7) // one-way: not propagated back
+	if view.Pos.X != 7 || model.Size != 5 {
+		t.Errorf("one-way binding: %v %v", view.Pos.X, model.Size)
+	}
+	b1.Unbind()
+	model.SetField("Size", 6)
+	if view.Pos.X != 7 || NumFieldWatchers(model) != 0 {
+		t.Errorf("Unbind: %v", view.Pos.X)
+	}
+
+	// two-way, with converters
+	b2, err := Bind(model, "Size", view, "Nm", BindOpts{Mode: BindTwoWay,
+		Convert: func(val any) (any, error) {
+			return fmt.Sprintf("size%d", val.(int)), nil
+		},
+		ConvertBack: func(val any) (any, error) {
+			return strconv.Atoi(val.(string)[4:])
+		}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.Name() != "size6" {
+		t.Errorf("initial value not converted: %v", view.Name())
+	}
+	view.SetField("Nm", "size9")
+	if model.Size != 9 {
+		t.Errorf("two-way binding back: %v", model.Size)
+	}
+	model.Size = 2
+	MarkFieldChanged(model, "Size")
+	if view.Name() != "size2" {
+		t.Errorf("two-way binding: %v", view.Name())
+	}
+
+	// loops between bindings are stopped
+	b3, _ := Bind(view, "Pos.Y", model, "Pos.Y", BindOpts{NoInit: true})
+	b4, _ := Bind(model, "Pos.Y", view, "Pos.Y", BindOpts{Convert: func(val any) (any, error) {
+		return val.(float32) + 1, nil
+	}})
+	view.SetField("Pos.Y", 3) // b3 sets model, b4 sets view, b3 stops
+	if model.Pos.Y != 3 || view.Pos.Y != 4 {
+		t.Errorf("binding loop: %v %v", model.Pos.Y, view.Pos.Y)
+	}
+
+	if _, err := Bind(model, "Nope", view, "Size", BindOpts{}); err == nil {
+		t.Errorf("binding missing field should fail")
+	}
+
+	view.Destroy()
+	if b2.IsBound() || b3.IsBound() || b4.IsBound() || NumFieldWatchers(model) != 0 {
+		t.Errorf("bindings should be removed when a node is destroyed: %v", NumFieldWatchers(model))
+	}
+	par.Destroy()
+	if fieldWatch.n != 0 {
+		t.Errorf("field watchers leaked: %v", fieldWatch.n)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"sync"
+
+	"github.com/goki/ki/kit"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//  Deletion manager
+
+// DestroyTimings determine when a Deleted manager destroys the nodes that
+// were deleted with destroy = true.
+type DestroyTimings int32
+
+//go:generate stringer -type=DestroyTimings
+
+var KiT_DestroyTimings = kit.Enums.AddEnum(DestroyTimingsN, kit.NotBitFlag, nil)
+
+const (
+	// DestroyAtUpdateEnd destroys deleted nodes at the UpdateEnd of the
+	// update in which they were deleted -- this is the default.
+	DestroyAtUpdateEnd DestroyTimings = iota
+
+	// DestroyImmediate destroys nodes as soon as they are deleted.
+	DestroyImmediate
+
+	// DestroyManual only destroys deleted nodes when DestroyDeleted is
+	// called.
+	DestroyManual
+
+	DestroyTimingsN
+)
+
+// Deleted manages the deleted Ki elements of a tree, that are destined to
+// then be destroyed, without having an additional pointer on the Ki
+// object.  Each tree has its own manager, owned by its root node and
+// obtained with DeleteManager, so that updates on one tree never destroy
+// the nodes deleted from another.
+type Deleted struct {
+	Dels []Ki
+	Mu   sync.Mutex
+
+	// when deleted nodes are destroyed
+	Timing DestroyTimings
+
+	// functions called for each node in the subtree of each deleted node,
+	// parents before children, just before the subtree is destroyed
+	OnDestroy []func(k Ki)
+}
+
+// DelMgr is the manager of deleted items that do not belong to any tree,
+// e.g., those deleted by Slice.Config on a Slice without a parent node.
+var DelMgr = Deleted{}
+
+// delMgrMu protects the creation and transfer of per-tree managers
+var delMgrMu sync.Mutex
+
+// DeleteManager returns the deletion manager of the tree containing given
+// node, owned by its root, making it if not yet made.  When a root is
+// added to another tree, its manager is discarded, and any nodes still
+// pending destruction are passed on to the manager of the new tree.
+func DeleteManager(k Ki) *Deleted {
+	return treeDelMgr(k, true)
+}
+
+// treeDelMgr returns the deletion manager of the tree containing given
+// node, optionally making it if not yet made -- returns DelMgr for nil
+// or destroyed nodes, and nil if not made and !makeNew
+func treeDelMgr(k Ki, makeNew bool) *Deleted {
+	if k == nil || k.This() == nil {
+		return &DelMgr
+	}
+	root := Root(k)
+	if root == nil {
+		return &DelMgr
+	}
+	rn := root.AsNode()
+	delMgrMu.Lock()
+	defer delMgrMu.Unlock()
+	if rn.delMgr == nil && makeNew {
+		rn.delMgr = &Deleted{}
+	}
+	return rn.delMgr
+}
+
+// transferDelMgr passes the pending deleted nodes of given node, if it
+// was the root of a tree with a manager, to the manager of its new tree
+func transferDelMgr(n *Node) {
+	delMgrMu.Lock()
+	dm := n.delMgr
+	n.delMgr = nil
+	delMgrMu.Unlock()
+	if dm == nil {
+		return
+	}
+	dm.Mu.Lock()
+	dels := dm.Dels
+	dm.Dels = nil
+	dm.Mu.Unlock()
+	if len(dels) > 0 {
+		DeleteManager(n).Add(dels...)
+	}
+}
+
+// SetTiming sets when deleted nodes are destroyed -- any pending nodes
+// are destroyed if changing to DestroyImmediate
+func (dm *Deleted) SetTiming(timing DestroyTimings) {
+	dm.Mu.Lock()
+	dm.Timing = timing
+	dm.Mu.Unlock()
+	if timing == DestroyImmediate {
+		dm.DestroyDeleted()
+	}
+}
+
+// AddOnDestroy adds a function to be called for each node in the subtree
+// of each deleted node, just before it is destroyed
+func (dm *Deleted) AddOnDestroy(fun func(k Ki)) {
+	dm.Mu.Lock()
+	dm.OnDestroy = append(dm.OnDestroy, fun)
+	dm.Mu.Unlock()
+}
+
+// Add the Ki elements to the deleted list -- they are destroyed right away
+// if the Timing is DestroyImmediate
+func (dm *Deleted) Add(kis ...Ki) {
+	dm.Mu.Lock()
+	if dm.Dels == nil {
+		dm.Dels = make([]Ki, 0)
+	}
+	dm.Dels = append(dm.Dels, kis...)
+	immediate := dm.Timing == DestroyImmediate
+	dm.Mu.Unlock()
+	if immediate {
+		dm.DestroyDeleted()
+	}
+}
+
+// NumDeleted returns the number of deleted items pending destruction
+func (dm *Deleted) NumDeleted() int {
+	dm.Mu.Lock()
+	defer dm.Mu.Unlock()
+	return len(dm.Dels)
+}
+
+// DestroyDeleted destroys any deleted items in list
+func (dm *Deleted) DestroyDeleted() {
+	// pr := prof.Start("ki.DestroyDeleted")
+	// defer pr.End()
+	dm.Mu.Lock()
+	curdels := dm.Dels
+	dm.Dels = make([]Ki, 0)
+	hooks := dm.OnDestroy
+	dm.Mu.Unlock()
+	for _, k := range curdels {
+		if k == nil || k.This() == nil {
+			continue
+		}
+		if len(hooks) > 0 {
+			callOnDestroy(k, hooks)
+		}
+		k.Destroy() // outside of lock, as destroy can trigger further deletions
+	}
+}
+
+// destroyAtUpdateEnd destroys any deleted items in list if the Timing is
+// DestroyAtUpdateEnd
+func (dm *Deleted) destroyAtUpdateEnd() {
+	if dm == nil {
+		return
+	}
+	dm.Mu.Lock()
+	atEnd := dm.Timing == DestroyAtUpdateEnd
+	dm.Mu.Unlock()
+	if atEnd {
+		dm.DestroyDeleted()
+	}
+}
+
+// callOnDestroy calls the OnDestroy functions for given deleted node and
+// its Ki fields and children, which are not visited by the Func methods
+// as the node is flagged as deleted
+func callOnDestroy(k Ki, hooks []func(k Ki)) {
+	for _, fun := range hooks {
+		fun(k)
+	}
+	n := k.AsNode()
+	for i := 0; i < NumKiFields(n); i++ {
+		callOnDestroy(KiField(n, i), hooks)
+	}
+	nk := numKids(k, false)
+	for i := 0; i < nk; i++ {
+		if kid, err := childTry(k, false, i); err == nil && kid != nil && kid.This() != nil {
+			callOnDestroy(kid, hooks)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/goki/ki/kit"
+)
+
+func deletedTestTree(name string) *Node {
+	root := &Node{}
+	root.InitName(root, name)
+	for i := 0; i < 3; i++ {
+		kid := root.AddNewChild(KiT_Node, fmt.Sprintf("child%d", i))
+		kid.AddNewChild(KiT_Node, "sub")
+	}
+	return root
+}
+
+func TestDeleteManagerPerTree(t *testing.T) {
+	a := deletedTestTree("a")
+	b := deletedTestTree("b")
+	if DeleteManager(a) == DeleteManager(b) || DeleteManager(a.Child(0)) != DeleteManager(a) {
+		t.Errorf("each tree should have its own manager, shared by all its nodes")
+	}
+
+	// nodes deleted in an update on a are only destroyed at its UpdateEnd,
+	// not by updates on b
+	updt := a.UpdateStart()
+	akid := a.Child(0)
+	a.DeleteChild(akid, DestroyKids)
+	b.DeleteChildAtIndex(0, DestroyKids)
+	if akid.This() == nil || DeleteManager(a).NumDeleted() != 1 {
+		t.Errorf("node deleted from a destroyed by update on b")
+	}
+	if DeleteManager(b).NumDeleted() != 0 {
+		t.Errorf("node deleted from b should be destroyed at its UpdateEnd")
+	}
+	a.UpdateEnd(updt)
+	if akid.This() != nil || DeleteManager(a).NumDeleted() != 0 {
+		t.Errorf("node deleted from a should be destroyed at its UpdateEnd")
+	}
+
+	// pending nodes move with the root into another tree
+	DeleteManager(a).SetTiming(DestroyManual)
+	akid = a.Child(0)
+	a.DeleteChild(akid, DestroyKids)
+	b.AddChild(a)
+	if DeleteManager(a) != DeleteManager(b) || DeleteManager(b).NumDeleted() != 1 {
+		t.Errorf("pending nodes should be passed on to the new tree: %d", DeleteManager(b).NumDeleted())
+	}
+	b.Destroy()
+	if akid.This() != nil {
+		t.Errorf("pending nodes should be destroyed with the root")
+	}
+}
+
+func TestDeleteManagerTiming(t *testing.T) {
+	root := deletedTestTree("root")
+	dm := DeleteManager(root)
+	var destroyed []string
+	dm.AddOnDestroy(func(k Ki) {
+		destroyed = append(destroyed, k.Path())
+	})
+
+	dm.SetTiming(DestroyManual)
+	kid := root.Child(0)
+	root.DeleteChild(kid, DestroyKids)
+	root.DeleteChildAtIndex(0, NoDestroyKids)
+	if kid.This() == nil || dm.NumDeleted() != 1 || len(destroyed) != 0 {
+		t.Errorf("manual timing should not destroy at UpdateEnd")
+	}
+	dm.DestroyDeleted()
+	if kid.This() != nil || fmt.Sprint(destroyed) != "[/child0 /child0/sub]" {
+		t.Errorf("manual DestroyDeleted: %v", destroyed)
+	}
+
+	destroyed = nil
+	dm.SetTiming(DestroyImmediate)
+	updt := root.UpdateStart()
+	kid = root.Child(0)
+	root.DeleteChild(kid, DestroyKids)
+	if kid.This() != nil || fmt.Sprint(destroyed) != "[/child2 /child2/sub]" {
+		t.Errorf("immediate timing should destroy right away: %v", destroyed)
+	}
+	root.UpdateEnd(updt)
+
+	// slices without a parent node use the fallback manager
+	sl := Slice{}
+	config := kit.TypeAndNameList{}
+	config.Add(KiT_Node, "a")
+	config.Add(KiT_Node, "b")
+	sl.Config(nil, config)
+	det := sl[0]
+	sl.Config(nil, config[1:])
+	if det.This() != nil || DelMgr.NumDeleted() != 0 {
+		t.Errorf("fallback manager should destroy nodes deleted by Config")
+	}
+}
@@ -40,7 +40,37 @@ functionality, including:
     gives the minimal update.
 
   - Properties (as a string-keyed map) with property inheritance, including
-    type-level properties via kit type registry.
+    type-level properties via kit type registry, with inherited values
+    optionally cached on each node (see CachePropInherit).  Property changes are
+    signaled through the update system (see PropUpdated, UpdatedProps).
+
+  - Declared property schemas for node types, with typed, validated keys,
+    defaults and inheritance (see DeclareProps, StrictProps, EffectiveProps).
+
+  - Watchers of changes to specific fields of a node, with the old and new
+    values (see WatchField, MarkFieldChanged), and one-way or two-way
+    bindings between fields of different nodes (see Bind).
+
+  - Validation of field values against rules in their tags, across a
+    tree or when setting fields (see ValidateFields, StrictFields).
+
+  - Optional stable unique node IDs, preserved through save / load, with
+    a registry for fast lookup (see UseNodeIDs, NodeByID).
+
+  - Lazy loading of children on first access, via a ChildLoader function
+    (see SetChildLoader), for mirroring large external hierarchies.
+
+  - Cheap copy-on-write Snapshots of a tree, with a read-only view of
+    the tree as it was when the snapshot was taken.
+
+  - Three-way Merge of trees edited separately from a common base, with
+    pluggable conflict resolution.
+
+  - Per-tree management of deleted nodes, destroyed at the end of the
+    update, immediately or manually, with OnDestroy hooks (see DeleteManager).
+
+  - Optional goroutine-safe tree locking mode, with a per-tree RWMutex
+    that is used by all the mutator and accessor methods (see SetTreeLocking).
 
 In general, the names of the children of a given node should all be unique.
 The following functions defined in ki package can be used:
@@ -50,6 +80,11 @@ The following functions defined in ki package can be used:
 * UniquifyNames(node) to add a suffix to name to ensure uniqueness.
 * UniquifyNamesAll(node) to to uniquify all names in entire tree.
 
+Nodes with NameIndexAbove or more children automatically maintain a map
+from names to child indexes, so ChildByName and FindPath remain fast
+for very large numbers of children.  Names must be set with SetName for
+the map to see them.
+
 The Ki interface is designed to support virtual method calling in Go
 and is only intended to be implemented once, by the ki.Node type
 (as opposed to interfaces that are used for hiding multiple different
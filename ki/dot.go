@@ -0,0 +1,268 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/goki/ki/kit"
+)
+
+// DotOpts are the options for WriteDot
+type DotOpts struct {
+
+	// include an edge for every signal connection, from the sender to the receiver, for all the Signal fields of each node (including NodeSig) -- each signal field name gets its own color
+	Signals bool `desc:"include an edge for every signal connection, from the sender to the receiver, for all the Signal fields of each node (including NodeSig) -- each signal field name gets its own color"`
+
+	// if > 0, each node at this depth below the root that has children or Ki fields is drawn as a cluster containing its entire subtree
+	ClusterDepth int `desc:"if > 0, each node at this depth below the root that has children or Ki fields is drawn as a cluster containing its entire subtree"`
+
+	// maximum depth below the root to include, 0 for no limit
+	MaxDepth int `desc:"maximum depth below the root to include, 0 for no limit"`
+}
+
+// DotSignalColors are the colors used for signal connection edges in
+// WriteDot, assigned to signal field names in sorted order.
+var DotSignalColors = []string{"blue", "red", "darkgreen", "orange", "purple", "brown", "magenta", "cyan4"}
+
+// WriteDot writes the tree under root as a Graphviz DOT directed graph:
+// nodes are labeled with their name and short type name, children are
+// connected to their parent with solid edges and Ki fields with dashed
+// edges labeled with the field name.  Signal connections are optionally
+// shown as colored edges labeled with the signal field name, which do not
+// affect the layout -- receivers outside of the tree are shown with dotted
+// outlines, labeled with their path.  The output is deterministic for a
+// given tree state.  Children are not loaded by WriteDot.
+func WriteDot(w io.Writer, root Ki, opts DotOpts) error {
+	bw := bufio.NewWriter(w)
+	d := dotWriter{w: bw, opts: &opts, ids: make(map[Ki]string), order: make(map[Ki]int)}
+	d.assignIDs(root, 0)
+	fmt.Fprintf(bw, "digraph %s {\n", dotQuote(root.Name()))
+	fmt.Fprintf(bw, "\tnode [shape=box];\n")
+	d.writeNodes(root, 0, "\t")
+	d.writeEdges()
+	if opts.Signals {
+		d.writeSignals()
+	}
+	fmt.Fprintf(bw, "}\n")
+	return bw.Flush()
+}
+
+// SaveDot writes the tree under root as a Graphviz DOT graph to given
+// file -- see WriteDot.
+func SaveDot(filename string, root Ki, opts DotOpts) error {
+	fp, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	return WriteDot(fp, root, opts)
+}
+
+// dotWriter holds the state of WriteDot
+type dotWriter struct {
+	w    *bufio.Writer
+	opts *DotOpts
+
+	// ids of the nodes in the graph
+	ids map[Ki]string
+
+	// nodes in the graph in order
+	nodes []Ki
+
+	// index of each node in nodes
+	order map[Ki]int
+
+	// edges to write after the nodes
+	edges []string
+}
+
+// dotEntries returns the Ki fields and children of given node to include
+// in the graph, and the names of the Ki fields
+func (d *dotWriter) dotEntries(k Ki, depth int) ([]Ki, []string) {
+	if d.opts.MaxDepth > 0 && depth >= d.opts.MaxDepth {
+		return nil, nil
+	}
+	n := k.AsNode()
+	var ents []Ki
+	fnms := KiFieldNames(n)
+	for i := range fnms {
+		ents = append(ents, KiField(n, i))
+	}
+	nk := numKids(k, false)
+	for i := 0; i < nk; i++ {
+		if kid, err := childTry(k, false, i); err == nil && kid != nil {
+			ents = append(ents, kid)
+		}
+	}
+	return ents, fnms
+}
+
+// assignIDs assigns ids to all the nodes in the graph in traversal order
+func (d *dotWriter) assignIDs(k Ki, depth int) {
+	if _, has := d.ids[k]; has {
+		return
+	}
+	d.ids[k] = fmt.Sprintf("n%d", len(d.nodes))
+	d.order[k] = len(d.nodes)
+	d.nodes = append(d.nodes, k)
+	ents, _ := d.dotEntries(k, depth)
+	for _, e := range ents {
+		d.assignIDs(e, depth+1)
+	}
+}
+
+// writeNodes writes the node declarations for given subtree, in a cluster
+// if at the ClusterDepth, and records the edges to its children
+func (d *dotWriter) writeNodes(k Ki, depth int, indent string) {
+	id := d.ids[k]
+	ents, fnms := d.dotEntries(k, depth)
+	cluster := d.opts.ClusterDepth > 0 && depth == d.opts.ClusterDepth && len(ents) > 0
+	if cluster {
+		fmt.Fprintf(d.w, "%ssubgraph cluster_%s {\n", indent, id)
+		indent += "\t"
+		fmt.Fprintf(d.w, "%slabel=%s;\n", indent, dotQuote(k.Name()))
+	}
+	fmt.Fprintf(d.w, "%s%s [label=%s];\n", indent, id, dotQuote(k.Name()+"\n"+kit.ShortTypeName(Type(k))))
+	for i, e := range ents {
+		eid := d.ids[e]
+		if i < len(fnms) {
+			d.edges = append(d.edges, fmt.Sprintf("%s -> %s [style=dashed, label=%s];", id, eid, dotQuote(fnms[i])))
+		} else {
+			d.edges = append(d.edges, fmt.Sprintf("%s -> %s;", id, eid))
+		}
+		d.writeNodes(e, depth+1, indent)
+	}
+	if cluster {
+		fmt.Fprintf(d.w, "%s}\n", indent[:len(indent)-1])
+	}
+}
+
+// writeEdges writes the recorded parent-child and Ki field edges
+func (d *dotWriter) writeEdges() {
+	for _, e := range d.edges {
+		fmt.Fprintf(d.w, "\t%s\n", e)
+	}
+}
+
+// writeSignals writes the signal connection edges, adding nodes for any
+// receivers outside of the graph
+func (d *dotWriter) writeSignals() {
+	type conn struct {
+		send, recv Ki
+		field      string
+	}
+	var conns []conn
+	fields := make(map[string]bool)
+	for _, k := range d.nodes {
+		for _, sf := range signalFields(k) {
+			sf.sig.Mu.RLock()
+			recvs := make([]Ki, 0, len(sf.sig.Cons))
+			for r := range sf.sig.Cons {
+				recvs = append(recvs, r)
+			}
+			sf.sig.Mu.RUnlock()
+			sort.Slice(recvs, func(i, j int) bool {
+				return d.recvLess(recvs[i], recvs[j])
+			})
+			for _, r := range recvs {
+				conns = append(conns, conn{k, r, sf.name})
+			}
+			if len(recvs) > 0 {
+				fields[sf.name] = true
+			}
+		}
+	}
+	fnms := make([]string, 0, len(fields))
+	for fnm := range fields {
+		fnms = append(fnms, fnm)
+	}
+	sort.Strings(fnms)
+	colors := make(map[string]string, len(fnms))
+	for i, fnm := range fnms {
+		colors[fnm] = DotSignalColors[i%len(DotSignalColors)]
+	}
+	next := 0
+	for _, c := range conns {
+		rid, has := d.ids[c.recv]
+		if !has {
+			rid = fmt.Sprintf("x%d", next)
+			next++
+			d.ids[c.recv] = rid
+			lbl := "nil"
+			if pth := dotPath(c.recv); pth != "" {
+				lbl = pth + "\n" + kit.ShortTypeName(Type(c.recv))
+			}
+			fmt.Fprintf(d.w, "\t%s [label=%s, style=dotted];\n", rid, dotQuote(lbl))
+		}
+		col := colors[c.field]
+		fmt.Fprintf(d.w, "\t%s -> %s [color=%s, fontcolor=%s, label=%s, constraint=false];\n", d.ids[c.send], rid, col, col, dotQuote(c.field))
+	}
+}
+
+// recvLess orders signal receivers: those in the graph in graph order,
+// then others by path
+func (d *dotWriter) recvLess(a, b Ki) bool {
+	ai, aok := d.order[a]
+	bi, bok := d.order[b]
+	switch {
+	case aok && bok:
+		return ai < bi
+	case aok || bok:
+		return aok
+	}
+	return dotPath(a) < dotPath(b)
+}
+
+// dotPath returns the path of given node, "" if nil or destroyed
+func dotPath(k Ki) string {
+	if k == nil || k.This() == nil {
+		return ""
+	}
+	return k.Path()
+}
+
+// namedSignal is a Signal field of a node
+type namedSignal struct {
+	name string
+	sig  *Signal
+}
+
+// signalFields returns all the Signal fields of given node, including
+// in embedded structs such as Node (NodeSig), but not in Ki fields
+func signalFields(k Ki) []namedSignal {
+	var sfs []namedSignal
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		typ := v.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			fv := v.Field(i)
+			switch {
+			case f.Type == KiT_Signal:
+				sfs = append(sfs, namedSignal{f.Name, (*Signal)(unsafe.Pointer(fv.UnsafeAddr()))})
+			case f.Type.Kind() == reflect.Struct && f.Anonymous:
+				walk(fv)
+			}
+		}
+	}
+	walk(reflect.ValueOf(k).Elem())
+	return sfs
+}
+
+// dotQuote returns given string as a quoted DOT string
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteDot(t *testing.T) {
+	parent := snapshotTestTree()
+	child2 := parent.Child(1)
+	child3 := parent.Child(2)
+	child3.NodeSignal().Connect(parent, func(r, s Ki, sig int64, d any) {})
+	ext := TestNode{}
+	ext.InitName(&ext, "ext")
+	child2.NodeSignal().Connect(&ext, func(r, s Ki, sig int64, d any) {})
+	ext.sig1.Connect(child2, func(r, s Ki, sig int64, d any) {})
+
+	var b bytes.Buffer
+	if err := WriteDot(&b, parent, DotOpts{MaxDepth: 1}); err != nil {
+		t.Error(err)
+	}
+	exp := `digraph "par1" {
+	node [shape=box];
+	n0 [label="par1\nki.NodeField"];
+	n1 [label="Field1\nki.NodeEmbed"];
+	n2 [label="child1\nki.NodeField"];
+	n3 [label="child2\nki.NodeEmbed"];
+	n4 [label="child3\nki.NodeEmbed"];
+	n0 -> n1 [style=dashed, label="Field1"];
+	n0 -> n2;
+	n0 -> n3;
+	n0 -> n4;
+}
+`
+	if b.String() != exp {
+		t.Errorf("WriteDot:\n%v\nnot as expected:\n%v", b.String(), exp)
+	}
+
+	b.Reset()
+	WriteDot(&b, parent, DotOpts{Signals: true, ClusterDepth: 1})
+	out := b.String()
+	for _, s := range []string{
+		"subgraph cluster_n2 {\n\t\tlabel=\"child1\";\n\t\tn2 [",
+		"\tn2 -> n3 [style=dashed, label=\"Field1\"];\n",
+		"\tx0 [label=\"/ext\\nki.TestNode\", style=dotted];\n",
+		"\tn5 -> x0 [color=blue, fontcolor=blue, label=\"NodeSig\", constraint=false];\n",
+		"\tn7 -> n0 [color=blue, fontcolor=blue, label=\"NodeSig\", constraint=false];\n",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("WriteDot output missing: %q\n%v", s, out)
+		}
+	}
+	if strings.Contains(out, "sig1") { // ext is not in the tree
+		t.Errorf("WriteDot should only show signals of nodes in the tree:\n%v", out)
+	}
+	b.Reset()
+	WriteDot(&b, parent, DotOpts{Signals: true, ClusterDepth: 1})
+	if b.String() != out {
+		t.Errorf("WriteDot should be deterministic")
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goki/ki/kit"
+)
+
+// DumpOpts are the options for Dump -- the zero value shows just the
+// names and types of all the nodes.
+type DumpOpts struct {
+
+	// show the node flags, as names decoded with kit.BitFlagsToString
+	Flags bool `desc:"show the node flags, as names decoded with kit.BitFlagsToString"`
+
+	// show the node properties, sorted by key
+	Props bool `desc:"show the node properties, sorted by key"`
+
+	// show the exported value fields of the node (not those of Node itself) that differ from their default: the def tag values or ranges if present, and otherwise the zero value
+	Fields bool `desc:"show the exported value fields of the node (not those of Node itself) that differ from their default: the def tag values or ranges if present, and otherwise the zero value"`
+
+	// show Ki fields, as sub-nodes listed before the children
+	KiFields bool `desc:"show Ki fields, as sub-nodes listed before the children"`
+
+	// maximum depth below the root to show, 0 for no limit -- nodes at the limit with hidden children show the number of children
+	MaxDepth int `desc:"maximum depth below the root to show, 0 for no limit -- nodes at the limit with hidden children show the number of children"`
+
+	// if set, only nodes for which this returns true are shown, along with their children -- the root is always shown
+	Filter func(k Ki) bool `desc:"if set, only nodes for which this returns true are shown, along with their children -- the root is always shown"`
+}
+
+// Dump returns an indented outline of the tree under root, like the output
+// of the tree command, with one line per node showing its name and short
+// type name, followed by the additional information selected in opts.
+// The output is deterministic for a given tree state, so it can be used
+// in golden tests: props are sorted by key, pointers to Ki nodes are shown
+// by path, and other pointers by their values.  Children are not loaded
+// by Dump: nodes with a ChildLoader that are not yet loaded have no
+// children listed.
+func Dump(root Ki, opts DumpOpts) string {
+	var sb strings.Builder
+	dumpNode(&sb, root, "", "", 0, &opts)
+	return sb.String()
+}
+
+// dumpEntry is a Ki field or child to be dumped
+type dumpEntry struct {
+	k     Ki
+	field bool
+}
+
+// dumpNode writes the line for given node, with given prefix for the line
+// and for its children, and then its children.
+func dumpNode(sb *strings.Builder, k Ki, prefix, kidPrefix string, depth int, opts *DumpOpts) {
+	n := k.AsNode()
+	var ents []dumpEntry
+	if opts.KiFields {
+		for i := 0; i < NumKiFields(n); i++ {
+			if fk := KiField(n, i); opts.Filter == nil || opts.Filter(fk) {
+				ents = append(ents, dumpEntry{fk, true})
+			}
+		}
+	}
+	nk := numKids(k, false)
+	for i := 0; i < nk; i++ {
+		kid, err := childTry(k, false, i)
+		if err != nil || kid == nil {
+			continue
+		}
+		if opts.Filter == nil || opts.Filter(kid) {
+			ents = append(ents, dumpEntry{kid, false})
+		}
+	}
+	sb.WriteString(prefix)
+	sb.WriteString(dumpLine(k, opts))
+	hide := opts.MaxDepth > 0 && depth >= opts.MaxDepth && len(ents) > 0
+	if hide {
+		fmt.Fprintf(sb, " (+%d)", len(ents))
+	}
+	sb.WriteString("\n")
+	if hide {
+		return
+	}
+	for i, e := range ents {
+		con, sub := "├── ", "│   "
+		if i == len(ents)-1 {
+			con, sub = "└── ", "    "
+		}
+		if e.field {
+			con += "." // marks Ki fields
+		}
+		dumpNode(sb, e.k, kidPrefix+con, kidPrefix+sub, depth+1, opts)
+	}
+}
+
+// dumpLine returns the description of given node, without children
+func dumpLine(k Ki, opts *DumpOpts) string {
+	str := k.Name() + " " + kit.ShortTypeName(Type(k))
+	if opts.Flags {
+		str += " flags=[" + kit.BitFlagsToString(k.Flags(), FlagsN) + "]"
+	}
+	if opts.Fields {
+		var fs []string
+		ti := kit.TypeInfo(Type(k))
+		for _, fn := range valueFieldNames(Type(k)) {
+			fv := kit.FlatFieldValueByName(k, fn)
+			if fi := ti.Field(fn); fi != nil && fi.IsDefault(fv.Interface()) {
+				continue
+			}
+			fs = append(fs, fn+": "+dumpValue(fv))
+		}
+		if len(fs) > 0 {
+			str += " fields={" + strings.Join(fs, ", ") + "}"
+		}
+	}
+	if opts.Props {
+		pr := *k.Properties()
+		if len(pr) > 0 {
+			keys := make([]string, 0, len(pr))
+			for key := range pr {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			ps := make([]string, len(keys))
+			for i, key := range keys {
+				ps[i] = key + ": " + dumpValue(reflect.ValueOf(pr[key]))
+			}
+			str += " props={" + strings.Join(ps, ", ") + "}"
+		}
+	}
+	return str
+}
+
+// dumpValue returns a deterministic string representation of given value
+func dumpValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if v.IsNil() {
+			return "nil"
+		}
+		return v.Type().String()
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+		if v.CanInterface() {
+			if ki, ok := v.Interface().(Ki); ok {
+				return ki.Path()
+			}
+		}
+		if v.Kind() == reflect.Ptr {
+			return "&" + dumpValue(v.Elem())
+		}
+		return dumpValue(v.Elem())
+	}
+	if v.CanInterface() {
+		return fmt.Sprintf("%v", v.Interface())
+	}
+	return v.Type().String()
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	parent := snapshotTestTree()
+	parent.Child(1).(*NodeEmbed).Mbr2 = 2
+	parent.SetProp("b", []int{1, 2})
+	parent.SetProp("a", "x")
+
+	out := Dump(parent, DumpOpts{})
+	exp := `par1 ki.NodeField
+├── child1 ki.NodeField
+│   └── subchild1 ki.NodeEmbed
+├── child2 ki.NodeEmbed
+│   └── subchild2 ki.NodeEmbed
+└── child3 ki.NodeEmbed
+`
+	if out != exp {
+		t.Errorf("Dump:\n%v\nnot as expected:\n%v", out, exp)
+	}
+
+	out = Dump(parent, DumpOpts{Props: true, Fields: true, KiFields: true, MaxDepth: 1})
+	exp = `par1 ki.NodeField props={a: "x", b: [1 2]}
+├── .Field1 ki.NodeEmbed fields={Mbr1: "field1"}
+├── child1 ki.NodeField (+2)
+├── child2 ki.NodeEmbed fields={Mbr2: 2} props={prop: 1} (+1)
+└── child3 ki.NodeEmbed
+`
+	if out != exp {
+		t.Errorf("Dump opts:\n%v\nnot as expected:\n%v", out, exp)
+	}
+	if out2 := Dump(parent, DumpOpts{Props: true, Fields: true, KiFields: true, MaxDepth: 1}); out2 != out {
+		t.Errorf("Dump should be deterministic")
+	}
+
+	out = Dump(parent, DumpOpts{Flags: true, Filter: func(k Ki) bool {
+		return !strings.HasPrefix(k.Name(), "child1")
+	}})
+	if strings.Contains(out, "child1") || !strings.Contains(out, "subchild2 ki.NodeEmbed flags=[") {
+		t.Errorf("Dump filter / flags:\n%v", out)
+	}
+
+	vn := &validTestNode{}
+	vn.InitName(vn, "vn")
+	vn.Size = 5 // within def range
+	if out = Dump(vn, DumpOpts{Fields: true}); out != "vn ki.validTestNode\n" {
+		t.Errorf("Dump default range field:\n%v", out)
+	}
+	vn.Size = 11
+	if out = Dump(vn, DumpOpts{Fields: true}); out != "vn ki.validTestNode fields={Size: 11}\n" {
+		t.Errorf("Dump non-default field:\n%v", out)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/goki/ki/kit"
+)
+
+// StrictFields turns on validation of field values in SetField, against
+// the validation rules in the tags of the field (see kit.FieldRules):
+// invalid values are rejected with an error, and the field is not set.
+var StrictFields = false
+
+// ValidateFields checks the field values of all the nodes in the tree
+// under given root (including Ki fields) against the validation rules in
+// their tags (see kit.Validate, kit.FieldRules), returning all of the
+// invalid fields found, with paths of the form node path + "." + field
+// path, e.g., /root/kid.Pos.X -- nil if all are valid.
+func ValidateFields(root Ki) []kit.ValidationError {
+	var errs []kit.ValidationError
+	root.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		verrs := kit.Validate(k.This())
+		if len(verrs) == 0 {
+			return Continue
+		}
+		kp := k.Path()
+		for _, ve := range verrs {
+			ve.Path = kp + "." + ve.Path
+			errs = append(errs, ve)
+		}
+		return Continue
+	})
+	return errs
+}
+
+// validateSetField returns an error if given value for given field
+// path is not valid, if StrictFields is on
+func (n *Node) validateSetField(field string, val any) error {
+	if !StrictFields {
+		return nil
+	}
+	fi := kit.TypeInfo(reflect.TypeOf(n.This())).FieldByPath(field)
+	if fi == nil {
+		return nil
+	}
+	if err := fi.ValidateValue(val); err != nil {
+		return fmt.Errorf("ki.SetField, invalid value for field %v on node %v: %v", field, n.Nm, err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goki/ki/kit"
+)
+
+type validTestPos struct {
+	X float32 `min:"0" max:"100"`
+}
+
+type validTestSub struct {
+	Node
+	Label string `required:"+"`
+}
+
+var KiT_validTestSub = kit.Types.AddType(&validTestSub{}, nil)
+
+type validTestNode struct {
+	Node
+	Size int `def:"1:10"`
+	Pos  validTestPos
+	Sub  validTestSub
+}
+
+var KiT_validTestNode = kit.Types.AddType(&validTestNode{}, nil)
+
+func TestValidateFields(t *testing.T) {
+	par := &Node{}
+	par.InitName(par, "par")
+	vn := par.AddNewChild(KiT_validTestNode, "vn").(*validTestNode)
+	vn.Size = 5
+	vn.Sub.Label = "ok"
+	if errs := ValidateFields(par); errs != nil {
+		t.Errorf("tree should be valid: %v", errs)
+	}
+
+	vn.Size = 20
+	vn.Pos.X = -1
+	vn.Sub.Label = ""
+	var paths []string
+	for _, e := range ValidateFields(par) {
+		paths = append(paths, e.Path)
+	}
+	want := "/par/vn.Size /par/vn.Pos.X /par/vn.Sub.Label"
+	if got := strings.Join(paths, " "); got != want {
+		t.Errorf("wrong error paths:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	if err := vn.SetField("Size", 30); err != nil || vn.Size != 30 {
+		t.Errorf("SetField should not validate unless StrictFields: %v", err)
+	}
+	StrictFields = true
+	defer func() { StrictFields = false }()
+	if err := vn.SetField("Size", "40"); err == nil || vn.Size != 30 {
+		t.Errorf("SetField should reject invalid value with StrictFields")
+	}
+	if err := vn.SetField("Pos.X", 200); err == nil || vn.Pos.X != -1 {
+		t.Errorf("SetField should reject invalid value for a field path with StrictFields")
+	}
+	if err := vn.SetField("Size", 3); err != nil || vn.Size != 3 {
+		t.Errorf("SetField should set valid value with StrictFields: %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/goki/ki/kit"
+)
+
+// FieldWatchFunc is a function called when the value of a watched field of
+// a node changes, with the watched field path and the old and new values
+// -- see WatchField.
+type FieldWatchFunc func(k Ki, path string, old, new any)
+
+// fieldWatcher is a watcher of a field of a node
+type fieldWatcher struct {
+	id   int
+	path string
+	last any
+	fun  FieldWatchFunc
+
+	// called when the watcher is removed, e.g., when the node is destroyed
+	onRemove func()
+}
+
+// fieldWatch guards the field watchers of all nodes, and counts them so
+// checking for changes can be skipped when there are none
+var fieldWatch struct {
+	mu     sync.Mutex
+	lastID int
+	n      int
+}
+
+// WatchField registers a function to be called with the old and new values
+// when the value of given field of given node changes, returning an ID for
+// UnwatchField.  The field can be given by name or by a dot-separated path
+// into struct fields, e.g., "Pos.X".  Changes are detected when made by
+// SetField, CopyFrom and ReadJSON on the node or its parents, and direct
+// assignments must be reported with MarkFieldChanged.  The old value of
+// slice, map and pointer fields shares the data of the new value, so
+// in-place changes of such fields are only reported by MarkFieldChanged.
+// Watchers are removed when the node is destroyed.
+func WatchField(k Ki, path string, fun FieldWatchFunc) (int, error) {
+	return watchField(k, path, fun, nil)
+}
+
+// watchField adds a field watcher, with a function called when it is
+// removed
+func watchField(k Ki, path string, fun FieldWatchFunc, onRemove func()) (int, error) {
+	cur, err := fieldWatchValue(k, path)
+	if err != nil {
+		return 0, fmt.Errorf("ki.WatchField: %v", err)
+	}
+	n := k.AsNode()
+	fieldWatch.mu.Lock()
+	defer fieldWatch.mu.Unlock()
+	fieldWatch.lastID++
+	fieldWatch.n++
+	n.fieldWatchers = append(n.fieldWatchers, &fieldWatcher{id: fieldWatch.lastID, path: path, last: cur, fun: fun, onRemove: onRemove})
+	return fieldWatch.lastID, nil
+}
+
+// UnwatchField removes the field watcher with given ID (as returned by
+// WatchField) from given node -- returns false if not found.
+func UnwatchField(k Ki, id int) bool {
+	n := k.AsNode()
+	fieldWatch.mu.Lock()
+	var rm *fieldWatcher
+	for i, fw := range n.fieldWatchers {
+		if fw.id == id {
+			rm = fw
+			n.fieldWatchers = append(n.fieldWatchers[:i:i], n.fieldWatchers[i+1:]...)
+			fieldWatch.n--
+			break
+		}
+	}
+	fieldWatch.mu.Unlock()
+	if rm == nil {
+		return false
+	}
+	if rm.onRemove != nil {
+		rm.onRemove()
+	}
+	return true
+}
+
+// UnwatchFields removes all the field watchers of given node.
+func UnwatchFields(k Ki) {
+	n := k.AsNode()
+	fieldWatch.mu.Lock()
+	fws := n.fieldWatchers
+	fieldWatch.n -= len(fws)
+	n.fieldWatchers = nil
+	fieldWatch.mu.Unlock()
+	for _, fw := range fws {
+		if fw.onRemove != nil {
+			fw.onRemove()
+		}
+	}
+}
+
+// NumFieldWatchers returns the number of field watchers of given node.
+func NumFieldWatchers(k Ki) int {
+	fieldWatch.mu.Lock()
+	defer fieldWatch.mu.Unlock()
+	return len(k.AsNode().fieldWatchers)
+}
+
+// MarkFieldChanged reports a change of given field (name or path) of given
+// node made by direct assignment, calling the watchers of the field, and
+// those of paths within it or containing it whose value changed.  The
+// watchers of the field itself are called even if its value appears
+// unchanged, as for in-place changes of slices or maps.
+func MarkFieldChanged(k Ki, path string) {
+	k.AsNode().fieldWatchCheck(path)
+}
+
+// fieldPathValue returns the value of given field path of given node, as
+// a field name, including those of embedded structs, or a dot-separated
+// path into struct fields
+func fieldPathValue(k Ki, path string) (reflect.Value, error) {
+	if k == nil || k.This() == nil {
+		return reflect.Value{}, fmt.Errorf("nil node")
+	}
+	v := reflect.ValueOf(k.This())
+	for _, pe := range strings.Split(path, ".") {
+		v = kit.NonPtrValue(v)
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("path %v on node %v: %v is not in a struct", path, k.Name(), pe)
+		}
+		v = v.FieldByName(pe)
+		if !v.IsValid() {
+			return reflect.Value{}, fmt.Errorf("path %v on node %v: field %v not found", path, k.Name(), pe)
+		}
+		if !v.CanInterface() {
+			return reflect.Value{}, fmt.Errorf("path %v on node %v: field %v is not exported", path, k.Name(), pe)
+		}
+	}
+	return v, nil
+}
+
+// fieldWatchValue returns the current value of given field path of given
+// node
+func fieldWatchValue(k Ki, path string) (any, error) {
+	v, err := fieldPathValue(k, path)
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+// fieldPathsOverlap returns true if one of the given field paths is the
+// same as or within the other
+func fieldPathsOverlap(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
+}
+
+// fieldWatchChange is a change to report to a field watcher
+type fieldWatchChange struct {
+	path     string
+	old, new any
+	fun      FieldWatchFunc
+}
+
+// fieldWatchCheck calls the field watchers of the node whose values
+// changed -- if marked is not empty, only those of paths overlapping it,
+// and always those of the marked path itself
+func (n *Node) fieldWatchCheck(marked string) {
+	fieldWatch.mu.Lock()
+	if len(n.fieldWatchers) == 0 {
+		fieldWatch.mu.Unlock()
+		return
+	}
+	var chgs []fieldWatchChange
+	for _, fw := range n.fieldWatchers {
+		if marked != "" && !fieldPathsOverlap(fw.path, marked) {
+			continue
+		}
+		cur, err := fieldWatchValue(n.This(), fw.path)
+		if err != nil || (fw.path != marked && reflect.DeepEqual(cur, fw.last)) {
+			continue
+		}
+		chgs = append(chgs, fieldWatchChange{path: fw.path, old: fw.last, new: cur, fun: fw.fun})
+		fw.last = cur
+	}
+	fieldWatch.mu.Unlock()
+	for _, ch := range chgs {
+		ch.fun(n.This(), ch.path, ch.old, ch.new)
+	}
+}
+
+// fieldWatchCheckTree calls the field watchers of all the nodes from given
+// one down whose values changed
+func fieldWatchCheckTree(k Ki) {
+	fieldWatch.mu.Lock()
+	none := fieldWatch.n == 0
+	fieldWatch.mu.Unlock()
+	if none {
+		return
+	}
+	k.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		k.AsNode().fieldWatchCheck("")
+		return Continue
+	})
+}
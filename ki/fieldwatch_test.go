@@ -0,0 +1,152 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/goki/ki/kit"
+)
+
+type watchTestPos struct {
+	X, Y float32
+}
+
+type watchTestNode struct {
+	Node
+	Size  int
+	Pos   watchTestPos
+	Items []string
+}
+
+var KiT_watchTestNode = kit.Types.AddType(&watchTestNode{}, nil)
+
+type WatchTestBorder struct {
+	Width float32
+}
+
+type WatchTestStyle struct {
+	WatchTestBorder
+	Color string
+	Pos   watchTestPos
+}
+
+type watchEmbedTestNode struct {
+	watchTestNode
+	WatchTestStyle
+}
+
+var KiT_watchEmbedTestNode = kit.Types.AddType(&watchEmbedTestNode{}, nil)
+
+func TestFieldWatch(t *testing.T) {
+	par := &Node{}
+	par.InitName(par, "par")
+	wn := par.AddNewChild(KiT_watchTestNode, "wn").(*watchTestNode)
+
+	var res []string
+	watch := func(path string) int {
+		id, err := WatchField(wn, path, func(k Ki, path string, old, new any) {
+			res = append(res, fmt.Sprintf("%v %v: %v -> %v", k.Name(), path, old, new))
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		return id
+	}
+	sid := watch("Size")
+	watch("Pos.X")
+	watch("Items")
+	watch("Nm")
+	if _, err := WatchField(wn, "Pos.Z", nil); err == nil {
+		t.Errorf("watching missing field should fail")
+	}
+	check := func(step, exp string) {
+		t.Helper()
+		if fmt.Sprint(res) != exp {
+			t.Errorf("%v: %v\nexpected: %v", step, res, exp)
+		}
+		res = nil
+	}
+
+	wn.SetField("Size", "3")
+	wn.SetField("Size", 3)
+	check("SetField", "[wn Size: 0 -> 3]")
+
+	wn.Pos.Y = 2
+	MarkFieldChanged(wn, "Pos")
+	check("unchanged sub-path", "[]")
+	wn.Pos = watchTestPos{X: 1, Y: 3}
+	MarkFieldChanged(wn, "Pos")
+	check("sub-path", "[wn Pos.X: 0 -> 1]")
+	wn.Items = append(wn.Items, "a")
+	MarkFieldChanged(wn, "Items")
+	MarkFieldChanged(wn, "Items") // marked path itself always reported
+	check("MarkFieldChanged", "[wn Items: [] -> [a] wn Items: [a] -> [a]]")
+
+	src := &watchTestNode{}
+	src.InitName(src, "wn")
+	src.Size = 5
+	src.Pos.X = 1
+	src.Items = []string{"a"}
+	wn.CopyFrom(src)
+	check("CopyFrom", "[wn Size: 3 -> 5]")
+
+	src.Size = 7
+	src.Pos.X = 2
+	var b bytes.Buffer
+	src.WriteJSON(&b, Indent)
+	wn.ReadJSON(&b)
+	check("ReadJSON", "[wn Size: 5 -> 7 wn Pos.X: 1 -> 2]")
+	par.CopyFrom(par.Clone()) // copies wn from clone of itself
+	check("CopyFrom parent", "[]")
+
+	if !UnwatchField(wn, sid) || UnwatchField(wn, sid) || NumFieldWatchers(wn) != 3 {
+		t.Errorf("UnwatchField")
+	}
+	wn.SetField("Size", 1)
+	wn.SetField("Nm", "renamed")
+	check("after Unwatch", "[renamed Nm: wn -> renamed]")
+
+	par.Destroy()
+	if NumFieldWatchers(wn) != 0 || fieldWatch.n != 0 {
+		t.Errorf("watchers should be removed on Destroy: %v", fieldWatch.n)
+	}
+}
+
+func TestSetFieldEmbedded(t *testing.T) {
+	par := &Node{}
+	par.InitName(par, "par")
+	en := par.AddNewChild(KiT_watchEmbedTestNode, "en").(*watchEmbedTestNode)
+	var res []string
+	if _, err := WatchField(en, "Color", func(k Ki, path string, old, new any) {
+		res = append(res, fmt.Sprintf("%v: %v -> %v", path, old, new))
+	}); err != nil {
+		t.Error(err)
+	}
+	if err := en.SetField("Size", 2); err != nil || en.Size != 2 {
+		t.Errorf("SetField on embedded Ki type field: %v %v", err, en.Size)
+	}
+	if err := en.SetField("Color", "red"); err != nil || en.Color != "red" {
+		t.Errorf("SetField on embedded struct field: %v %v", err, en.Color)
+	}
+	if err := en.SetField("Width", "1.5"); err != nil || en.Width != 1.5 {
+		t.Errorf("SetField on field promoted from a nested embedded struct: %v %v", err, en.Width)
+	}
+	if err := en.SetField("WatchTestStyle.Width", 2); err != nil || en.Width != 2 {
+		t.Errorf("SetField on path to promoted field: %v %v", err, en.Width)
+	}
+	if err := en.SetField("WatchTestStyle.Pos.Y", 3); err != nil || en.WatchTestStyle.Pos.Y != 3 {
+		t.Errorf("SetField on embedded struct path: %v %v", err, en.WatchTestStyle.Pos.Y)
+	}
+	if err := en.SetField("Pos.X", 1); err == nil {
+		t.Errorf("SetField on ambiguous embedded field should fail")
+	}
+	if fmt.Sprint(res) != "[Color:  -> red]" {
+		t.Errorf("watch embedded field: %v", res)
+	}
+	par.Destroy()
+}
@@ -60,18 +60,36 @@ const (
 	// ValUpdated means a value was updated (Field, Prop, any kind of value)
 	ValUpdated
 
+	// SnapshotView indicates that the node is part of the read-only view of
+	// a Snapshot -- any attempt to modify it via Node methods will panic.
+	SnapshotView
+
+	// ChildrenUnloaded means that the node has a ChildLoader and its children
+	// have not been loaded yet (or were unloaded) -- this distinguishes a node
+	// whose children are not loaded from one that has no children.
+	ChildrenUnloaded
+
+	// ChildMoved means one or more children were moved to a different
+	// position in the children list, e.g., by SortChildren or ConfigChildren.
+	ChildMoved
+
+	// PropUpdated means one or more properties were set or deleted, by
+	// SetProp, SetProps or DeleteProp -- the keys are returned by
+	// UpdatedProps.
+	PropUpdated
+
 	// FlagsN is total number of flags used by base Ki Node -- can extend from
 	// here up to 64 bits.
 	FlagsN
 
 	// ChildUpdateFlagsMask is a mask for all child updates.
-	ChildUpdateFlagsMask = (1 << uint32(ChildAdded)) | (1 << uint32(ChildDeleted)) | (1 << uint32(ChildrenDeleted))
+	ChildUpdateFlagsMask = (1 << uint32(ChildAdded)) | (1 << uint32(ChildDeleted)) | (1 << uint32(ChildrenDeleted)) | (1 << uint32(ChildMoved))
 
 	// StruUpdateFlagsMask is a mask for all structural changes update flags.
 	StruUpdateFlagsMask = ChildUpdateFlagsMask | (1 << uint32(NodeDeleted))
 
 	// ValUpdateFlagsMask is a mask for all non-structural, value-only changes update flags.
-	ValUpdateFlagsMask = (1 << uint32(ValUpdated))
+	ValUpdateFlagsMask = (1 << uint32(ValUpdated)) | (1 << uint32(PropUpdated))
 
 	// UpdateFlagsMask is a Mask for all the update flags -- destroyed is
 	// excluded b/c otherwise it would get cleared.
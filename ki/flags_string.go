@@ -22,12 +22,16 @@ func _() {
 	_ = x[ChildDeleted-8]
 	_ = x[ChildrenDeleted-9]
 	_ = x[ValUpdated-10]
-	_ = x[FlagsN-11]
+	_ = x[SnapshotView-11]
+	_ = x[ChildrenUnloaded-12]
+	_ = x[ChildMoved-13]
+	_ = x[PropUpdated-14]
+	_ = x[FlagsN-15]
 }
 
-const _Flags_name = "IsFieldHasKiFieldsHasNoKiFieldsUpdatingOnlySelfUpdateNodeDeletedNodeDestroyedChildAddedChildDeletedChildrenDeletedValUpdatedFlagsN"
+const _Flags_name = "IsFieldHasKiFieldsHasNoKiFieldsUpdatingOnlySelfUpdateNodeDeletedNodeDestroyedChildAddedChildDeletedChildrenDeletedValUpdatedSnapshotViewChildrenUnloadedChildMovedPropUpdatedFlagsN"
 
-var _Flags_index = [...]uint8{0, 7, 18, 31, 39, 53, 64, 77, 87, 99, 114, 124, 130}
+var _Flags_index = [...]uint8{0, 7, 18, 31, 39, 53, 64, 77, 87, 99, 114, 124, 136, 152, 162, 173, 179}
 
 func (i Flags) String() string {
 	if i < 0 || i >= Flags(len(_Flags_index)-1) {
@@ -58,7 +62,11 @@ var _Flags_descMap = map[Flags]string{
 	8:  `ChildDeleted means one or more children were deleted from the node.`,
 	9:  `ChildrenDeleted means all children were deleted.`,
 	10: `ValUpdated means a value was updated (Field, Prop, any kind of value)`,
-	11: `FlagsN is total number of flags used by base Ki Node -- can extend from here up to 64 bits.`,
+	11: `SnapshotView indicates that the node is part of the read-only view of a Snapshot -- any attempt to modify it via Node methods will panic.`,
+	12: `ChildrenUnloaded means that the node has a ChildLoader and its children have not been loaded yet (or were unloaded) -- this distinguishes a node whose children are not loaded from one that has no children.`,
+	13: `ChildMoved means one or more children were moved to a different position in the children list, e.g., by SortChildren or ConfigChildren.`,
+	14: `PropUpdated means one or more properties were set or deleted, by SetProp, SetProps or DeleteProp -- the keys are returned by UpdatedProps.`,
+	15: `FlagsN is total number of flags used by base Ki Node -- can extend from here up to 64 bits.`,
 }
 
 func (i Flags) Desc() string {
@@ -0,0 +1,215 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Node IDs are an optional stable unique identifier for each node, in the
+// Node.UID field, that does not change when nodes are renamed or moved,
+// unlike paths, so they can be used as durable references to nodes e.g., in
+// a database or network protocol.  When UseNodeIDs is on, InitNode assigns a
+// new ID to any node that does not have one, and registers it in a global
+// registry for O(1) lookup via NodeByID.  IDs are saved and loaded in JSON and
+// XML, and loaded IDs are registered after loading (in UnmarshalPost), as long
+// as they are not already in use by another node, in which case a new ID is
+// assigned and an ErrNodeIDInUse error is returned (by UnmarshalPostTry and
+// the Read / Open methods).  Nodes are removed from
+// the registry when they are Destroyed.  When built with Go 1.24 or later,
+// the registry only holds weak references to the nodes, so nodes that are
+// simply dropped without Destroy are removed from it when they are garbage
+// collected -- with earlier versions, use ReleaseNodeIDs for such nodes, as
+// they are otherwise kept alive by the registry.  Clone assigns new IDs to
+// the cloned nodes -- use CloneKeepIDs to preserve them.
+
+// UseNodeIDs determines whether InitNode assigns a unique UID to each node
+// -- see the Node IDs docs above.  Call AssignNodeIDs to assign IDs to
+// existing nodes created before this was turned on.
+var UseNodeIDs = false
+
+// ErrNodeIDInUse is returned, wrapped with the nodes involved, when loaded
+// or assigned node IDs are already in use by other nodes, in which case the
+// nodes are assigned new IDs.
+var ErrNodeIDInUse = errors.New("node UID already in use")
+
+// nodeIDRegistry maps node IDs to nodes
+type nodeIDRegistry struct {
+	nodes map[uint64]nodeRef
+
+	// last ID assigned -- new IDs are always greater than any registered ID
+	last uint64
+
+	mu sync.RWMutex
+}
+
+var nodeIDs = nodeIDRegistry{nodes: make(map[uint64]nodeRef)}
+
+// NodeByID returns the node with given UID, or nil if not found.
+func NodeByID(id uint64) Ki {
+	if id == 0 {
+		return nil
+	}
+	nodeIDs.mu.RLock()
+	n := nodeIDs.nodes[id].node()
+	nodeIDs.mu.RUnlock()
+	if n == nil {
+		return nil
+	}
+	return n.This()
+}
+
+// NumNodeIDs returns the number of live nodes currently in the ID registry.
+func NumNodeIDs() int {
+	nodeIDs.mu.RLock()
+	defer nodeIDs.mu.RUnlock()
+	nn := 0
+	for _, ref := range nodeIDs.nodes {
+		if ref.node() != nil {
+			nn++
+		}
+	}
+	return nn
+}
+
+// AssignNodeIDs assigns IDs to all nodes in the tree under k (including Ki
+// fields) that do not yet have one, and registers any existing IDs that are
+// not yet registered (e.g., after loading).  This is done regardless of the
+// UseNodeIDs setting.  Returns an ErrNodeIDInUse error if any of the
+// existing IDs were in use by other nodes, which then got new IDs.
+func AssignNodeIDs(k Ki) error {
+	return syncNodeIDs(k, true)
+}
+
+// ReleaseNodeIDs removes all the nodes in the tree under k from the ID
+// registry, for nodes that are no longer used but were not Destroyed.
+// The UID values are retained, so they will be registered again if the
+// nodes are subsequently loaded or passed to AssignNodeIDs.
+func ReleaseNodeIDs(k Ki) {
+	k.AsNode().funcDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		releaseNodeID(k.AsNode())
+		return Continue
+	}, false)
+}
+
+// CloneKeepIDs returns a Clone of given node, where the cloned nodes have
+// the same UIDs as the corresponding original nodes, and the registry is
+// updated to point to the clones: this is for cases where the clone takes
+// the place of the original, which will typically be Destroyed.  The
+// original nodes are not modified, and Destroying them does not remove the
+// clones from the registry.
+func CloneKeepIDs(k Ki) Ki {
+	nki := k.Clone()
+	copyNodeIDs(nki, k)
+	return nki
+}
+
+// syncNodeIDs registers the UIDs of all nodes under k that are not
+// registered under their current ID, and assigns new IDs to nodes
+// without one if assign is true.  Returns an ErrNodeIDInUse error
+// listing any nodes whose IDs were in use by other nodes.
+func syncNodeIDs(k Ki, assign bool) error {
+	var inUse []string
+	k.AsNode().funcDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		n := k.AsNode()
+		if n.UID != 0 && !nodeIDRegistered(n) {
+			if ok := registerNodeID(n, n.UID); ok != nil {
+				inUse = append(inUse, fmt.Sprintf("%v (in use by %v, new UID %d)", n.Nm, ok.AsNode().Nm, n.UID))
+			}
+		} else if n.UID == 0 && assign {
+			registerNodeID(n, 0)
+		}
+		return Continue
+	}, false)
+	if len(inUse) > 0 {
+		return fmt.Errorf("ki: %w: %v", ErrNodeIDInUse, strings.Join(inUse, ", "))
+	}
+	return nil
+}
+
+// nodeIDRegistered returns true if the node is registered under its UID
+func nodeIDRegistered(n *Node) bool {
+	nodeIDs.mu.RLock()
+	defer nodeIDs.mu.RUnlock()
+	return nodeIDs.nodes[n.UID].node() == n
+}
+
+// copyNodeIDs copies the UIDs from frm tree into the corresponding
+// nodes of the cloned tree k, registering the new nodes.
+func copyNodeIDs(k, frm Ki) {
+	n := k.AsNode()
+	fn := frm.AsNode()
+	if fn.UID != 0 {
+		releaseNodeID(n)
+		n.UID = fn.UID
+		nodeIDs.mu.Lock()
+		nodeIDs.nodes[n.UID] = newNodeRef(n, n.UID)
+		nodeIDs.mu.Unlock()
+		n.regID = n.UID
+	}
+	nf := NumKiFields(fn)
+	for i := 0; i < nf; i++ {
+		copyNodeIDs(KiField(n, i), KiField(fn, i))
+	}
+	for i, kid := range *frm.Children() {
+		if i < k.NumChildren() {
+			copyNodeIDs(k.Child(i), kid)
+		}
+	}
+}
+
+// registerNodeID registers node under given ID, or a new ID if 0 or if
+// the ID is already in use by another node, which is returned in that case.
+func registerNodeID(n *Node, id uint64) Ki {
+	nodeIDs.mu.Lock()
+	defer nodeIDs.mu.Unlock()
+	if n.regID != 0 && nodeIDs.nodes[n.regID].node() == n {
+		delete(nodeIDs.nodes, n.regID)
+	}
+	var inUse Ki
+	if id != 0 {
+		if on := nodeIDs.nodes[id].node(); on != nil && on != n && on.This() != nil {
+			inUse = on.This()
+			id = 0
+		}
+	}
+	if id == 0 {
+		nodeIDs.last++
+		id = nodeIDs.last
+	} else if id > nodeIDs.last {
+		nodeIDs.last = id
+	}
+	n.UID = id
+	n.regID = id
+	nodeIDs.nodes[id] = newNodeRef(n, id)
+	return inUse
+}
+
+// releaseNodeID removes the node from the ID registry, if it is
+// registered -- called in Destroy.
+func releaseNodeID(n *Node) {
+	if n.regID == 0 {
+		return
+	}
+	nodeIDs.mu.Lock()
+	if nodeIDs.nodes[n.regID].node() == n {
+		delete(nodeIDs.nodes, n.regID)
+	}
+	nodeIDs.mu.Unlock()
+	n.regID = 0
+}
+
+// releaseDeadNodeID removes the registry entry for given ID if its node
+// has been garbage collected.
+func releaseDeadNodeID(id uint64) {
+	nodeIDs.mu.Lock()
+	if ref, has := nodeIDs.nodes[id]; has && ref.node() == nil {
+		delete(nodeIDs.nodes, id)
+	}
+	nodeIDs.mu.Unlock()
+}
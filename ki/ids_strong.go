@@ -0,0 +1,24 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !go1.24
+
+package ki
+
+// nodeRef is a reference to a node in the ID registry -- weak references
+// require Go 1.24, so before that the registry keeps its nodes alive until
+// they are Destroyed or released with ReleaseNodeIDs.
+type nodeRef struct {
+	n *Node
+}
+
+// newNodeRef returns a reference to given node registered under given ID
+func newNodeRef(n *Node, id uint64) nodeRef {
+	return nodeRef{n: n}
+}
+
+// node returns the node
+func (r nodeRef) node() *Node {
+	return r.n
+}
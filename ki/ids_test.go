@@ -0,0 +1,119 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestNodeIDs(t *testing.T) {
+	UseNodeIDs = true
+	defer func() { UseNodeIDs = false }()
+
+	parent := NodeField{}
+	parent.InitName(&parent, "par1")
+	child1 := parent.AddNewChild(KiT_NodeField, "child1")
+	child2 := parent.AddNewChild(KiT_NodeEmbed, "child2")
+	schild := child1.AddNewChild(KiT_NodeEmbed, "subchild1")
+
+	ids := map[uint64]Ki{}
+	parent.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		id := k.AsNode().UID
+		if id == 0 {
+			t.Errorf("node %v has no UID", k.Path())
+		}
+		if _, has := ids[id]; has {
+			t.Errorf("node %v has duplicate UID: %d", k.Path(), id)
+		}
+		ids[id] = k
+		if NodeByID(id) != k {
+			t.Errorf("NodeByID %d != %v", id, k.Path())
+		}
+		return Continue
+	})
+	if parent.Field1.UID == 0 {
+		t.Errorf("Ki field should have a UID")
+	}
+
+	// stable through rename and move
+	sid := schild.AsNode().UID
+	schild.SetName("renamed")
+	MoveToParent(schild, child2)
+	if schild.AsNode().UID != sid || NodeByID(sid) != schild {
+		t.Errorf("UID changed on rename / move")
+	}
+
+	// save / load
+	var buf bytes.Buffer
+	if err := parent.WriteJSON(&buf, true); err != nil {
+		t.Error(err)
+	}
+	b := buf.Bytes()
+	c1id := child1.AsNode().UID
+	parent.DeleteChild(child1, DestroyKids)
+	if NodeByID(c1id) != nil {
+		t.Errorf("destroyed node still in registry")
+	}
+	nwk, err := ReadNewJSON(bytes.NewReader(b))
+	if !errors.Is(err, ErrNodeIDInUse) {
+		t.Errorf("loading UIDs in use should return ErrNodeIDInUse: %v", err)
+	}
+	nc1 := nwk.ChildByName("child1", 0)
+	if nc1 == nil || nc1.AsNode().UID != c1id || NodeByID(c1id) != nc1 {
+		t.Errorf("loaded child1 did not preserve UID %d: %v", c1id, nc1)
+	}
+	// child2 is still in use in the original tree, so it gets a new ID
+	nc2 := nwk.ChildByName("child2", 0)
+	if nc2.AsNode().UID == child2.AsNode().UID || NodeByID(child2.AsNode().UID) != child2 {
+		t.Errorf("loaded duplicate UID should be reassigned")
+	}
+	if NodeByID(nc2.AsNode().UID) != nc2 {
+		t.Errorf("reassigned UID not registered")
+	}
+
+	// clone
+	cl := parent.Clone()
+	if cl.AsNode().UID == parent.UID || cl.Child(0).AsNode().UID == child2.AsNode().UID {
+		t.Errorf("Clone should assign fresh UIDs")
+	}
+	c2reg := child2.AsNode().regID
+	ck := CloneKeepIDs(child2)
+	if child2.AsNode().regID != c2reg {
+		t.Errorf("CloneKeepIDs should not modify the original")
+	}
+	if ck.AsNode().UID != child2.AsNode().UID || NodeByID(ck.AsNode().UID) != ck {
+		t.Errorf("CloneKeepIDs should preserve UIDs")
+	}
+	if ck.Child(0).AsNode().UID != sid || NodeByID(sid) != ck.Child(0) {
+		t.Errorf("CloneKeepIDs should preserve UIDs of children")
+	}
+	child2.Destroy()
+	if NodeByID(ck.AsNode().UID) != ck {
+		t.Errorf("destroying original should not unregister clone")
+	}
+
+	n := NumNodeIDs()
+	ReleaseNodeIDs(nwk)
+	ReleaseNodeIDs(cl)
+	ReleaseNodeIDs(ck)
+	ReleaseNodeIDs(&parent)
+	if NumNodeIDs() >= n || NodeByID(nc1.AsNode().UID) != nil {
+		t.Errorf("ReleaseNodeIDs did not release nodes")
+	}
+	if err := AssignNodeIDs(nwk); err != nil {
+		t.Error(err)
+	}
+	if NodeByID(nc1.AsNode().UID) != nc1 {
+		t.Errorf("AssignNodeIDs did not re-register node")
+	}
+	nck := CloneKeepIDs(nwk)
+	if err := AssignNodeIDs(nwk); !errors.Is(err, ErrNodeIDInUse) || NodeByID(nc1.AsNode().UID) != nc1 {
+		t.Errorf("AssignNodeIDs of IDs in use should return ErrNodeIDInUse: %v", err)
+	}
+	ReleaseNodeIDs(nck)
+	ReleaseNodeIDs(nwk)
+}
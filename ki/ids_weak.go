@@ -0,0 +1,29 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.24
+
+package ki
+
+import (
+	"runtime"
+	"weak"
+)
+
+// nodeRef is a weak reference to a node in the ID registry, which does not
+// keep it alive: its entry is removed when it is garbage collected.
+type nodeRef struct {
+	p weak.Pointer[Node]
+}
+
+// newNodeRef returns a reference to given node registered under given ID
+func newNodeRef(n *Node, id uint64) nodeRef {
+	runtime.AddCleanup(n, releaseDeadNodeID, id)
+	return nodeRef{p: weak.Make(n)}
+}
+
+// node returns the node, or nil if it has been garbage collected
+func (r nodeRef) node() *Node {
+	return r.p.Value()
+}
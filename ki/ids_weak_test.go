@@ -0,0 +1,47 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.24
+
+package ki
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestNodeIDsDropped(t *testing.T) {
+	UseNodeIDs = true
+	defer func() { UseNodeIDs = false }()
+
+	n := NumNodeIDs()
+	par := &NodeEmbed{}
+	par.InitName(par, "par")
+	par.SetNChildren(10, KiT_NodeEmbed, "child")
+	id := par.Child(5).AsNode().UID
+	if NodeByID(id) != par.Child(5) || NumNodeIDs() != n+11 {
+		t.Fatalf("nodes not registered: %v", NumNodeIDs())
+	}
+	par = nil // dropped without Destroy
+	for i := 0; i < 100; i++ {
+		runtime.GC()
+		nodeIDs.mu.RLock()
+		_, has := nodeIDs.nodes[id]
+		nodeIDs.mu.RUnlock()
+		if !has {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if NodeByID(id) != nil || NumNodeIDs() != n {
+		t.Errorf("dropped nodes should be removed from the registry: %v", NumNodeIDs())
+	}
+	nodeIDs.mu.RLock()
+	_, has := nodeIDs.nodes[id]
+	nodeIDs.mu.RUnlock()
+	if has {
+		t.Errorf("registry entry for dropped node not cleaned up")
+	}
+}
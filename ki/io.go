@@ -114,9 +114,13 @@ func (n *Node) ReadJSON(reader io.Reader) error {
 	}
 	// todo: use json.NewDecoder, Decode instead -- need to deal with TypePrefix etc above
 	err = json.Unmarshal(b[stidx:], n.This()) // key use of this!
-	UnmarshalPost(n.This())
+	perr := UnmarshalPostTry(n.This())
 	n.SetChildAdded() // this might not be set..
 	n.UpdateEnd(updt)
+	fieldWatchCheckTree(n.This())
+	if err == nil {
+		err = perr
+	}
 	return err
 }
 
@@ -133,7 +137,9 @@ func (n *Node) OpenJSON(filename string) error {
 }
 
 // ReadNewJSON reads a new Ki tree from a JSON-encoded byte string, using type
-// information at start of file to create an object of the proper type
+// information at start of file to create an object of the proper type.
+// The tree is also returned with an ErrNodeIDInUse error, if any of its
+// node IDs were already in use.
 func ReadNewJSON(reader io.Reader) (Ki, error) {
 	b, err := ioutil.ReadAll(reader)
 	if err != nil {
@@ -154,10 +160,10 @@ func ReadNewJSON(reader io.Reader) (Ki, error) {
 
 		updt := root.UpdateStart()
 		err = json.Unmarshal(b[bodyidx:], root)
-		UnmarshalPost(root)
+		perr := UnmarshalPostTry(root)
 		root.SetChildAdded() // this might not be set..
 		root.UpdateEnd(updt)
-		return root, nil
+		return root, perr
 	}
 	return nil, fmt.Errorf("ki.OpenNewJSON -- type prefix not found at start of file -- must be there to identify type of root node of tree")
 }
@@ -215,10 +221,16 @@ func (n *Node) ReadXML(reader io.Reader) error {
 	}
 	updt := n.UpdateStart()
 	err = xml.Unmarshal(b, n.This()) // key use of this!
-	UnmarshalPost(n.This())
+	perr := UnmarshalPostTry(n.This())
 	n.SetChildAdded() // this might not be set..
 	n.UpdateEnd(updt)
-	return nil
+	fieldWatchCheckTree(n.This())
+	if err == nil {
+		err = perr
+	} else {
+		log.Println(err)
+	}
+	return err
 }
 
 // ParentAllChildren walks the tree down from current node and call
@@ -233,9 +245,20 @@ func ParentAllChildren(kn Ki) {
 }
 
 // UnmarshalPost must be called after an Unmarshal -- calls
-// ParentAllChildren.
+// ParentAllChildren, and registers any loaded node IDs (see
+// UnmarshalPostTry).
 func UnmarshalPost(kn Ki) {
+	UnmarshalPostTry(kn)
+}
+
+// UnmarshalPostTry is UnmarshalPost that returns an ErrNodeIDInUse error
+// if any of the loaded node IDs were already in use by other nodes
+// (which are otherwise loaded normally, with new IDs).
+func UnmarshalPostTry(kn Ki) error {
 	ParentAllChildren(kn)
+	err := syncNodeIDs(kn, UseNodeIDs)
+	PropsChanged()
+	return err
 }
 
 //////////////////////////////////////////////////////////////////////////
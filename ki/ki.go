@@ -290,6 +290,31 @@ type Ki interface {
 	// you call UpdateEnd(updt).
 	ConfigChildren(config kit.TypeAndNameList) (mods, updt bool)
 
+	//////////////////////////////////////////////////////////////////////////
+	//  Moving Children
+
+	// SortChildren sorts the children using given less function, with a
+	// stable sort so that equal children retain their relative order.
+	// If the order changes, sends NodeSignalChildrenMoved with the
+	// permutation, and sets the ChildMoved flag within UpdateStart / End.
+	SortChildren(less func(a, b Ki) bool)
+
+	// SortChildrenBy sorts the children by the value of given field (which
+	// can be in an embedded struct) or, for children without that field, the
+	// property with given key name.  Values that can be converted to numbers
+	// are compared numerically, and otherwise as strings, with missing values
+	// ordered before all others (when ascending).  See SortChildren for update signaling.
+	SortChildrenBy(key string, ascending bool)
+
+	// MoveChild moves the child at index from to index to, shifting the
+	// children in between, returning error for invalid indexes.
+	// See SortChildren for update signaling.
+	MoveChild(from, to int) error
+
+	// ReverseChildren reverses the order of the children.
+	// See SortChildren for update signaling.
+	ReverseChildren()
+
 	//////////////////////////////////////////////////////////////////////////
 	//  Deleting Children
 
@@ -406,9 +431,35 @@ type Ki interface {
 	Properties() *Props
 
 	// SetProp sets given property key to value val -- initializes property
-	// map if nil.
+	// map if nil.  Sets the PropUpdated and ValUpdated flags and sends the
+	// NodeSignalUpdated and NodeSignalPropsUpdated signals (at the end of
+	// the update if the node is updating).
 	SetProp(key string, val any)
 
+	// SetPropNoUpdate sets given property key to value val, like SetProp but
+	// without any update signaling or flags, e.g., for bulk initialization of
+	// new nodes.
+	SetPropNoUpdate(key string, val any)
+
+	// SetProps sets a whole set of properties, with update flags and
+	// signals as in SetProp.
+	SetProps(props Props)
+
+	// SetPropsNoUpdate sets a whole set of properties, like SetProps but
+	// without any update signaling or flags, e.g., for bulk initialization of
+	// new nodes.
+	SetPropsNoUpdate(props Props)
+
+	// SetPropUpdated sets the PropUpdated and ValUpdated flags, and records
+	// given property keys as updated, to be returned by UpdatedProps until
+	// the next UpdateStart -- should be called after setting Props directly.
+	SetPropUpdated(keys ...string)
+
+	// UpdatedProps returns the keys of the properties that were set or
+	// deleted since the last UpdateStart, in the order they were first
+	// changed, when the PropUpdated flag is set.
+	UpdatedProps() []string
+
 	// Prop returns property value for key that is known to exist.
 	// Returns nil if it actually doesn't -- this version allows
 	// direct conversion of return.  See PropTry for version with
@@ -425,7 +476,9 @@ type Ki interface {
 	// (registered via KiT type registry).  Returns false if not set anywhere.
 	PropInherit(key string, inherit, typ bool) (any, bool)
 
-	// DeleteProp deletes property key on this node.
+	// DeleteProp deletes property key on this node -- if it was set,
+	// sets the PropUpdated and ValUpdated flags and sends signals as in
+	// SetProp.
 	DeleteProp(key string)
 
 	// PropTag returns the name to look for in type properties, for types
@@ -516,7 +569,8 @@ type Ki interface {
 	// UpdateEnd should be called when done updating after an UpdateStart, and
 	// passed the result of the UpdateStart call -- if this is true, the
 	// NodeSignalUpdated signal will be emitted and the Updating flag will be
-	// cleared, and DestroyDeleted called -- otherwise it is a no-op.
+	// cleared, and the deleted nodes of the tree destroyed (depending on the
+	// Timing of its DeleteManager) -- otherwise it is a no-op.
 	UpdateEnd(updt bool)
 
 	// UpdateEndNoSig is just like UpdateEnd except it does not emit a
@@ -546,7 +600,11 @@ type Ki interface {
 	// SetField sets given field name to given value, using very robust
 	// conversion routines to e.g., convert from strings to numbers, and
 	// vice-versa, automatically.  Returns error if not successfully set.
-	// wrapped in UpdateStart / End and sets the ValUpdated flag.
+	// The field can also be a dot-separated path into struct fields.
+	// wrapped in UpdateStart / End and sets the ValUpdated flag, and then
+	// calls the field watchers of the node whose values changed (see
+	// WatchField).  If StrictFields is on, values that are not valid
+	// according to the validation tags of the field are rejected.
 	SetField(field string, val any) error
 
 	//////////////////////////////////////////////////////////////////////////
@@ -658,6 +716,10 @@ type Func func(k Ki, level int, data any) bool
 // KiType is a Ki reflect.Type, suitable for checking for Type.Implements.
 var KiType = reflect.TypeOf((*Ki)(nil)).Elem()
 
+func init() {
+	kit.SetKiType(KiType)
+}
+
 // IsKi returns true if the given type implements the Ki interface at any
 // level of embedded structure.
 func IsKi(typ reflect.Type) bool {
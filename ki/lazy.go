@@ -0,0 +1,159 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/goki/ki/bitflag"
+)
+
+// ChildLoader is a function that loads the children of given node on
+// demand, e.g., from an external hierarchy such as a file system directory
+// or a database catalog, using the usual methods to add children (AddNewChild,
+// ConfigChildren etc).  See SetChildLoader.
+type ChildLoader func(k Ki) error
+
+// SetChildLoader registers a loader function that lazily loads the
+// children of given node on first access, via Children, NumChildren,
+// Child, ChildByName, ChildByType, FindPath, the FuncDown traversals, or
+// adding children.  The node is marked as ChildrenUnloaded if it does not
+// yet have any children.  The loader is called in the goroutine that first
+// accesses the children, and any error is logged -- use LoadChildren to
+// load explicitly and get the error.  Passing a nil loader removes any
+// existing loader, leaving the node as loaded.
+//
+// The loader is called on the node itself, within UpdateStart / End, so a
+// single update signal is sent with the ChildAdded flag set when it is done
+// (unless the node is already updating, in which case the signal is sent at
+// the end of the update).  The node is marked as loaded before the loader
+// is called, so the loader can add and access children through the node it
+// is passed or any other reference to it.  Other callers that access the
+// children before the load has started wait until it is finished, but, as
+// for any other change to the tree, those that access them while it is in
+// progress can see the children added so far.
+func SetChildLoader(k Ki, loader ChildLoader) {
+	n := k.AsNode()
+	mu := n.lockTree()
+	n.loader = loader
+	unloaded := loader != nil && len(n.Kids) == 0
+	unlockTree(mu)
+	n.SetFlagState(unloaded, int(ChildrenUnloaded))
+}
+
+// HasChildLoader returns true if the node has a ChildLoader.
+func HasChildLoader(k Ki) bool {
+	n := k.AsNode()
+	mu := n.rlockTree()
+	defer runlockTree(mu)
+	return n.loader != nil
+}
+
+// ChildrenLoaded returns true if the children of the node are loaded,
+// i.e., the ChildrenUnloaded flag is not set -- always true for nodes
+// without a ChildLoader.
+func ChildrenLoaded(k Ki) bool {
+	return !k.HasFlag(int(ChildrenUnloaded))
+}
+
+// LoadChildren loads the children of the node using its ChildLoader if
+// they are not yet loaded, returning any error from the loader.  If the
+// loader fails, the node is still marked as loaded -- call UnloadChildren
+// to try again on next access.
+func LoadChildren(k Ki) error {
+	return k.AsNode().loadChildren()
+}
+
+// UnloadChildren evicts the children of a node that has a ChildLoader,
+// destroying them, and marks it as ChildrenUnloaded, so they will be loaded
+// again on next access.  Does nothing if the node has no ChildLoader or
+// is already unloaded.
+func UnloadChildren(k Ki) {
+	if !HasChildLoader(k) || !ChildrenLoaded(k) {
+		return
+	}
+	k.DeleteChildren(DestroyKids)
+	k.SetFlag(int(ChildrenUnloaded))
+}
+
+// loadChildren is called by all the Node methods that access children,
+// loading them if ChildrenUnloaded is set.  The flag is cleared before the
+// loader is called on the node, so the loader can access the children it
+// adds (also through a node it captured), and loadMu makes other callers
+// that find the flag set wait until the load is finished.
+func (n *Node) loadChildren() error {
+	if !bitflag.HasAtomic(&n.Flag, int(ChildrenUnloaded)) {
+		return nil
+	}
+	n.loadMu.Lock()
+	defer n.loadMu.Unlock()
+	if !bitflag.HasAtomic(&n.Flag, int(ChildrenUnloaded)) { // loaded while waiting
+		return nil
+	}
+	mu := n.rlockTree()
+	loader := n.loader
+	runlockTree(mu)
+	if loader != nil && bitflag.HasAtomic(&n.Flag, int(SnapshotView)) { // view loaders install the kids directly
+		return loader(n.This())
+	}
+	bitflag.ClearAtomic(&n.Flag, int(ChildrenUnloaded))
+	if loader == nil {
+		return nil
+	}
+	updt := n.UpdateStart()
+	err := loader(n.This())
+	n.SetChildAdded()
+	n.UpdateEnd(updt)
+	if err != nil {
+		log.Printf("ki.Node %v: ChildLoader error: %v\n", n.Nm, err)
+	}
+	return err
+}
+
+// clearNodeState clears the internal state of a node that is a copy of
+// another node, so it does not share it
+func clearNodeState(n *Node) {
+	n.NodeSig = Signal{}
+	n.treeMu = nil
+	n.delMgr = nil
+	n.propCache = propCache{}
+	n.updtProps = nil
+	n.fieldWatchers = nil
+	n.nameIdx = nil
+	n.regID = 0
+	atomic.StoreInt64(&n.index, 0)
+	n.loader = nil
+	n.loadMu = sync.Mutex{}
+}
+
+// numKids returns the number of children of given node, loading them
+// first only if load is true.
+func numKids(k Ki, load bool) int {
+	if load {
+		return k.NumChildren()
+	}
+	n := k.AsNode()
+	mu := n.rlockTree()
+	defer runlockTree(mu)
+	return len(n.Kids)
+}
+
+// childTry returns the child at given index, loading the children
+// first only if load is true.
+func childTry(k Ki, load bool, idx int) (Ki, error) {
+	if load {
+		return k.ChildTry(idx)
+	}
+	n := k.AsNode()
+	mu := n.rlockTree()
+	defer runlockTree(mu)
+	if idx < 0 || idx >= len(n.Kids) {
+		return nil, fmt.Errorf("ki %v: invalid index: %v -- len = %v", n.Nm, idx, len(n.Kids))
+	}
+	return n.Kids[idx], nil
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goki/ki/bitflag"
+)
+
+// dirLoader simulates a directory hierarchy of given depth with nkids per level
+func dirLoader(nkids, depth int, nloads *int) ChildLoader {
+	var loader ChildLoader
+	loader = func(k Ki) error {
+		*nloads++
+		for i := 0; i < nkids; i++ {
+			kid := k.AddNewChild(KiT_NodeEmbed, fmt.Sprintf("%s_%d", k.Name(), i))
+			if depth > 1 {
+				SetChildLoader(kid, dirLoader(nkids, depth-1, nloads))
+			}
+		}
+		if k.NumChildren() != nkids { // loader can access its own children
+			return errors.New("wrong number of children in loader")
+		}
+		return nil
+	}
+	return loader
+}
+
+func TestLazyChildren(t *testing.T) {
+	root := NodeEmbed{}
+	root.InitName(&root, "root")
+	nloads := 0
+	SetChildLoader(&root, dirLoader(3, 2, &nloads))
+
+	if ChildrenLoaded(&root) || !root.HasFlag(int(ChildrenUnloaded)) || nloads != 0 {
+		t.Errorf("root should be unloaded")
+	}
+
+	res := make([]string, 0, 10)
+	recv := TestNode{}
+	recv.InitName(&recv, "recv")
+	root.NodeSignal().Connect(&recv, func(r, s Ki, sig int64, d any) {
+		res = append(res, fmt.Sprintf("%v %v", NodeSignals(sig), bitflag.Has(d.(int64), int(ChildAdded))))
+	})
+
+	if nk := root.NumChildren(); nk != 3 {
+		t.Errorf("NumChildren: %d != 3", nk)
+	}
+	if nloads != 1 || !ChildrenLoaded(&root) {
+		t.Errorf("root should be loaded once: %d", nloads)
+	}
+	if len(res) != 1 || res[0] != "NodeSignalUpdated true" {
+		t.Errorf("load should emit a single ChildAdded update: %v", res)
+	}
+	root.NumChildren()
+	if nloads != 1 {
+		t.Errorf("second access should not load again: %d", nloads)
+	}
+
+	// kids are not loaded until accessed
+	k0 := root.Child(0)
+	if ChildrenLoaded(k0) {
+		t.Errorf("kid should not be loaded yet")
+	}
+	if fk := root.FindPath("/root/root_1/root_1_2"); fk == nil || nloads != 2 {
+		t.Errorf("FindPath should load root_1: %v %d", fk, nloads)
+	}
+	if k0.ChildByName("root_0_1", 0) == nil || nloads != 3 {
+		t.Errorf("ChildByName should load root_0: %d", nloads)
+	}
+	cnt := 0
+	root.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		cnt++
+		return Continue
+	})
+	if cnt != 13 || nloads != 4 {
+		t.Errorf("traversal should visit 13 nodes and load last kid: %d %d", cnt, nloads)
+	}
+
+	// unload
+	UnloadChildren(k0)
+	if ChildrenLoaded(k0) || len(k0.AsNode().Kids) != 0 {
+		t.Errorf("k0 should be unloaded")
+	}
+	if k0.NumChildren() != 3 || nloads != 5 {
+		t.Errorf("k0 should reload on access: %d", nloads)
+	}
+
+	// empty vs not loaded
+	empty := root.AddNewChild(KiT_NodeEmbed, "empty")
+	SetChildLoader(empty, func(k Ki) error { return nil })
+	if ChildrenLoaded(empty) {
+		t.Errorf("empty should be unloaded before access")
+	}
+	if empty.HasChildren() || !ChildrenLoaded(empty) {
+		t.Errorf("empty should be loaded with no children")
+	}
+
+	// errors
+	bad := root.AddNewChild(KiT_NodeEmbed, "bad")
+	SetChildLoader(bad, func(k Ki) error { return errors.New("load failed") })
+	if err := LoadChildren(bad); err == nil {
+		t.Errorf("LoadChildren should return loader error")
+	}
+	if err := LoadChildren(bad); err != nil {
+		t.Errorf("LoadChildren should not reload: %v", err)
+	}
+}
+
+func TestLazyChildrenConcurrent(t *testing.T) {
+	root := NodeEmbed{}
+	root.InitName(&root, "root")
+	SetTreeLocking(&root, true)
+	nloads := 0
+	SetChildLoader(&root, dirLoader(5, 1, &nloads))
+
+	var wg sync.WaitGroup
+	nks := make([]int, 8)
+	for i := range nks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nks[i] = root.NumChildren()
+		}(i)
+	}
+	wg.Wait()
+	for i, nk := range nks {
+		if nk > 5 {
+			t.Errorf("reader %d saw too many children: %d", i, nk)
+		}
+	}
+	if root.NumChildren() != 5 {
+		t.Errorf("root should have 5 children: %d", root.NumChildren())
+	}
+	if nloads != 1 {
+		t.Errorf("root should be loaded once: %d", nloads)
+	}
+	for _, kid := range root.Kids {
+		if kid.Parent() != root.This() {
+			t.Errorf("kid %v parent not set to root", kid.Name())
+		}
+	}
+}
+
+func TestLazyChildrenCaptured(t *testing.T) {
+	root := &NodeEmbed{}
+	root.InitName(root, "root")
+	recv := &NodeEmbed{}
+	recv.InitName(recv, "recv")
+	SetChildLoader(root, func(k Ki) error {
+		root.AddNewChild(KiT_NodeEmbed, "a") // captured node, not k
+		root.Mbr1 = "loaded"
+		root.NodeSignal().Connect(recv, func(r, s Ki, sig int64, d any) {})
+		if root.NumChildren() != 1 {
+			return errors.New("loader should see its own children")
+		}
+		return nil
+	})
+	done := make(chan error)
+	go func() {
+		done <- LoadChildren(root)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("loader using the captured node deadlocked")
+	}
+	if root.NumChildren() != 1 || root.Child(0).Parent() != root.This() || root.Mbr1 != "loaded" || len(root.NodeSig.Cons) != 1 {
+		t.Errorf("loader changes to the node should be kept: %v %v %v", root.Kids, root.Mbr1, len(root.NodeSig.Cons))
+	}
+}
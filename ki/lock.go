@@ -0,0 +1,183 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import "sync"
+
+// Tree locking is an optional mode that makes a tree safe for access from
+// multiple goroutines.  By default, the Kids, Props, Par and Nm fields of
+// nodes are not protected (only the Flag bits are atomic), which is fastest
+// for the typical case where a tree is only used from one goroutine.
+//
+// When locking is turned on for a tree via SetTreeLocking on its root, all
+// nodes in the tree share a single sync.RWMutex.  The Node mutator methods
+// (Add*, Insert*, SetChild, Delete*, SetName, SetProp, SetProps, DeleteProp,
+// SetField, MoveToParent, ConfigChildren) take the write lock while modifying
+// the tree, and the accessors (Name, Parent, Children, NumChildren, Child*,
+// IndexInParent, Prop*, Path, FindPath and the FuncDown* traversals) take the
+// read lock while reading it.
+// The lock is only held during the raw access itself, never while calling
+// other Ki methods, callbacks or signals, so these methods can be freely
+// combined.  Nodes added to a locked tree automatically share its mutex.
+//
+// Use TreeLock / TreeRLock for batch operations that must be atomic with
+// respect to other goroutines.  While holding the lock, access the Node
+// fields (Kids, Props etc) and Slice methods directly, as the Ki methods
+// acquire the lock themselves and would deadlock.
+//
+// Note that the Slice returned by Children() is only protected while it is
+// being obtained: use TreeRLock while iterating over it in a locked tree.
+
+// SetTreeLocking turns goroutine-safe locking mode on or off for the
+// entire tree containing given node -- see the tree locking docs above.
+// This must be called before any concurrent access to the tree begins.
+func SetTreeLocking(k Ki, on bool) {
+	root := Root(k)
+	if root == nil {
+		return
+	}
+	var mu *sync.RWMutex
+	if on {
+		mu = &sync.RWMutex{}
+	}
+	setTreeMu(root.AsNode(), mu)
+}
+
+// IsTreeLocking returns true if goroutine-safe locking mode is on
+// for the tree containing given node.
+func IsTreeLocking(k Ki) bool {
+	return k.AsNode().treeMu != nil
+}
+
+// TreeLock acquires the write lock on the tree containing given node,
+// if tree locking is on (otherwise it does nothing).  Ki methods must
+// not be called on the tree until TreeUnlock is called.
+func TreeLock(k Ki) {
+	if mu := k.AsNode().treeMu; mu != nil {
+		mu.Lock()
+	}
+}
+
+// TreeUnlock releases the write lock acquired by TreeLock.
+func TreeUnlock(k Ki) {
+	if mu := k.AsNode().treeMu; mu != nil {
+		mu.Unlock()
+	}
+}
+
+// TreeRLock acquires the read lock on the tree containing given node,
+// if tree locking is on (otherwise it does nothing).  Ki methods must
+// not be called on the tree until TreeRUnlock is called.
+func TreeRLock(k Ki) {
+	if mu := k.AsNode().treeMu; mu != nil {
+		mu.RLock()
+	}
+}
+
+// TreeRUnlock releases the read lock acquired by TreeRLock.
+func TreeRUnlock(k Ki) {
+	if mu := k.AsNode().treeMu; mu != nil {
+		mu.RUnlock()
+	}
+}
+
+// setTreeMu sets the tree mutex on given node and everything below it,
+// including Ki fields.  It walks the Kids directly without locking,
+// so it must only be called on nodes not yet visible in a locked tree,
+// or under the write lock.
+func setTreeMu(n *Node, mu *sync.RWMutex) {
+	if n == nil || n.treeMu == mu {
+		return
+	}
+	n.treeMu = mu
+	if KiHasKiFields(n) {
+		nf := NumKiFields(n)
+		for i := 0; i < nf; i++ {
+			if fk := KiField(n, i); fk != nil {
+				setTreeMu(fk.AsNode(), mu)
+			}
+		}
+	}
+	for _, kid := range n.Kids {
+		if kid != nil {
+			setTreeMu(kid.AsNode(), mu)
+		}
+	}
+}
+
+// lockTree acquires the write lock if tree locking is on, returning the
+// mutex to pass to unlockTree (nil if locking is off).
+func (n *Node) lockTree() *sync.RWMutex {
+	mu := n.treeMu
+	if mu != nil {
+		mu.Lock()
+	}
+	return mu
+}
+
+// rlockTree acquires the read lock if tree locking is on, returning the
+// mutex to pass to runlockTree (nil if locking is off).
+func (n *Node) rlockTree() *sync.RWMutex {
+	mu := n.treeMu
+	if mu != nil {
+		mu.RLock()
+	}
+	return mu
+}
+
+// unlockTree releases a write lock acquired with lockTree.
+func unlockTree(mu *sync.RWMutex) {
+	if mu != nil {
+		mu.Unlock()
+	}
+}
+
+// runlockTree releases a read lock acquired with rlockTree.
+func runlockTree(mu *sync.RWMutex) {
+	if mu != nil {
+		mu.RUnlock()
+	}
+}
+
+// appendKid adds kid at the end of Kids under the write lock, first
+// passing on our tree mutex to the kid subtree so it is visible to any
+// reader that can see the kid.
+func (n *Node) appendKid(kid Ki) {
+	n.loadChildren()
+	n.snapshotSave()
+	mu := n.lockTree()
+	setTreeMu(kid.AsNode(), n.treeMu)
+	idxcur := n.nameIndexCurrent()
+	n.Kids = append(n.Kids, kid)
+	n.nameIndexAppend(kid, idxcur)
+	unlockTree(mu)
+}
+
+// insertKid inserts kid into Kids at given index under the write lock,
+// first passing on our tree mutex to the kid subtree (see appendKid).
+func (n *Node) insertKid(kid Ki, at int) {
+	n.loadChildren()
+	n.snapshotSave()
+	mu := n.lockTree()
+	setTreeMu(kid.AsNode(), n.treeMu)
+	n.Kids.Insert(kid, at)
+	unlockTree(mu)
+}
+
+// kidsSnapshot returns the children of given node for iteration: if tree
+// locking is on, this is a copy made under the read lock, otherwise it is
+// just the Kids slice itself.
+func kidsSnapshot(k Ki) Slice {
+	n := k.AsNode()
+	n.loadChildren()
+	mu := n.rlockTree()
+	if mu == nil {
+		return n.Kids
+	}
+	kids := make(Slice, len(n.Kids))
+	copy(kids, n.Kids)
+	mu.RUnlock()
+	return kids
+}
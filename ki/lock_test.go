@@ -0,0 +1,146 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestTreeLockingSetup(t *testing.T) {
+	parent := NodeField{}
+	parent.InitName(&parent, "par1")
+	child := parent.AddNewChild(KiT_NodeField, "child1")
+	if IsTreeLocking(&parent) {
+		t.Errorf("tree locking should be off by default")
+	}
+	SetTreeLocking(child, true) // applies to whole tree
+	if !IsTreeLocking(&parent) || !IsTreeLocking(child) || !IsTreeLocking(&parent.Field1) {
+		t.Errorf("tree locking should be on for all nodes and fields in the tree")
+	}
+	nc := parent.AddNewChild(KiT_NodeEmbed, "child2")
+	if !IsTreeLocking(nc) {
+		t.Errorf("new child should inherit tree locking")
+	}
+	if nc.AsNode().treeMu != parent.treeMu {
+		t.Errorf("new child should share tree mutex")
+	}
+	SetTreeLocking(&parent, false)
+	if IsTreeLocking(nc) || IsTreeLocking(&parent.Field1) {
+		t.Errorf("tree locking should be off for all nodes")
+	}
+}
+
+func TestTreeLockingConcurrent(t *testing.T) {
+	parent := NodeEmbed{}
+	parent.InitName(&parent, "par1")
+	for i := 0; i < 10; i++ {
+		parent.AddNewChild(KiT_NodeEmbed, fmt.Sprintf("child%d", i))
+	}
+	SetTreeLocking(&parent, true)
+
+	var wg sync.WaitGroup
+	nwrite := 4
+	nread := 4
+	niter := 100
+	for w := 0; w < nwrite; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < niter; i++ {
+				nm := fmt.Sprintf("w%d_%d", w, i)
+				kid := parent.AddNewChild(KiT_NodeEmbed, nm)
+				kid.AddNewChild(KiT_NodeEmbed, "sub")
+				kid.SetProp("idx", i)
+				kid.SetName(nm + "_x")
+				if err := kid.SetField("Mbr2", i); err != nil {
+					t.Error(err)
+				}
+				parent.SetProp(nm, i)
+				if i%2 == 0 {
+					parent.DeleteChild(kid, NoDestroyKids)
+					parent.DeleteProp(nm)
+				}
+			}
+		}(w)
+	}
+	for r := 0; r < nread; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < niter; i++ {
+				cnt := 0
+				parent.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+					cnt++
+					k.Prop("idx")
+					k.PropInherit("intprop", Inherit, TypeProps)
+					return Continue
+				})
+				if cnt == 0 {
+					t.Errorf("traversal visited no nodes")
+				}
+				parent.FuncDownMeLast(0, nil, func(k Ki, level int, d any) bool {
+					return Continue
+				}, func(k Ki, level int, d any) bool {
+					return Continue
+				})
+				parent.ChildByName("child5", StartMiddle)
+				if parent.FindPath("/par1/child3") == nil {
+					t.Errorf("FindPath failed to find child3")
+				}
+				TreeRLock(&parent)
+				for _, kid := range parent.Kids {
+					_ = kid.AsNode().Nm
+				}
+				TreeRUnlock(&parent)
+			}
+		}()
+	}
+	wg.Wait()
+
+	exp := 10 + nwrite*niter/2
+	if nc := parent.NumChildren(); nc != exp {
+		t.Errorf("NumChildren: %d != expected: %d", nc, exp)
+	}
+}
+
+func TestTreeLockingBatch(t *testing.T) {
+	parent := NodeEmbed{}
+	parent.InitName(&parent, "par1")
+	child := parent.AddNewChild(KiT_NodeEmbed, "child1")
+	parent.SetProp("val", 0)
+	child.SetProp("val", 0)
+	SetTreeLocking(&parent, true)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				// set both at once, so readers never see a partial batch
+				TreeLock(&parent)
+				parent.Props["val"] = w*1000 + i
+				child.AsNode().Props["val"] = w*1000 + i
+				TreeUnlock(&parent)
+			}
+		}(w)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				TreeRLock(child)
+				pv := parent.Props["val"]
+				cv := child.AsNode().Props["val"]
+				TreeRUnlock(child)
+				if pv != cv {
+					t.Errorf("saw partial batch: %v != %v", pv, cv)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,576 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goki/ki/kit"
+)
+
+// Merge does a three-way merge of two trees, ours and theirs, that were
+// both derived from a common base tree, e.g., copies of the same saved tree
+// edited by different users.  Nodes are matched by name among the children
+// of matched parents, and must also have the same type to be merged
+// recursively.  Children with the same name are matched by their order
+// among the children with that name: the one at occurrence n (starting at
+// 0) is identified as name[n] for n > 0 in the paths of conflicts, e.g.,
+// item, item[1], item[2].  Changes made on only one
+// side relative to base are taken automatically, including child
+// insertions, deletions and moves, field changes, and property changes
+// (including deletions).  Changes made on both sides are conflicts, unless
+// they are identical (e.g., the same child deleted on both sides): each conflict is passed to the resolve function,
+// which chooses the version to use, and all conflicts are returned.  If
+// resolve is nil, ours is used for all conflicts.
+//
+// Fields are compared and merged individually, for all exported fields
+// (including in embedded structs) other than those of the Node itself,
+// Signal fields, and fields with a `copy:"-"` tag.  Ki fields are merged
+// recursively like children.  Values are compared with reflect.DeepEqual.
+//
+// The merged result is applied to the target tree with a single CopyFrom
+// call, which is wrapped in UpdateStart / End and uses ConfigChildren to
+// preserve existing nodes in the target, so the target is typically ours
+// (or a copy of it).  The target must have the same type as the merged
+// root.  See MergeTrees to get the merged tree instead, which must be used
+// for trees with duplicate child names, as ConfigChildren matches children
+// by name.
+func Merge(target, base, ours, theirs Ki, resolve MergeResolver) ([]MergeConflict, error) {
+	res, conflicts := MergeTrees(base, ours, theirs, resolve)
+	if res == nil {
+		return conflicts, fmt.Errorf("ki.Merge into %v: the merged root was deleted", target.Path())
+	}
+	err := target.CopyFrom(res)
+	res.Destroy()
+	return conflicts, err
+}
+
+// MergeTrees does a three-way merge of ours and theirs relative to base
+// as described in Merge, returning the merged tree as a new tree, and all
+// the conflicts.  Any of the roots can be nil, e.g., base for two trees
+// created independently.  Returns a nil tree if the merge results in no
+// root, e.g., if it was deleted by the conflict resolution.
+func MergeTrees(base, ours, theirs Ki, resolve MergeResolver) (Ki, []MergeConflict) {
+	m := merger{resolve: resolve}
+	nm := ""
+	switch {
+	case ours != nil:
+		nm = ours.Name()
+	case theirs != nil:
+		nm = theirs.Name()
+	case base != nil:
+		nm = base.Name()
+	}
+	res := m.mergeNode("/"+nm, base, ours, theirs)
+	return res, m.conflicts
+}
+
+// MergeConflictTypes are the different kinds of conflicts in a Merge.
+type MergeConflictTypes int32
+
+//go:generate stringer -type=MergeConflictTypes
+
+var KiT_MergeConflictTypes = kit.Enums.AddEnum(MergeConflictTypesN, kit.NotBitFlag, nil)
+
+const (
+	// MergeFieldConflict means a field of a node was changed to different
+	// values in ours and theirs.
+	MergeFieldConflict MergeConflictTypes = iota
+
+	// MergePropConflict means a property of a node was set to different
+	// values in ours and theirs, or deleted on one side and changed on the
+	// other -- a nil value means the property is not set.
+	MergePropConflict
+
+	// MergeChildConflict means a child was deleted on one side
+	// and changed on the other, or added or changed to different types on
+	// both sides -- the values are Ki nodes, nil if the child is not present.
+	MergeChildConflict
+
+	// MergeOrderConflict means the children of a node were reordered
+	// differently on both sides -- the values are []string lists of the names
+	// of the children present on all sides, in their order on each side, where
+	// the n-th occurrence of a duplicate name is the child at occurrence n.
+	MergeOrderConflict
+
+	MergeConflictTypesN
+)
+
+// MergeChoices are the ways of resolving a MergeConflict.
+type MergeChoices int32
+
+//go:generate stringer -type=MergeChoices
+
+var KiT_MergeChoices = kit.Enums.AddEnum(MergeChoicesN, kit.NotBitFlag, nil)
+
+const (
+	// MergeOurs uses the Ours version.
+	MergeOurs MergeChoices = iota
+
+	// MergeTheirs uses the Theirs version.
+	MergeTheirs
+
+	// MergeBase uses the Base version, reverting both changes.
+	MergeBase
+
+	// MergeCustom uses the Value set on the conflict by the resolver, which
+	// must be of the same kind as the other values: a field value (converted
+	// with kit.SetRobust if needed), a property value, a Ki node to be
+	// cloned, or an ordered []string list of child names.
+	MergeCustom
+
+	MergeChoicesN
+)
+
+// MergeResolver is a function that resolves a Merge conflict, returning
+// the choice of version to use -- it can also set the Value of the
+// conflict to use with MergeCustom.
+type MergeResolver func(c *MergeConflict) MergeChoices
+
+// MergeConflict records one conflict found in a Merge, and its resolution.
+type MergeConflict struct {
+
+	// type of conflict
+	Type MergeConflictTypes `desc:"type of conflict"`
+
+	// path of the node with the conflict, built from the names of the merged nodes, with Ki fields as .Field -- for MergeChildConflict this is the path of the child
+	Path string `desc:"path of the node with the conflict, built from the names of the merged nodes, with Ki fields as .Field -- for MergeChildConflict this is the path of the child"`
+
+	// name of the field or property with the conflict, or the child name for MergeChildConflict
+	Key string `desc:"name of the field or property with the conflict, or the child name for MergeChildConflict"`
+
+	// value in base -- nil if not present
+	Base any `desc:"value in base -- nil if not present"`
+
+	// value in ours -- nil if not present
+	Ours any `desc:"value in ours -- nil if not present"`
+
+	// value in theirs -- nil if not present
+	Theirs any `desc:"value in theirs -- nil if not present"`
+
+	// the choice made to resolve the conflict
+	Choice MergeChoices `desc:"the choice made to resolve the conflict"`
+
+	// custom value to use for MergeCustom, set by the resolver
+	Value any `desc:"custom value to use for MergeCustom, set by the resolver"`
+}
+
+// String returns a one-line description of the conflict.
+func (mc *MergeConflict) String() string {
+	if mc.Type == MergeChildConflict || mc.Type == MergeOrderConflict {
+		return fmt.Sprintf("%v at %v: resolved as %v", mc.Type, mc.Path, mc.Choice)
+	}
+	return fmt.Sprintf("%v at %v: %v base: %v ours: %v theirs: %v, resolved as %v", mc.Type, mc.Path, mc.Key, mc.Base, mc.Ours, mc.Theirs, mc.Choice)
+}
+
+// merger holds the state of a Merge
+type merger struct {
+	resolve   MergeResolver
+	conflicts []MergeConflict
+}
+
+// conflict records given conflict, resolving it, and returns the chosen value
+func (m *merger) conflict(c MergeConflict) any {
+	c.Choice = MergeOurs
+	if m.resolve != nil {
+		c.Choice = m.resolve(&c)
+	}
+	m.conflicts = append(m.conflicts, c)
+	switch c.Choice {
+	case MergeTheirs:
+		return c.Theirs
+	case MergeBase:
+		return c.Base
+	case MergeCustom:
+		return c.Value
+	}
+	return c.Ours
+}
+
+// merge3 returns the three-way merge of the values in given conflict,
+// calling conflict only if both sides changed to different values.
+func (m *merger) merge3(c MergeConflict) any {
+	switch {
+	case reflect.DeepEqual(c.Ours, c.Theirs):
+		return c.Ours
+	case reflect.DeepEqual(c.Base, c.Ours):
+		return c.Theirs
+	case reflect.DeepEqual(c.Base, c.Theirs):
+		return c.Ours
+	}
+	return m.conflict(c)
+}
+
+// mergeNode returns the merged version of given nodes, any of which can be
+// nil if not present: nodes of the same type in ours and theirs are merged
+// recursively, and otherwise a clone of the chosen version is returned.
+func (m *merger) mergeNode(path string, b, o, t Ki) Ki {
+	if o != nil && t != nil && Type(o) == Type(t) {
+		if b != nil && Type(b) != Type(o) {
+			b = nil
+		}
+		res := NewOfType(Type(o))
+		res.InitName(res, o.Name())
+		m.mergeInto(path, res, b, o, t)
+		return res
+	}
+	var ch Ki
+	switch {
+	case treeEqual(o, t): // including both deleted
+		ch = o
+	case treeEqual(b, t):
+		ch = o
+	case treeEqual(b, o):
+		ch = t
+	default:
+		var bi, oi, ti any // avoid non-nil interfaces holding nil Ki
+		if b != nil {
+			bi = b
+		}
+		if o != nil {
+			oi = o
+		}
+		if t != nil {
+			ti = t
+		}
+		key := path[strings.LastIndex(path, "/")+1:]
+		cv := m.conflict(MergeConflict{Type: MergeChildConflict, Path: path, Key: key, Base: bi, Ours: oi, Theirs: ti})
+		ch, _ = cv.(Ki)
+	}
+	if ch == nil {
+		return nil
+	}
+	return ch.Clone()
+}
+
+// mergeInto merges the fields, properties, Ki fields and children of given
+// nodes into res, which is a new node of the same type as o and t -- b is
+// nil if not present or of a different type.
+func (m *merger) mergeInto(path string, res, b, o, t Ki) {
+	for _, fn := range valueFieldNames(Type(o)) {
+		c := MergeConflict{Type: MergeFieldConflict, Path: path, Key: fn}
+		c.Ours = kit.FlatFieldValueByName(o, fn).Interface()
+		c.Theirs = kit.FlatFieldValueByName(t, fn).Interface()
+		if b != nil {
+			c.Base = kit.FlatFieldValueByName(b, fn).Interface()
+		}
+		val := m.merge3(c)
+		rf := kit.FlatFieldValueByName(res, fn)
+		vv := reflect.ValueOf(val)
+		switch {
+		case val == nil:
+			rf.Set(reflect.Zero(rf.Type()))
+		case vv.Type().AssignableTo(rf.Type()):
+			rf.Set(vv)
+		default:
+			if !kit.SetRobust(kit.PtrValue(rf).Interface(), val) {
+				log.Printf("ki.Merge: %v field %v could not be set to merged value: %v\n", path, fn, val)
+			}
+		}
+	}
+
+	var bp, op, tp Props
+	if b != nil {
+		bp = *b.Properties()
+	}
+	op, tp = *o.Properties(), *t.Properties()
+	var keys []string
+	for _, pr := range []Props{op, tp, bp} {
+		for k := range pr {
+			keys = append(keys, k)
+		}
+	}
+	keys = uniqueSorted(keys)
+	rn := res.AsNode()
+	for _, k := range keys {
+		val := m.merge3(MergeConflict{Type: MergePropConflict, Path: path, Key: k, Base: bp[k], Ours: op[k], Theirs: tp[k]})
+		if val != nil {
+			if rn.Props == nil {
+				rn.Props = make(Props)
+			}
+			rn.Props[k] = val
+		}
+	}
+	rn.propsChanged()
+
+	on, tn := o.AsNode(), t.AsNode()
+	for i, fnm := range KiFieldNames(rn) {
+		var bf Ki
+		if b != nil {
+			bf = KiField(b.AsNode(), i)
+		}
+		m.mergeInto(path+"."+fnm, KiField(rn, i), bf, KiField(on, i), KiField(tn, i))
+	}
+
+	bk, bkids := kidKeys(b)
+	ok, okids := kidKeys(o)
+	tk, tkids := kidKeys(t)
+	kkeys := uniqueKeysInOrder(ok, tk, bk)
+	merged := make(map[kidKey]Ki, len(kkeys))
+	for _, kk := range kkeys {
+		kid := m.mergeNode(path+"/"+kk.String(), bkids[kk], okids[kk], tkids[kk])
+		if kid != nil {
+			merged[kk] = kid
+		}
+	}
+	for _, kk := range m.mergeOrder(path, bk, ok, tk, merged) {
+		res.AddChild(merged[kk])
+	}
+}
+
+// mergeOrder returns the merged order of the merged children, given the
+// keys of the children in base, ours and theirs: if only one side changed
+// the relative order of the children present on all sides, that order is
+// used, and otherwise it is a conflict.  New children are inserted after
+// the preceding child on the side that added them.
+func (m *merger) mergeOrder(path string, bk, ok, tk []kidKey, merged map[kidKey]Ki) []kidKey {
+	oset, tset := make(map[kidKey]bool, len(ok)), make(map[kidKey]bool, len(tk))
+	for _, kk := range ok {
+		oset[kk] = true
+	}
+	for _, kk := range tk {
+		tset[kk] = true
+	}
+	common := func(kks []kidKey) []kidKey {
+		var cm []kidKey
+		for _, kk := range kks {
+			if oset[kk] && tset[kk] && merged[kk] != nil {
+				cm = append(cm, kk)
+			}
+		}
+		return cm
+	}
+	sb, so, st := common(bk), common(ok), common(tk)
+	if bk == nil { // no base: treat ours as base
+		sb = so
+	}
+	var prim []kidKey
+	switch {
+	case reflect.DeepEqual(so, sb):
+		prim = tk
+	case reflect.DeepEqual(st, sb), reflect.DeepEqual(so, st):
+		prim = ok
+	default:
+		cv := m.conflict(MergeConflict{Type: MergeOrderConflict, Path: path, Base: kidKeyNames(sb), Ours: kidKeyNames(so), Theirs: kidKeyNames(st)})
+		nms, _ := cv.([]string)
+		prim = namesKidKeys(nms)
+	}
+	var order []kidKey
+	placed := make(map[kidKey]bool, len(merged))
+	order = insertMissing(order, prim, merged, placed)
+	order = insertMissing(order, ok, merged, placed)
+	order = insertMissing(order, tk, merged, placed)
+	order = insertMissing(order, bk, merged, placed)
+	var rest []kidKey
+	for kk := range merged {
+		if !placed[kk] {
+			rest = append(rest, kk)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		if rest[i].name != rest[j].name {
+			return rest[i].name < rest[j].name
+		}
+		return rest[i].occ < rest[j].occ
+	})
+	return append(order, rest...)
+}
+
+// insertMissing inserts the keys in src that are in merged and not yet
+// placed into order, after the closest preceding key in src that is
+// already placed (or at the start), returning the updated order.
+func insertMissing(order, src []kidKey, merged map[kidKey]Ki, placed map[kidKey]bool) []kidKey {
+	for i, nm := range src {
+		if placed[nm] || merged[nm] == nil {
+			continue
+		}
+		at := 0
+		for j := i - 1; j >= 0; j-- {
+			if placed[src[j]] {
+				for oi, onm := range order {
+					if onm == src[j] {
+						at = oi + 1
+						break
+					}
+				}
+				break
+			}
+		}
+		order = append(order, kidKey{})
+		copy(order[at+1:], order[at:])
+		order[at] = nm
+		placed[nm] = true
+	}
+	return order
+}
+
+// valueFieldNames returns the names of the value fields of given Ki type,
+// which are merged by Merge and shown by Dump: exported fields, including
+// in embedded structs, other than those of the Node itself, Ki and Signal
+// fields, and `copy:"-"` fields.
+func valueFieldNames(typ reflect.Type) []string {
+	typ = kit.NonPtrType(typ)
+	if kit.ShortTypeName(typ) == "ki.Node" {
+		return nil
+	}
+	var fns []string
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("copy") == "-" {
+			continue
+		}
+		if f.Type.Kind() == reflect.Struct && f.Anonymous {
+			fns = append(fns, valueFieldNames(f.Type)...)
+			continue
+		}
+		if f.Type == KiT_Signal || (f.Type.Kind() == reflect.Struct && IsKi(f.Type)) {
+			continue
+		}
+		fns = append(fns, f.Name)
+	}
+	return fns
+}
+
+// treeEqual returns true if the two trees have the same types, names,
+// merged field values, properties, Ki fields and children -- either or
+// both can be nil.
+func treeEqual(a, b Ki) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if Type(a) != Type(b) || a.Name() != b.Name() {
+		return false
+	}
+	for _, fn := range valueFieldNames(Type(a)) {
+		if !reflect.DeepEqual(kit.FlatFieldValueByName(a, fn).Interface(), kit.FlatFieldValueByName(b, fn).Interface()) {
+			return false
+		}
+	}
+	ap, bp := *a.Properties(), *b.Properties()
+	if (len(ap) > 0 || len(bp) > 0) && !reflect.DeepEqual(ap, bp) {
+		return false
+	}
+	an, bn := a.AsNode(), b.AsNode()
+	for i := 0; i < NumKiFields(an); i++ {
+		if !treeEqual(KiField(an, i), KiField(bn, i)) {
+			return false
+		}
+	}
+	ak, bk := *a.Children(), *b.Children()
+	if len(ak) != len(bk) {
+		return false
+	}
+	for i := range ak {
+		if !treeEqual(ak[i], bk[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// kidKey is the key that a child is matched by in a Merge: its name and
+// its occurrence among the children with that name
+type kidKey struct {
+	name string
+	occ  int
+}
+
+// String returns the key as used in conflict paths: the name, with [n]
+// added for the occurrence n > 0 of a duplicate name
+func (kk kidKey) String() string {
+	if kk.occ == 0 {
+		return kk.name
+	}
+	return fmt.Sprintf("%s[%d]", kk.name, kk.occ)
+}
+
+// kidKeys returns the keys of the children of given node, in order, and a
+// map from keys to children.
+func kidKeys(k Ki) ([]kidKey, map[kidKey]Ki) {
+	if k == nil {
+		return nil, nil
+	}
+	kids := *k.Children()
+	nms := make([]string, len(kids))
+	for i, kid := range kids {
+		nms[i] = kid.Name()
+	}
+	keys := namesKidKeys(nms)
+	byKey := make(map[kidKey]Ki, len(kids))
+	for i, kk := range keys {
+		byKey[kk] = kids[i]
+	}
+	return keys, byKey
+}
+
+// namesKidKeys returns the keys for given list of child names, where the
+// n-th occurrence of a name is occurrence n
+func namesKidKeys(nms []string) []kidKey {
+	if nms == nil {
+		return nil
+	}
+	keys := make([]kidKey, len(nms))
+	occ := make(map[string]int)
+	for i, nm := range nms {
+		keys[i] = kidKey{nm, occ[nm]}
+		occ[nm]++
+	}
+	return keys
+}
+
+// kidKeyNames returns the names of given keys
+func kidKeyNames(keys []kidKey) []string {
+	if keys == nil {
+		return nil
+	}
+	nms := make([]string, len(keys))
+	for i, kk := range keys {
+		nms[i] = kk.name
+	}
+	return nms
+}
+
+// uniqueKeysInOrder returns the unique keys in the given lists, in order
+// of first appearance
+func uniqueKeysInOrder(lists ...[]kidKey) []kidKey {
+	var keys []kidKey
+	has := make(map[kidKey]bool)
+	for _, ls := range lists {
+		for _, kk := range ls {
+			if !has[kk] {
+				has[kk] = true
+				keys = append(keys, kk)
+			}
+		}
+	}
+	return keys
+}
+
+// uniqueInOrder returns the unique names in the given lists, in order of
+// first appearance
+func uniqueInOrder(lists ...[]string) []string {
+	var nms []string
+	has := make(map[string]bool)
+	for _, ls := range lists {
+		for _, nm := range ls {
+			if !has[nm] {
+				has[nm] = true
+				nms = append(nms, nm)
+			}
+		}
+	}
+	return nms
+}
+
+// uniqueSorted returns the sorted unique names in given list
+func uniqueSorted(nms []string) []string {
+	nms = uniqueInOrder(nms)
+	sort.Strings(nms)
+	return nms
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func mergeTestTree() *NodeField {
+	base := &NodeField{}
+	base.InitName(base, "par1")
+	for i := 1; i <= 4; i++ {
+		kid := base.AddNewChild(KiT_NodeEmbed, fmt.Sprintf("child%d", i)).(*NodeEmbed)
+		kid.Mbr2 = i
+		kid.SetProp("p", i)
+	}
+	base.Field1.Mbr1 = "field1"
+	return base
+}
+
+func TestMerge(t *testing.T) {
+	base := mergeTestTree()
+	ours := base.Clone().(*NodeField)
+	theirs := base.Clone().(*NodeField)
+
+	// ours
+	ours.Child(0).(*NodeEmbed).Mbr2 = 5
+	ours.Child(1).(*NodeEmbed).Mbr2 = 7
+	ours.InsertNewChild(KiT_NodeEmbed, 2, "ours_new")
+	ours.DeleteChildByName("child4", DestroyKids)
+	ours.SetProp("a", "ours")
+	ours.Field1.Mbr1 = "ofield"
+
+	// theirs
+	theirs.Child(0).(*NodeEmbed).Mbr2 = 5 // same change: no conflict
+	theirs.Child(0).DeleteProp("p")
+	theirs.Child(1).(*NodeEmbed).Mbr1 = "t"
+	theirs.Child(1).(*NodeEmbed).Mbr2 = 8
+	theirs.Child(3).SetProp("p", 40) // ours deleted it: conflict
+	theirs.AddNewChild(KiT_NodeEmbed, "their_new")
+	theirs.MoveChild(2, 0)
+
+	nupdt := 0
+	recv := Node{}
+	recv.InitName(&recv, "recv")
+	ours.NodeSignal().Connect(&recv, func(r, s Ki, sig int64, d any) {
+		if NodeSignals(sig) == NodeSignalUpdated {
+			nupdt++
+		}
+	})
+	child1 := ours.Child(0)
+
+	conflicts, err := Merge(ours, base, ours, theirs, func(c *MergeConflict) MergeChoices {
+		if c.Type == MergeFieldConflict {
+			c.Value = 42
+			return MergeCustom
+		}
+		return MergeOurs
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if len(conflicts) != 2 {
+		t.Errorf("expected 2 conflicts, got: %v", conflicts)
+	} else {
+		if c := conflicts[0]; c.Type != MergeFieldConflict || c.Path != "/par1/child2" || c.Key != "Mbr2" || c.Ours != 7 || c.Theirs != 8 {
+			t.Errorf("field conflict: %v", c.String())
+		}
+		if c := conflicts[1]; c.Type != MergeChildConflict || c.Key != "child4" || c.Ours != nil || c.Theirs == nil {
+			t.Errorf("child conflict: %v", c.String())
+		}
+	}
+	if nupdt != 1 {
+		t.Errorf("merge should send one update signal, sent: %d", nupdt)
+	}
+
+	nms := strings.Join(kidNames(ours), ",")
+	if nms != "child3,child1,child2,ours_new,their_new" {
+		t.Errorf("merged children: %v", nms)
+	}
+	if ours.ChildByName("child1", 0) != child1 {
+		t.Errorf("existing children should be preserved in target")
+	}
+	c1 := ours.ChildByName("child1", 0).(*NodeEmbed)
+	if c1.Mbr2 != 5 || c1.Prop("p") != nil {
+		t.Errorf("child1 merge: %v %v", c1.Mbr2, c1.Prop("p"))
+	}
+	c2 := ours.ChildByName("child2", 0).(*NodeEmbed)
+	if c2.Mbr1 != "t" || c2.Mbr2 != 42 || c2.Prop("p") != 2 {
+		t.Errorf("child2 merge: %v %v %v", c2.Mbr1, c2.Mbr2, c2.Prop("p"))
+	}
+	if ours.Prop("a") != "ours" || ours.Field1.Mbr1 != "ofield" {
+		t.Errorf("root merge: %v %v", ours.Prop("a"), ours.Field1.Mbr1)
+	}
+
+	// theirs for everything reverts ours where it conflicts
+	ours2 := base.Clone().(*NodeField)
+	ours2.MoveChild(3, 0)
+	theirs2 := base.Clone().(*NodeField)
+	theirs2.ReverseChildren()
+	res, conflicts := MergeTrees(base, ours2, theirs2, func(c *MergeConflict) MergeChoices {
+		return MergeTheirs
+	})
+	if len(conflicts) != 1 || conflicts[0].Type != MergeOrderConflict {
+		t.Errorf("expected order conflict: %v", conflicts)
+	}
+	if nms := strings.Join(kidNames(res), ","); nms != "child4,child3,child2,child1" {
+		t.Errorf("order conflict theirs: %v", nms)
+	}
+	if !treeEqual(res, theirs2) {
+		t.Errorf("merged tree should equal theirs")
+	}
+}
+
+func TestMergeDuplicateNames(t *testing.T) {
+	dupTree := func() *NodeField { // note: Clone does not keep duplicate names
+		par := &NodeField{}
+		par.InitName(par, "par1")
+		for i := 0; i < 3; i++ {
+			kid := par.AddNewChild(KiT_NodeEmbed, "item").(*NodeEmbed)
+			kid.Mbr2 = i + 1
+		}
+		return par
+	}
+	base, ours, theirs := dupTree(), dupTree(), dupTree()
+	ours.Child(1).(*NodeEmbed).Mbr2 = 20
+	theirs.Child(2).(*NodeEmbed).Mbr2 = 30
+	ours.Field1.Mbr1 = "ours"
+	theirs.Field1.Mbr1 = "theirs"
+
+	res, conflicts := MergeTrees(base, ours, theirs, nil)
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict: %v", conflicts)
+	}
+	if c := conflicts[0]; c.Path != "/par1.Field1" || c.Key != "Mbr1" {
+		t.Errorf("Ki field conflict path: %v key: %v", c.Path, c.Key)
+	}
+	if res.NumChildren() != 3 {
+		t.Fatalf("duplicate names collapsed: %d children", res.NumChildren())
+	}
+	for i, exp := range []int{1, 20, 30} {
+		if v := res.Child(i).(*NodeEmbed).Mbr2; v != exp || res.Child(i).Name() != "item" {
+			t.Errorf("child %d: %v %v != item %v", i, res.Child(i).Name(), v, exp)
+		}
+	}
+
+	// changes to the same duplicate conflict, with its occurrence in the path
+	theirs.Child(1).(*NodeEmbed).Mbr2 = 21
+	_, conflicts = MergeTrees(base, ours, theirs, nil)
+	if len(conflicts) != 2 || conflicts[1].Path != "/par1/item[1]" {
+		t.Errorf("duplicate child conflict: %v", conflicts)
+	}
+	res.Destroy()
+
+	// a child named like a duplicate occurrence is a different child
+	base, ours, theirs = dupTree(), dupTree(), dupTree()
+	for _, par := range []*NodeField{base, ours, theirs} {
+		par.AddNewChild(KiT_NodeEmbed, "item[1]").(*NodeEmbed).Mbr2 = 4
+	}
+	ours.Child(1).(*NodeEmbed).Mbr2 = 20
+	theirs.Child(3).(*NodeEmbed).Mbr2 = 40
+	res, conflicts = MergeTrees(base, ours, theirs, nil)
+	if len(conflicts) != 0 {
+		t.Errorf("item[1] child should not conflict with duplicate item: %v", conflicts)
+	}
+	if nms := strings.Join(kidNames(res), ","); nms != "item,item,item,item[1]" {
+		t.Fatalf("merged children: %v", nms)
+	}
+	for i, exp := range []int{1, 20, 3, 40} {
+		if v := res.Child(i).(*NodeEmbed).Mbr2; v != exp {
+			t.Errorf("child %d: %v != %v", i, v, exp)
+		}
+	}
+	res.Destroy()
+}
+
+func TestMergeBothDeleted(t *testing.T) {
+	base := mergeTestTree()
+	ours := base.Clone().(*NodeField)
+	theirs := base.Clone().(*NodeField)
+	ours.DeleteChildByName("child2", DestroyKids)
+	theirs.DeleteChildByName("child2", DestroyKids)
+	theirs.Child(0).SetProp("p", 10)
+
+	res, conflicts := MergeTrees(base, ours, theirs, nil)
+	if len(conflicts) != 0 {
+		t.Errorf("deleting the same child on both sides should not conflict: %v", conflicts)
+	}
+	if nms := strings.Join(kidNames(res), ","); nms != "child1,child3,child4" {
+		t.Errorf("merged children: %v", nms)
+	}
+	if p := res.Child(0).Prop("p"); p != 10 {
+		t.Errorf("theirs change not merged: %v", p)
+	}
+}
+
+// kidNames returns the names of the children of given node, nil if nil
+func kidNames(k Ki) []string {
+	if k == nil {
+		return nil
+	}
+	kids := *k.Children()
+	nms := make([]string, len(kids))
+	for i, kid := range kids {
+		nms[i] = kid.Name()
+	}
+	return nms
+}
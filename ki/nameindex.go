@@ -0,0 +1,172 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import "sync"
+
+// NameIndexAbove is the number of children at or above which a Node
+// automatically maintains a map from child names to indexes, used by
+// ChildByName, FindPath and DeleteChildByName, making those lookups O(1)
+// instead of a linear scan.  Set to 0 to disable the index entirely.
+// When the index is used, the first child with a given name is returned,
+// regardless of the startIdx arg (names should be unique in any case).
+var NameIndexAbove = 1000
+
+// nameIndex maps child names to the index of the first child with that
+// name.  It records the Kids slice it was built for, and is rebuilt if
+// the slice has been reallocated or changed length since then.  Moved or
+// swapped children are caught by checking the name of the child at the
+// looked-up index.  Appending a child and SetName update the index in
+// place, and the other Node methods that change the children, as well as
+// Slice Insert and DeleteAtIndex on the Kids, reset it, so a name that is
+// not in a current index is not found, without a linear scan.  Thus,
+// names must be set with SetName, and children must not be replaced by
+// direct assignment of elements in Kids (use SetChild).
+type nameIndex struct {
+	idx map[string]int
+
+	// true if there are duplicate names among the children
+	dup bool
+
+	// first element of Kids when built, to detect reallocation
+	first *Ki
+
+	// length of Kids when built
+	n int
+}
+
+// nameIndexMu protects the nameIdx field of nodes during lookups, which
+// build the index lazily while only holding the tree read lock.  Mutators
+// holding the tree write lock can access the index directly.
+var nameIndexMu sync.Mutex
+
+// current returns true if the index is valid for given slice
+func (ni *nameIndex) current(kids Slice) bool {
+	return ni.n == len(kids) && (ni.n == 0 || ni.first == &kids[0])
+}
+
+// setSlice records given slice as the one the index is valid for
+func (ni *nameIndex) setSlice(kids Slice) {
+	ni.n = len(kids)
+	if ni.n > 0 {
+		ni.first = &kids[0]
+	} else {
+		ni.first = nil
+	}
+}
+
+// buildNameIndex rebuilds the name index from the current Kids
+func (n *Node) buildNameIndex() *nameIndex {
+	ni := n.nameIdx
+	if ni == nil {
+		ni = &nameIndex{idx: make(map[string]int, len(n.Kids))}
+		n.nameIdx = ni
+	} else {
+		for k := range ni.idx {
+			delete(ni.idx, k)
+		}
+		ni.dup = false
+	}
+	for i, kid := range n.Kids {
+		if kid == nil {
+			continue
+		}
+		nm := kid.AsNode().Nm
+		if _, has := ni.idx[nm]; has {
+			ni.dup = true
+		} else {
+			ni.idx[nm] = i
+		}
+	}
+	ni.setSlice(n.Kids)
+	return ni
+}
+
+// childIndexByName returns the index of the child with given name, using
+// the name index if there are at least NameIndexAbove children, otherwise
+// the regular IndexByName search from startIdx.  Must be called with the
+// tree read lock held.
+func (n *Node) childIndexByName(name string, startIdx int) (int, bool) {
+	if NameIndexAbove <= 0 || len(n.Kids) < NameIndexAbove {
+		return n.Kids.IndexByName(name, startIdx)
+	}
+	nameIndexMu.Lock()
+	defer nameIndexMu.Unlock()
+	ni := n.nameIdx
+	if ni == nil || !ni.current(n.Kids) {
+		ni = n.buildNameIndex()
+	}
+	idx, ok := ni.idx[name]
+	if !ok {
+		return -1, false
+	}
+	if kid := n.Kids[idx]; kid != nil && kid.AsNode().Nm == name {
+		return idx, true
+	}
+	ni = n.buildNameIndex() // children were moved or swapped
+	idx, ok = ni.idx[name]
+	return idx, ok
+}
+
+// nameIndexAppend is called after appending kid to the Kids, under the
+// tree write lock, with the result of checking if the index was current
+// prior to the append -- if so, it is updated for the new kid.
+func (n *Node) nameIndexAppend(kid Ki, wascur bool) {
+	if !wascur {
+		return
+	}
+	ni := n.nameIdx
+	if _, has := ni.idx[kid.AsNode().Nm]; has {
+		ni.dup = true
+	} else {
+		ni.idx[kid.AsNode().Nm] = len(n.Kids) - 1
+	}
+	ni.setSlice(n.Kids)
+}
+
+// nameIndexRename is called after renaming kid from old to its current
+// name, under the tree write lock -- if the index is current, and the old
+// name was unique, the entry is updated in place, and otherwise the index
+// is reset, to be rebuilt on the next lookup.
+func (n *Node) nameIndexRename(kid *Node, old string) {
+	ni := n.nameIdx
+	if ni == nil || !ni.current(n.Kids) {
+		return
+	}
+	idx, has := ni.idx[old]
+	if ni.dup || !has || n.Kids[idx] == nil || n.Kids[idx].AsNode() != kid {
+		n.nameIdx = nil
+		return
+	}
+	delete(ni.idx, old)
+	if cur, has := ni.idx[kid.Nm]; has {
+		ni.dup = true
+		if cur < idx {
+			return
+		}
+	}
+	ni.idx[kid.Nm] = idx
+}
+
+// resetNameIndex resets the name index of the node that has this slice as
+// its Kids, if any, found through the parents of the elements -- called
+// by Slice methods that change the children without their node.
+func (sl *Slice) resetNameIndex() {
+	for _, kid := range *sl {
+		if kid == nil {
+			continue
+		}
+		if par := kid.AsNode().Par; par != nil && &par.AsNode().Kids == sl {
+			par.AsNode().nameIdx = nil
+			return
+		}
+	}
+}
+
+// nameIndexCurrent returns true if we have a name index that is
+// current -- must be called under the tree write lock.
+func (n *Node) nameIndexCurrent() bool {
+	return n.nameIdx != nil && n.nameIdx.current(n.Kids)
+}
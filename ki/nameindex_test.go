@@ -0,0 +1,188 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/goki/ki/kit"
+)
+
+func buildWideTree(nkids int) *NodeEmbed {
+	parent := &NodeEmbed{}
+	parent.InitName(parent, "par1")
+	parent.SetNChildren(nkids, KiT_NodeEmbed, "child")
+	return parent
+}
+
+// checkNameIndex checks that every child is found at its index
+func checkNameIndex(t *testing.T, par Ki, where string) {
+	t.Helper()
+	for i, kid := range *par.Children() {
+		if fk := par.ChildByName(kid.Name(), 0); fk != kid {
+			t.Errorf("%v: ChildByName %v at %d returned: %v", where, kid.Name(), i, fk)
+			return
+		}
+	}
+}
+
+func TestNameIndex(t *testing.T) {
+	svabove := NameIndexAbove
+	NameIndexAbove = 10
+	defer func() { NameIndexAbove = svabove }()
+
+	parent := buildWideTree(20)
+	checkNameIndex(t, parent, "initial")
+	if parent.nameIdx == nil {
+		t.Errorf("name index should have been built")
+	}
+	if parent.ChildByName("nonexistent", 0) != nil {
+		t.Errorf("found nonexistent child")
+	}
+
+	parent.AddNewChild(KiT_NodeEmbed, "added")
+	checkNameIndex(t, parent, "AddNewChild")
+	parent.InsertNewChild(KiT_NodeEmbed, 3, "inserted")
+	checkNameIndex(t, parent, "InsertNewChild")
+	parent.Kids.Insert(NewOfType(KiT_NodeEmbed), 5)
+	parent.Kids[5].SetName("sliceins")
+	checkNameIndex(t, parent, "Slice.Insert")
+	parent.Kids.DeleteAtIndex(7)
+	checkNameIndex(t, parent, "Slice.DeleteAtIndex")
+	parent.DeleteChildAtIndex(2, DestroyKids)
+	checkNameIndex(t, parent, "DeleteChildAtIndex")
+	parent.Kids.Move(0, 15)
+	checkNameIndex(t, parent, "Slice.Move")
+	parent.Kids.Swap(1, 12)
+	checkNameIndex(t, parent, "Slice.Swap")
+	parent.Child(4).SetName("renamed")
+	checkNameIndex(t, parent, "SetName")
+	if parent.ChildByName("renamed", 0) != parent.Child(4) {
+		t.Errorf("renamed child not found")
+	}
+	if _, err := parent.DeleteChildByName("renamed", DestroyKids); err != nil {
+		t.Error(err)
+	}
+	checkNameIndex(t, parent, "DeleteChildByName")
+	nk := &NodeEmbed{}
+	parent.SetChild(nk, 6, "setchild")
+	checkNameIndex(t, parent, "SetChild")
+
+	if fk := parent.FindPath("/par1/setchild"); fk != nk {
+		t.Errorf("FindPath did not find setchild: %v", fk)
+	}
+
+	config := kit.TypeAndNameList{}
+	for i := 0; i < 15; i++ {
+		config.Add(KiT_NodeEmbed, fmt.Sprintf("cfg%d", 14-i))
+	}
+	parent.ConfigChildren(config)
+	checkNameIndex(t, parent, "ConfigChildren")
+	if parent.ChildByName("cfg7", 0) != parent.Child(7) {
+		t.Errorf("config child not found")
+	}
+	config = config[5:]
+	config.Add(KiT_NodeEmbed, "cfg_new")
+	parent.ConfigChildren(config)
+	checkNameIndex(t, parent, "ConfigChildren again")
+	if parent.ChildByName("cfg14", 0) != nil {
+		t.Errorf("config deleted child still found")
+	}
+
+	// delete + append keeps the same length and backing array
+	parent.DeleteChildAtIndex(3, DestroyKids)
+	parent.AddNewChild(KiT_NodeEmbed, "appended")
+	checkNameIndex(t, parent, "delete + append")
+	checkNameIndex(t, parent, "before Slice delete + add")
+	sk := NewOfType(KiT_NodeEmbed)
+	sk.InitName(sk, "sliceadd")
+	parent.Kids.DeleteAtIndex(2)
+	parent.Kids.Insert(sk, len(parent.Kids))
+	if parent.ChildByName("sliceadd", 0) != sk {
+		t.Errorf("appended child not found")
+	}
+	checkNameIndex(t, parent, "Slice delete + add")
+
+	// SetName on a child updates the index in place
+	ni := parent.nameIdx
+	kid := parent.Child(5)
+	oldnm := kid.Name()
+	kid.SetName("newname")
+	if parent.nameIdx != ni || !parent.nameIndexCurrent() {
+		t.Errorf("SetName should update the name index in place")
+	}
+	if parent.ChildByName("newname", 0) != kid || parent.ChildByName(oldnm, 0) != nil {
+		t.Errorf("SetName child not found under its new name only")
+	}
+	checkNameIndex(t, parent, "SetName child")
+	parent.Child(7).SetName("newname") // duplicate of an earlier child
+	if parent.ChildByName("newname", 0) != kid {
+		t.Errorf("SetName duplicate should find the first child")
+	}
+
+	// names missing from a current index are not found with a scan
+	parent.Child(6).AsNode().Nm = "direct"
+	if parent.ChildByName("direct", 0) != nil || parent.nameIdx == nil {
+		t.Errorf("lookup of a name missing from the index should use the index")
+	}
+
+	parent.DeleteChildren(DestroyKids)
+	if parent.nameIdx != nil || parent.ChildByName("cfg7", 0) != nil {
+		t.Errorf("name index not cleared")
+	}
+}
+
+func BenchmarkChildByNameScan(b *testing.B) {
+	svabove := NameIndexAbove
+	NameIndexAbove = 0
+	defer func() { NameIndexAbove = svabove }()
+	benchmarkChildByName(b)
+}
+
+func BenchmarkChildByNameIndex(b *testing.B) {
+	benchmarkChildByName(b)
+}
+
+func benchmarkChildByName(b *testing.B) {
+	nkids := 20000
+	parent := buildWideTree(nkids)
+	names := make([]string, 100)
+	for i := range names {
+		names[i] = parent.Child((i * 7919) % nkids).Name()
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if parent.ChildByName(names[n%len(names)], StartMiddle) == nil {
+			b.Fatal("child not found")
+		}
+	}
+}
+
+func BenchmarkFindPathScan(b *testing.B) {
+	svabove := NameIndexAbove
+	NameIndexAbove = 0
+	defer func() { NameIndexAbove = svabove }()
+	benchmarkFindPath(b)
+}
+
+func BenchmarkFindPathIndex(b *testing.B) {
+	benchmarkFindPath(b)
+}
+
+func benchmarkFindPath(b *testing.B) {
+	nkids := 20000
+	parent := buildWideTree(nkids)
+	for i := 0; i < nkids; i += 1000 {
+		parent.Child(i).SetNChildren(nkids/10, KiT_NodeEmbed, "sub")
+	}
+	path := parent.Child(nkids/2).Path() + "/" + parent.Child(nkids/2).Child(nkids/20).Name()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if parent.FindPath(path) == nil {
+			b.Fatal("path not found")
+		}
+	}
+}
@@ -10,11 +10,12 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"sync/atomic"
-	"unsafe"
 
 	"log"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/goki/ki/bitflag"
@@ -35,6 +36,9 @@ type Node struct {
 	// Ki.Name() user-supplied name of this node -- can be empty or non-unique
 	Nm string `copy:"-" label:"Name" desc:"Ki.Name() user-supplied name of this node -- can be empty or non-unique"`
 
+	// [view: -] [tableview: -] optional stable unique ID of this node, assigned when UseNodeIDs is on -- see NodeByID -- 0 if not assigned
+	UID uint64 `tableview:"-" copy:"-" json:",omitempty" xml:",omitempty" view:"-" desc:"optional stable unique ID of this node, assigned when UseNodeIDs is on -- see NodeByID -- 0 if not assigned"`
+
 	// [tableview: -] bit flags for internal node state
 	Flag int64 `tableview:"-" copy:"-" json:"-" xml:"-" max-width:"80" height:"3" desc:"bit flags for internal node state"`
 
@@ -53,14 +57,44 @@ type Node struct {
 	// [view: -] we need a pointer to ourselves as a Ki, which can always be used to extract the true underlying type of object when Node is embedded in other structs -- function receivers do not have this ability so this is necessary.  This is set to nil when deleted.  Typically use This() convenience accessor which protects against concurrent access.
 	Ths Ki `copy:"-" json:"-" xml:"-" view:"-" desc:"we need a pointer to ourselves as a Ki, which can always be used to extract the true underlying type of object when Node is embedded in other structs -- function receivers do not have this ability so this is necessary.  This is set to nil when deleted.  Typically use This() convenience accessor which protects against concurrent access."`
 
-	// [view: -] last value of our index -- used as a starting point for finding us in our parent next time -- is not guaranteed to be accurate!  use IndexInParent() method
-	index int `copy:"-" json:"-" xml:"-" view:"-" desc:"last value of our index -- used as a starting point for finding us in our parent next time -- is not guaranteed to be accurate!  use IndexInParent() method"`
+	// [view: -] last value of our index, accessed atomically -- used as a starting point for finding us in our parent next time -- is not guaranteed to be accurate!  use IndexInParent() method
+	index int64 `copy:"-" json:"-" xml:"-" view:"-" desc:"last value of our index, accessed atomically -- used as a starting point for finding us in our parent next time -- is not guaranteed to be accurate!  use IndexInParent() method"`
+
+	// [view: -] map from child names to indexes, maintained automatically for large numbers of children -- see NameIndexAbove
+	nameIdx *nameIndex `copy:"-" json:"-" xml:"-" view:"-" desc:"map from child names to indexes, maintained automatically for large numbers of children -- see NameIndexAbove"`
+
+	// [view: -] the UID this node is registered under in the node ID registry, if any
+	regID uint64 `copy:"-" json:"-" xml:"-" view:"-" desc:"the UID this node is registered under in the node ID registry, if any"`
+
+	// [view: -] function that lazily loads the children of this node on first access -- see SetChildLoader
+	loader ChildLoader `copy:"-" json:"-" xml:"-" view:"-" desc:"function that lazily loads the children of this node on first access -- see SetChildLoader"`
+
+	// [view: -] mutex held while the children are being loaded, so other callers wait for the load to finish
+	loadMu sync.Mutex `copy:"-" json:"-" xml:"-" view:"-" desc:"mutex held while the children are being loaded, so other callers wait for the load to finish"`
 
 	// [view: -] optional depth parameter of this node -- only valid during specific contexts, not generally -- e.g., used in FuncDownBreadthFirst function
 	depth int `copy:"-" json:"-" xml:"-" view:"-" desc:"optional depth parameter of this node -- only valid during specific contexts, not generally -- e.g., used in FuncDownBreadthFirst function"`
 
 	// [view: -] cached version of the field offsets relative to base Node address -- used in generic field access.
 	fieldOffs []uintptr `copy:"-" json:"-" xml:"-" view:"-" desc:"cached version of the field offsets relative to base Node address -- used in generic field access."`
+
+	// [view: -] mutex shared by all nodes in the tree when tree locking mode is on (nil otherwise) -- see SetTreeLocking
+	treeMu *sync.RWMutex `copy:"-" json:"-" xml:"-" view:"-" desc:"mutex shared by all nodes in the tree when tree locking mode is on (nil otherwise) -- see SetTreeLocking"`
+
+	// [view: -] deletion manager of the tree, only on the root node, made on demand -- see DeleteManager
+	delMgr *Deleted `copy:"-" json:"-" xml:"-" view:"-" desc:"deletion manager of the tree, only on the root node, made on demand -- see DeleteManager"`
+
+	// [view: -] cache of PropInherit results -- see CachePropInherit
+	propCache propCache `copy:"-" json:"-" xml:"-" view:"-" desc:"cache of PropInherit results -- see CachePropInherit"`
+
+	// [view: -] propsClock value at the last change to the properties or parent of this node -- see CachePropInherit
+	propsStamp int64 `copy:"-" json:"-" xml:"-" view:"-" desc:"propsClock value at the last change to the properties or parent of this node -- see CachePropInherit"`
+
+	// [view: -] keys of the properties updated since the last UpdateStart -- see UpdatedProps
+	updtProps []string `copy:"-" json:"-" xml:"-" view:"-" desc:"keys of the properties updated since the last UpdateStart -- see UpdatedProps"`
+
+	// [view: -] watchers of changes to fields of this node -- see WatchField
+	fieldWatchers []*fieldWatcher `copy:"-" json:"-" xml:"-" view:"-" desc:"watchers of changes to fields of this node -- see WatchField"`
 }
 
 // must register all new types so type names can be looked up by name -- also props
@@ -149,7 +183,10 @@ func (n *Node) BaseIface() reflect.Type {
 // Name returns the user-defined name of the object (Node.Nm),
 // for finding elements, generating paths, IO, etc.
 func (n *Node) Name() string {
-	return n.Nm
+	mu := n.rlockTree()
+	nm := n.Nm
+	runlockTree(mu)
+	return nm
 }
 
 // SetName sets the name of this node.
@@ -158,7 +195,14 @@ func (n *Node) Name() string {
 // If node requires non-unique names, add a separate Label field.
 // Does NOT wrap in UpdateStart / End.
 func (n *Node) SetName(name string) {
+	n.snapshotSave()
+	mu := n.lockTree()
+	old := n.Nm
 	n.Nm = name
+	if n.Par != nil {
+		n.Par.AsNode().nameIndexRename(n, old)
+	}
+	unlockTree(mu)
 }
 
 //////////////////////////////////////////////////////////////////////////
@@ -167,19 +211,24 @@ func (n *Node) SetName(name string) {
 // Parent returns the parent of this Ki (Node.Par) -- Ki has strict
 // one-parent, no-cycles structure -- see SetParent.
 func (n *Node) Parent() Ki {
-	return n.Par
+	mu := n.rlockTree()
+	par := n.Par
+	runlockTree(mu)
+	return par
 }
 
 // IndexInParent returns our index within our parent object -- caches the
 // last value and uses that for an optimized search so subsequent calls
 // are typically quite fast.  Returns false if we don't have a parent.
 func (n *Node) IndexInParent() (int, bool) {
+	mu := n.rlockTree()
+	defer runlockTree(mu)
 	if n.Par == nil {
 		return -1, false
 	}
-	idx, ok := n.Par.Children().IndexOf(n.This(), n.index) // very fast if index is close..
+	idx, ok := n.Par.AsNode().Kids.IndexOf(n.This(), int(atomic.LoadInt64(&n.index))) // very fast if index is close..
 	if ok {
-		n.index = idx
+		atomic.StoreInt64(&n.index, int64(idx)) // atomic, as other readers may hold the read lock
 	}
 	return idx, ok
 }
@@ -256,12 +305,16 @@ func (n *Node) ParentByTypeTry(t reflect.Type, embeds bool) (Ki, error) {
 
 // HasChildren tests whether this node has children (i.e., non-terminal).
 func (n *Node) HasChildren() bool {
-	return len(n.Kids) > 0
+	return n.NumChildren() > 0
 }
 
 // NumChildren returns the number of children of this node.
 func (n *Node) NumChildren() int {
-	return len(n.Kids)
+	n.loadChildren()
+	mu := n.rlockTree()
+	nk := len(n.Kids)
+	runlockTree(mu)
+	return nk
 }
 
 // Children returns a pointer to the slice of children (Node.Kids) -- use
@@ -269,13 +322,17 @@ func (n *Node) NumChildren() int {
 // Slice can be modified directly (e.g., sort, reorder) but Add* / Delete*
 // methods on parent node should be used to ensure proper tracking.
 func (n *Node) Children() *Slice {
-	return &n.Kids
+	n.loadChildren()
+	mu := n.rlockTree()
+	kids := &n.Kids
+	runlockTree(mu)
+	return kids
 }
 
 // IsValidIndex returns error if given index is not valid for accessing children
 // nil otherwise.
 func (n *Node) IsValidIndex(idx int) error {
-	sz := len(n.Kids)
+	sz := n.NumChildren()
 	if idx >= 0 && idx < sz {
 		return nil
 	}
@@ -285,14 +342,20 @@ func (n *Node) IsValidIndex(idx int) error {
 // Child returns the child at given index -- will panic if index is invalid.
 // See methods on ki.Slice for more ways to access.
 func (n *Node) Child(idx int) Ki {
+	n.loadChildren()
+	mu := n.rlockTree()
+	defer runlockTree(mu)
 	return n.Kids[idx]
 }
 
 // ChildTry returns the child at given index.  Try version returns error if index is invalid.
 // See methods on ki.Slice for more ways to acces.
 func (n *Node) ChildTry(idx int) (Ki, error) {
-	if err := n.IsValidIndex(idx); err != nil {
-		return nil, err
+	n.loadChildren()
+	mu := n.rlockTree()
+	defer runlockTree(mu)
+	if idx < 0 || idx >= len(n.Kids) {
+		return nil, fmt.Errorf("ki %v: invalid index: %v -- len = %v", n.Nm, idx, len(n.Kids))
 	}
 	return n.Kids[idx], nil
 }
@@ -302,7 +365,14 @@ func (n *Node) ChildTry(idx int) (Ki, error) {
 // an idea where it might be -- can be key speedup for large lists -- pass
 // [ki.StartMiddle] to start in the middle (good default).
 func (n *Node) ChildByName(name string, startIdx int) Ki {
-	return n.Kids.ElemByName(name, startIdx)
+	n.loadChildren()
+	mu := n.rlockTree()
+	defer runlockTree(mu)
+	idx, ok := n.childIndexByName(name, startIdx)
+	if !ok {
+		return nil
+	}
+	return n.Kids[idx]
 }
 
 // ChildByNameTry returns first element that has given name, error if not found.
@@ -310,7 +380,10 @@ func (n *Node) ChildByName(name string, startIdx int) Ki {
 // an idea where it might be -- can be key speedup for large lists -- pass
 // [ki.StartMiddle] to start in the middle (good default).
 func (n *Node) ChildByNameTry(name string, startIdx int) (Ki, error) {
-	idx, ok := n.Kids.IndexByName(name, startIdx)
+	n.loadChildren()
+	mu := n.rlockTree()
+	defer runlockTree(mu)
+	idx, ok := n.childIndexByName(name, startIdx)
 	if !ok {
 		return nil, fmt.Errorf("ki %v: child named: %v not found", n.Nm, name)
 	}
@@ -324,6 +397,9 @@ func (n *Node) ChildByNameTry(name string, startIdx int) (Ki, error) {
 // an idea where it might be -- can be key speedup for large lists -- pass
 // [ki.StartMiddle] to start in the middle (good default).
 func (n *Node) ChildByType(t reflect.Type, embeds bool, startIdx int) Ki {
+	n.loadChildren()
+	mu := n.rlockTree()
+	defer runlockTree(mu)
 	return n.Kids.ElemByType(t, embeds, startIdx)
 }
 
@@ -335,6 +411,9 @@ func (n *Node) ChildByType(t reflect.Type, embeds bool, startIdx int) Ki {
 // an idea where it might be -- can be key speedup for large lists -- pass
 // [ki.StartMiddle] to start in the middle (good default).
 func (n *Node) ChildByTypeTry(t reflect.Type, embeds bool, startIdx int) (Ki, error) {
+	n.loadChildren()
+	mu := n.rlockTree()
+	defer runlockTree(mu)
 	idx, ok := n.Kids.IndexByType(t, embeds, startIdx)
 	if !ok {
 		return nil, fmt.Errorf("ki %v: child of type: %t not found", n.Nm, t)
@@ -362,13 +441,16 @@ func UnescapePathName(name string) string {
 // Node names escape any existing / and . characters to \\ and \,
 // Path is only valid when child names are unique (see Unique* functions)
 func (n *Node) Path() string {
-	if n.Par != nil {
+	mu := n.rlockTree()
+	par, nm := n.Par, n.Nm
+	runlockTree(mu)
+	if par != nil {
 		if n.IsField() {
-			return n.Par.Path() + "." + EscapePathName(n.Nm)
+			return par.Path() + "." + EscapePathName(nm)
 		}
-		return n.Par.Path() + "/" + EscapePathName(n.Nm)
+		return par.Path() + "/" + EscapePathName(nm)
 	}
-	return "/" + EscapePathName(n.Nm)
+	return "/" + EscapePathName(nm)
 }
 
 // PathFrom returns path to this node from given parent node, using
@@ -377,37 +459,48 @@ func (n *Node) Path() string {
 // Path is only valid for finding items when child names are unique
 // (see Unique* functions)
 func (n *Node) PathFrom(par Ki) string {
-	if n.Par != nil {
+	mu := n.rlockTree()
+	mypar, nm := n.Par, n.Nm
+	runlockTree(mu)
+	if mypar != nil {
 		ppath := ""
-		if n.Par == par {
+		if mypar == par {
 			ppath = "/" + EscapePathName(par.Name())
 		} else {
-			ppath = n.Par.PathFrom(par)
+			ppath = mypar.PathFrom(par)
 		}
 		if n.IsField() {
-			return ppath + "." + EscapePathName(n.Nm)
+			return ppath + "." + EscapePathName(nm)
 		}
-		return ppath + "/" + EscapePathName(n.Nm)
+		return ppath + "/" + EscapePathName(nm)
 	}
-	return "/" + n.Nm
+	return "/" + nm
 }
 
-// find the child on the path
-func findPathChild(k Ki, child string) (int, bool) {
+// find the child on the path, returning nil if not found
+func findPathChild(k Ki, child string) Ki {
+	n := k.AsNode()
+	n.loadChildren()
+	mu := n.rlockTree()
+	defer runlockTree(mu)
 	if child[0] == '[' && child[len(child)-1] == ']' {
 		idx, err := strconv.Atoi(child[1 : len(child)-1])
 		if err != nil {
-			return idx, false
+			return nil
 		}
 		if idx < 0 { // from end
-			idx = len(*k.Children()) + idx
+			idx = len(n.Kids) + idx
 		}
-		if k.Children().IsValidIndex(idx) != nil {
-			return idx, false
+		if n.Kids.IsValidIndex(idx) != nil {
+			return nil
 		}
-		return idx, true
+		return n.Kids[idx]
+	}
+	idx, ok := n.childIndexByName(child, 0)
+	if !ok {
+		return nil
 	}
-	return k.Children().IndexByName(child, 0)
+	return n.Kids[idx]
 }
 
 // FindPath returns Ki object at given path, starting from this node
@@ -420,7 +513,7 @@ func findPathChild(k Ki, child string) (int, bool) {
 // element, for cases when indexes are more useful than names.
 // Returns nil if not found.
 func (n *Node) FindPath(path string) Ki {
-	if n.Par != nil { // we are not root..
+	if n.Parent() != nil { // we are not root..
 		myp := n.Path()
 		path = strings.TrimPrefix(path, myp)
 	}
@@ -436,11 +529,10 @@ func (n *Node) FindPath(path string) Ki {
 		if strings.Contains(pe, ".") { // has fields
 			fels := strings.Split(pe, ".")
 			// find the child first, then the fields
-			idx, ok := findPathChild(curn, UnescapePathName(fels[0]))
-			if !ok {
+			curn = findPathChild(curn, UnescapePathName(fels[0]))
+			if curn == nil {
 				return nil
 			}
-			curn = (*(curn.Children()))[idx]
 			for i := 1; i < len(fels); i++ {
 				fe := UnescapePathName(fels[i])
 				fk := KiFieldByName(curn.AsNode(), fe)
@@ -450,11 +542,10 @@ func (n *Node) FindPath(path string) Ki {
 				curn = fk
 			}
 		} else {
-			idx, ok := findPathChild(curn, UnescapePathName(pe))
-			if !ok {
+			curn = findPathChild(curn, UnescapePathName(pe))
+			if curn == nil {
 				return nil
 			}
-			curn = (*(curn.Children()))[idx]
 		}
 	}
 	return curn
@@ -490,7 +581,7 @@ func (n *Node) AddChild(kid Ki) error {
 		return err
 	}
 	InitNode(kid)
-	n.Kids = append(n.Kids, kid)
+	n.appendKid(kid)
 	SetParent(kid, n.This()) // key to set new parent before deleting: indicates move instead of delete
 	return nil
 }
@@ -506,8 +597,8 @@ func (n *Node) AddNewChild(typ reflect.Type, name string) Ki {
 	}
 	kid := NewOfType(typ)
 	InitNode(kid)
-	n.Kids = append(n.Kids, kid)
 	kid.SetName(name)
+	n.appendKid(kid)
 	SetParent(kid, n.This())
 	return kid
 }
@@ -519,15 +610,27 @@ func (n *Node) AddNewChild(typ reflect.Type, name string) Ki {
 // No UpdateStart / End wrapping is done: do that externally as needed.
 // Can also call SetChildAdded() if notification is needed.
 func (n *Node) SetChild(kid Ki, idx int, name string) error {
-	if err := n.Kids.IsValidIndex(idx); err != nil {
+	if err := n.IsValidIndex(idx); err != nil {
 		return err
 	}
+	n.snapshotSave()
+	if old, err := n.ChildTry(idx); err == nil && old != nil {
+		snapshotSaveTree(old)
+	}
 	if name != "" {
 		kid.InitName(kid, name)
 	} else {
 		InitNode(kid)
 	}
+	mu := n.lockTree()
+	if idx >= len(n.Kids) { // shrunk in the meantime
+		unlockTree(mu)
+		return n.IsValidIndex(idx)
+	}
+	setTreeMu(kid.AsNode(), n.treeMu)
 	n.Kids[idx] = kid
+	n.nameIdx = nil
+	unlockTree(mu)
 	SetParent(kid, n.This())
 	return nil
 }
@@ -542,7 +645,7 @@ func (n *Node) InsertChild(kid Ki, at int) error {
 		return err
 	}
 	InitNode(kid)
-	n.Kids.Insert(kid, at)
+	n.insertKid(kid, at)
 	SetParent(kid, n.This())
 	return nil
 }
@@ -558,8 +661,8 @@ func (n *Node) InsertNewChild(typ reflect.Type, at int, name string) Ki {
 	}
 	kid := NewOfType(typ)
 	InitNode(kid)
-	n.Kids.Insert(kid, at)
 	kid.SetName(name)
+	n.insertKid(kid, at)
 	SetParent(kid, n.This())
 	return kid
 }
@@ -579,7 +682,7 @@ func (n *Node) InsertNewChild(typ reflect.Type, at int, name string) Ki {
 // consistently to manage children all of the same type.
 func (n *Node) SetNChildren(trgn int, typ reflect.Type, nameStub string) (mods, updt bool) {
 	mods, updt = false, false
-	sz := len(n.Kids)
+	sz := n.NumChildren()
 	if trgn == sz {
 		return
 	}
@@ -619,6 +722,171 @@ func (n *Node) ConfigChildren(config kit.TypeAndNameList) (mods, updt bool) {
 	return n.Kids.Config(n.This(), config)
 }
 
+//////////////////////////////////////////////////////////////////////////
+//  Moving Children
+
+// SortChildren sorts the children using given less function, with a
+// stable sort so that equal children retain their relative order.
+// If the order changes, sends NodeSignalChildrenMoved with the
+// permutation, and sets the ChildMoved flag within UpdateStart / End.
+func (n *Node) SortChildren(less func(a, b Ki) bool) {
+	kids := n.kidsCopy()
+	perm := make([]int, len(kids))
+	for i := range perm {
+		perm[i] = i
+	}
+	sort.SliceStable(perm, func(i, j int) bool {
+		return less(kids[perm[i]], kids[perm[j]])
+	})
+	n.permuteChildren(kids, perm)
+}
+
+// SortChildrenBy sorts the children by the value of given field (which
+// can be in an embedded struct) or, for children without that field, the
+// property with given key name.  Values that can be converted to numbers
+// are compared numerically, and otherwise as strings, with missing values
+// ordered before all others (when ascending).  See SortChildren for update
+// signaling.
+func (n *Node) SortChildrenBy(key string, ascending bool) {
+	kids := n.kidsCopy()
+	vals := make([]any, len(kids))
+	for i, kid := range kids {
+		vals[i] = sortKeyValue(kid, key)
+	}
+	perm := make([]int, len(kids))
+	for i := range perm {
+		perm[i] = i
+	}
+	sort.SliceStable(perm, func(i, j int) bool {
+		cmp := compareSortValues(vals[perm[i]], vals[perm[j]])
+		if ascending {
+			return cmp < 0
+		}
+		return cmp > 0
+	})
+	n.permuteChildren(kids, perm)
+}
+
+// MoveChild moves the child at index from to index to, shifting the
+// children in between, returning error for invalid indexes.
+// See SortChildren for update signaling.
+func (n *Node) MoveChild(from, to int) error {
+	kids := n.kidsCopy()
+	if err := kids.IsValidIndex(from); err != nil {
+		return err
+	}
+	if err := kids.IsValidIndex(to); err != nil {
+		return err
+	}
+	perm := make([]int, 0, len(kids))
+	for i := range kids {
+		if i != from {
+			perm = append(perm, i)
+		}
+	}
+	perm = append(perm[:to], append([]int{from}, perm[to:]...)...)
+	n.permuteChildren(kids, perm)
+	return nil
+}
+
+// ReverseChildren reverses the order of the children.
+// See SortChildren for update signaling.
+func (n *Node) ReverseChildren() {
+	kids := n.kidsCopy()
+	sz := len(kids)
+	perm := make([]int, sz)
+	for i := range perm {
+		perm[i] = sz - 1 - i
+	}
+	n.permuteChildren(kids, perm)
+}
+
+// kidsCopy returns a copy of the children, loading them if needed,
+// for computing a permutation outside of the tree lock.
+func (n *Node) kidsCopy() Slice {
+	n.loadChildren()
+	mu := n.rlockTree()
+	defer runlockTree(mu)
+	return append(Slice(nil), n.Kids...)
+}
+
+// permuteChildren reorders the children according to given permutation,
+// where perm[i] is the index in kids of the child to put at index i.
+// Only the children that change position are moved, and nothing is done
+// if the permutation is the identity, or if the children were changed
+// by another goroutine since kids was copied.
+func (n *Node) permuteChildren(kids Slice, perm []int) {
+	ident := true
+	for i, p := range perm {
+		if p != i {
+			ident = false
+			break
+		}
+	}
+	if ident {
+		return
+	}
+	updt := n.UpdateStart()
+	mu := n.lockTree()
+	same := len(n.Kids) == len(kids)
+	for i := 0; same && i < len(kids); i++ {
+		same = n.Kids[i] == kids[i]
+	}
+	if same {
+		for i, p := range perm {
+			if p != i {
+				n.Kids[i] = kids[p]
+			}
+		}
+		n.nameIdx = nil
+	}
+	unlockTree(mu)
+	if !same {
+		log.Printf("ki.Node %v: children changed while being reordered -- not moved\n", n.Nm)
+		n.UpdateEnd(updt)
+		return
+	}
+	n.SetFlag(int(ChildMoved))
+	n.NodeSig.Emit(n.This(), int64(NodeSignalChildrenMoved), perm)
+	n.UpdateEnd(updt)
+}
+
+// sortKeyValue returns the value of given field or property key on
+// given node for SortChildrenBy, nil if it has neither.
+func sortKeyValue(k Ki, key string) any {
+	fv := kit.FlatFieldValueByName(k, key)
+	if fv.IsValid() && fv.CanInterface() {
+		return fv.Interface()
+	}
+	return k.Prop(key)
+}
+
+// compareSortValues compares two values for SortChildrenBy, returning
+// -1, 0, or 1, numerically if both can be converted to numbers, and
+// otherwise as strings, with nil values before all others.
+func compareSortValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	if af, ok := kit.ToFloat(a); ok {
+		if bf, ok := kit.ToFloat(b); ok {
+			switch {
+			case af < bf:
+				return -1
+			case af > bf:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(kit.ToString(a), kit.ToString(b))
+}
+
 //////////////////////////////////////////////////////////////////////////
 //  Deleting Children
 
@@ -630,6 +898,7 @@ func (n *Node) DeleteChildAtIndex(idx int, destroy bool) error {
 	if err != nil {
 		return err
 	}
+	snapshotSaveTree(child)
 	updt := n.UpdateStart()
 	n.SetFlag(int(ChildDeleted))
 	if child.Parent() == n.This() {
@@ -642,9 +911,15 @@ func (n *Node) DeleteChildAtIndex(idx int, destroy bool) error {
 		child.NodeSignal().Emit(child, int64(NodeSignalDeleting), nil)
 		SetParent(child, nil)
 	}
+	mu := n.lockTree()
+	if idx >= len(n.Kids) || n.Kids[idx] != child { // moved in the meantime
+		idx, _ = n.Kids.IndexOf(child, idx)
+	}
 	n.Kids.DeleteAtIndex(idx)
+	n.nameIdx = nil
+	unlockTree(mu)
 	if destroy {
-		DelMgr.Add(child)
+		treeDelMgr(n, true).Add(child)
 	}
 	UpdateReset(child) // it won't get the UpdateEnd from us anymore -- init fresh in any case
 	n.UpdateEnd(updt)
@@ -658,7 +933,9 @@ func (n *Node) DeleteChild(child Ki, destroy bool) error {
 	if child == nil {
 		return errors.New("ki DeleteChild: child is nil")
 	}
+	mu := n.rlockTree()
 	idx, ok := n.Kids.IndexOf(child, 0)
+	runlockTree(mu)
 	if !ok {
 		return fmt.Errorf("ki %v: child: %v not found", n.Nm, child.Path())
 	}
@@ -669,11 +946,17 @@ func (n *Node) DeleteChild(child Ki, destroy bool) error {
 // if not found.
 // Wraps delete in UpdateStart / End and sets ChildDeleted flag.
 func (n *Node) DeleteChildByName(name string, destroy bool) (Ki, error) {
-	idx, ok := n.Kids.IndexByName(name, 0)
+	n.loadChildren()
+	mu := n.rlockTree()
+	idx, ok := n.childIndexByName(name, 0)
+	var child Ki
+	if ok {
+		child = n.Kids[idx]
+	}
+	runlockTree(mu)
 	if !ok {
 		return nil, fmt.Errorf("ki %v: child named: %v not found", n.Nm, name)
 	}
-	child := n.Kids[idx]
 	return child, n.DeleteChildAtIndex(idx, destroy)
 }
 
@@ -682,10 +965,22 @@ func (n *Node) DeleteChildByName(name string, destroy bool) (Ki, error) {
 // remain intact but parent is nil -- could be inserted elsewhere, but you
 // better have kept a slice of them before calling this.
 func (n *Node) DeleteChildren(destroy bool) {
+	if atomic.LoadInt32(&numSnapshots) > 0 {
+		for _, kid := range kidsSnapshot(n) {
+			snapshotSaveTree(kid)
+		}
+	}
 	updt := n.UpdateStart()
 	n.SetFlag(int(ChildrenDeleted))
+	mu := n.lockTree()
 	kids := n.Kids
-	n.Kids = n.Kids[:0] // preserves capacity of list
+	n.nameIdx = nil
+	if mu != nil { // others could append into the shared capacity once unlocked
+		n.Kids = nil
+	} else {
+		n.Kids = n.Kids[:0] // preserves capacity of list
+	}
+	unlockTree(mu)
 	for _, child := range kids {
 		if child == nil {
 			continue
@@ -696,7 +991,7 @@ func (n *Node) DeleteChildren(destroy bool) {
 		UpdateReset(child)
 	}
 	if destroy {
-		DelMgr.Add(kids...)
+		treeDelMgr(n, true).Add(kids...)
 	}
 	n.UpdateEnd(updt)
 }
@@ -705,30 +1000,51 @@ func (n *Node) DeleteChildren(destroy bool) {
 // add removed child to deleted list, to be destroyed later -- otherwise
 // child remains intact but parent is nil -- could be inserted elsewhere.
 func (n *Node) Delete(destroy bool) {
-	if n.Par == nil {
+	par := n.Parent()
+	if par == nil {
 		if destroy {
 			n.This().Destroy()
 		}
 	} else {
-		n.Par.DeleteChild(n.This(), destroy)
+		par.DeleteChild(n.This(), destroy)
 	}
 }
 
 // Destroy calls DisconnectAll to cut all pointers and signal connections,
 // and remove all children and their childrens-children, etc.
+// Any nodes pending destruction in the deletion manager of this node, if
+// it is a root, are destroyed as well.
 func (n *Node) Destroy() {
 	// fmt.Printf("Destroying: %v %T %p Kids: %v\n", n.Nm, n.This(), n.This(), len(n.Kids))
 	if n.This() == nil { // already dead!
 		return
 	}
+	releaseSnapshots(n)
 	n.DisconnectAll()
-	n.DeleteChildren(true) // first delete all my children
+	mu := n.rlockTree()
+	kids := make(Slice, len(n.Kids))
+	copy(kids, n.Kids)
+	runlockTree(mu)
+	n.DeleteChildren(false) // first delete all my children
+	for _, kid := range kids {
+		if kid != nil {
+			kid.Destroy() // then destroy all those kids
+		}
+	}
 	// and destroy all my fields
 	n.FuncFields(0, nil, func(k Ki, level int, d any) bool {
 		k.Destroy()
 		return true
 	})
-	DelMgr.DestroyDeleted() // then destroy all those kids
+	delMgrMu.Lock()
+	dm := n.delMgr
+	n.delMgr = nil
+	delMgrMu.Unlock()
+	if dm != nil {
+		dm.DestroyDeleted()
+	}
+	releaseNodeID(n)
+	UnwatchFields(n)
 	n.SetFlag(int(NodeDestroyed))
 	n.Ths = nil // last gasp: lose our own sense of self..
 	// note: above is thread-safe because This() accessor checks Destroyed
@@ -854,11 +1170,119 @@ func (n *Node) Properties() *Props {
 
 // SetProp sets given property key to value val.
 // initializes property map if nil.
+// If StrictProps is on, the property is validated against the declared
+// properties of the node type (see DeclareProps) and not set if invalid.
+// Sets the PropUpdated and ValUpdated flags, recording the key for
+// UpdatedProps, and sends the NodeSignalUpdated and NodeSignalPropsUpdated
+// signals, unless the node is updating, in which case they are sent at the
+// end of the update -- use SetPropNoUpdate for bulk initialization without
+// update signals.
 func (n *Node) SetProp(key string, val any) {
+	val, ok := strictProp(n.This(), key, val)
+	if !ok {
+		return
+	}
+	n.setProp(key, val)
+	n.propsUpdated(key)
+}
+
+// SetPropNoUpdate sets given property key to value val, like SetProp but
+// without any update signaling or flags, e.g., for bulk initialization of
+// new nodes.
+func (n *Node) SetPropNoUpdate(key string, val any) {
+	val, ok := strictProp(n.This(), key, val)
+	if !ok {
+		return
+	}
+	n.setProp(key, val)
+}
+
+// setProp sets given property, which has been validated
+func (n *Node) setProp(key string, val any) {
+	n.snapshotSave()
+	mu := n.lockTree()
 	if n.Props == nil {
 		n.Props = make(Props)
 	}
 	n.Props[key] = val
+	unlockTree(mu)
+	n.propsChanged()
+}
+
+// setProps sets given properties, which have been validated
+func (n *Node) setProps(props Props) {
+	n.snapshotSave()
+	mu := n.lockTree()
+	if n.Props == nil {
+		n.Props = make(Props, len(props))
+	}
+	for key, val := range props {
+		n.Props[key] = val
+	}
+	unlockTree(mu)
+	n.propsChanged()
+}
+
+// SetPropUpdated sets the PropUpdated and ValUpdated flags, and records
+// given property keys as updated, to be returned by UpdatedProps until the
+// next UpdateStart -- this is done by SetProp, SetProps and DeleteProp,
+// and should be called after setting Props directly.
+func (n *Node) SetPropUpdated(keys ...string) {
+	n.SetFlag(int(PropUpdated), int(ValUpdated))
+	mu := n.lockTree()
+	defer unlockTree(mu)
+	for _, key := range keys {
+		has := false
+		for _, uk := range n.updtProps {
+			if uk == key {
+				has = true
+				break
+			}
+		}
+		if !has {
+			n.updtProps = append(n.updtProps, key)
+		}
+	}
+}
+
+// propsUpdated records given property keys as updated and sends the
+// NodeSignalUpdated and NodeSignalPropsUpdated signals, or, if the node is
+// updating, leaves that to UpdateEnd.  This is much cheaper than wrapping
+// the change in UpdateStart / End, which walks the entire subtree.
+func (n *Node) propsUpdated(keys ...string) {
+	if n.IsUpdating() {
+		n.SetPropUpdated(keys...)
+		return
+	}
+	if n.IsDestroyed() {
+		return
+	}
+	n.ClearFlagMask(int64(UpdateFlagsMask))
+	n.clearUpdatedProps()
+	n.SetPropUpdated(keys...)
+	n.NodeSignal().Emit(n.This(), int64(NodeSignalUpdated), n.Flags())
+	n.NodeSignal().Emit(n.This(), int64(NodeSignalPropsUpdated), keys)
+}
+
+// emitPropsUpdated sends the NodeSignalPropsUpdated signal at the end of
+// an update if any properties were updated during it
+func (n *Node) emitPropsUpdated() {
+	if keys := n.UpdatedProps(); len(keys) > 0 {
+		n.NodeSignal().Emit(n.This(), int64(NodeSignalPropsUpdated), keys)
+	}
+}
+
+// UpdatedProps returns the keys of the properties that were set or
+// deleted since the last UpdateStart, in the order they were first
+// changed, when the PropUpdated flag is set -- e.g., for receivers of the
+// NodeSignalUpdated signal.
+func (n *Node) UpdatedProps() []string {
+	mu := n.rlockTree()
+	defer runlockTree(mu)
+	if len(n.updtProps) == 0 {
+		return nil
+	}
+	return append([]string(nil), n.updtProps...)
 }
 
 // SetPropStr sets given property key to value val as a string (e.g., for python wrapper)
@@ -885,14 +1309,49 @@ func (n *Node) SetSubProps(key string, val Props) {
 	n.SetProp(key, val)
 }
 
-// SetProps sets a whole set of properties
+// SetProps sets a whole set of properties.
+// If StrictProps is on, each property is validated as in SetProp.
+// Sets the PropUpdated and ValUpdated flags and sends signals as in
+// SetProp, with the keys in sorted order -- use SetPropsNoUpdate for bulk
+// initialization without update signals.
 func (n *Node) SetProps(props Props) {
-	if n.Props == nil {
-		n.Props = make(Props, len(props))
+	props = n.strictProps(props)
+	if len(props) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
+	n.setProps(props)
+	n.propsUpdated(keys...)
+}
+
+// SetPropsNoUpdate sets a whole set of properties, like SetProps but
+// without any update signaling or flags, e.g., for bulk initialization of
+// new nodes.
+func (n *Node) SetPropsNoUpdate(props Props) {
+	props = n.strictProps(props)
+	if len(props) == 0 {
+		return
+	}
+	n.setProps(props)
+}
+
+// strictProps returns the valid properties of given ones if StrictProps
+// is on, converted as needed
+func (n *Node) strictProps(props Props) Props {
+	if !StrictProps {
+		return props
+	}
+	valid := make(Props, len(props))
 	for key, val := range props {
-		n.Props[key] = val
+		if cv, ok := strictProp(n.This(), key, val); ok {
+			valid[key] = cv
+		}
 	}
+	return valid
 }
 
 // Prop returns property value for key that is known to exist.
@@ -900,13 +1359,17 @@ func (n *Node) SetProps(props Props) {
 // direct conversion of return.  See PropTry for version with
 // error message if uncertain if property exists.
 func (n *Node) Prop(key string) any {
+	mu := n.rlockTree()
+	defer runlockTree(mu)
 	return n.Props[key]
 }
 
 // PropTry returns property value for key.  Returns error message
 // if property with that key does not exist.
 func (n *Node) PropTry(key string) (any, error) {
+	mu := n.rlockTree()
 	v, ok := n.Props[key]
+	runlockTree(mu)
 	if !ok {
 		return v, fmt.Errorf("ki.PropTry, could not find property with key %v on node %v", key, n.Nm)
 	}
@@ -917,31 +1380,48 @@ func (n *Node) PropTry(key string) (any, error) {
 // property from parents and / or type-level properties.  If inherit, then
 // checks all parents.  If typ then checks property on type as well
 // (registered via KiT type registry).  Returns false if not set anywhere.
+// For properties declared for the node type (see DeclareProps), parents
+// are only checked if the property is declared as inherited, and if typ
+// the declared default is returned if not set anywhere.
+// Results are cached on the node if CachePropInherit is on.
 func (n *Node) PropInherit(key string, inherit, typ bool) (any, bool) {
 	// pr := prof.Start("PropInherit")
 	// defer pr.End()
-	v, ok := n.Props[key]
-	if ok {
-		return v, ok
+	if CachePropInherit {
+		return n.propInheritCached(key, inherit, typ)
 	}
-	if inherit && n.Par != nil {
-		v, ok = n.Par.PropInherit(key, inherit, typ)
-		if ok {
-			return v, ok
-		}
+	return n.propInherit(key, inherit, typ)
+}
+
+// propInherit is the uncached version of PropInherit
+func (n *Node) propInherit(key string, inherit, typ bool) (any, bool) {
+	ps := PropSpecFor(n.This(), key)
+	if ps != nil && !ps.Inherit {
+		inherit = false
 	}
-	if typ {
-		return kit.Types.Prop(Type(n.This()), key)
+	v, ok := n.propLookup(key, inherit, typ)
+	if !ok && typ && ps != nil && ps.Default != nil {
+		return ps.Default, true
 	}
-	return nil, false
+	return v, ok
 }
 
 // DeleteProp deletes property key on this node.
+// If it was set, sets the PropUpdated and ValUpdated flags and sends
+// signals as in SetProp, recording the key for UpdatedProps.
 func (n *Node) DeleteProp(key string) {
-	if n.Props == nil {
+	mu := n.rlockTree()
+	_, has := n.Props[key]
+	runlockTree(mu)
+	if !has {
 		return
 	}
+	n.snapshotSave()
+	mu = n.lockTree()
 	delete(n.Props, key)
+	unlockTree(mu)
+	n.propsChanged()
+	n.propsUpdated(key)
 }
 
 func init() {
@@ -959,6 +1439,7 @@ func (n *Node) CopyPropsFrom(frm Ki, deep bool) error {
 	}
 	// pr := prof.Start("CopyPropsFrom")
 	// defer pr.End()
+	defer n.propsChanged()
 	if n.Props == nil {
 		n.Props = make(Props)
 	}
@@ -1034,11 +1515,8 @@ func (n *Node) FuncFields(level int, data any, fun Func) {
 	if n.This() == nil {
 		return
 	}
-	op := uintptr(unsafe.Pointer(n))
-	foffs := KiFieldOffs(n)
-	for _, fo := range foffs {
-		fn := (*Node)(unsafe.Pointer(op + fo))
-		fun(fn.This(), level, data)
+	for _, fo := range KiFieldOffs(n) {
+		fun(kiFieldNode(n, fo).This(), level, data)
 	}
 }
 
@@ -1137,6 +1615,14 @@ func (tm TravMap) Get(k Ki) (curField, curChild int) {
 // aborted, but other branches continue -- i.e., if fun on current node
 // returns false, children are not processed further.
 func (n *Node) FuncDownMeFirst(level int, data any, fun Func) {
+	n.funcDownMeFirst(level, data, fun, true)
+}
+
+// funcDownMeFirst implements FuncDownMeFirst -- if load is false, then
+// children that are not yet loaded (see SetChildLoader) are skipped
+// instead of being loaded, as used for internal traversals that update
+// state.
+func (n *Node) funcDownMeFirst(level int, data any, fun Func, load bool) {
 	if n.This() == nil || n.IsDeleted() {
 		return
 	}
@@ -1157,9 +1643,9 @@ outer:
 					continue
 				}
 			}
-			if cur.HasChildren() {
+			if numKids(cur, load) > 0 {
 				tm.Set(cur, 0, 0) // 0 for no fields
-				nxt := cur.Child(0)
+				nxt, _ := childTry(cur, load, 0)
 				if nxt != nil && nxt.This() != nil && !nxt.IsDeleted() {
 					cur = nxt.This()
 					tm.Start(cur)
@@ -1167,7 +1653,7 @@ outer:
 				}
 			}
 		} else {
-			tm.Set(cur, NumKiFields(cur.AsNode()), cur.NumChildren())
+			tm.Set(cur, NumKiFields(cur.AsNode()), numKids(cur, load))
 			level++ // we will pop back up out of this next
 		}
 		// if we get here, we're in the ascent branch -- move to the right and then up
@@ -1186,10 +1672,10 @@ outer:
 					continue
 				}
 			}
-			if (curChild + 1) < cur.NumChildren() {
+			if (curChild + 1) < numKids(cur, load) {
 				curChild++
 				tm.Set(cur, curField, curChild)
-				nxt := cur.Child(curChild)
+				nxt, _ := childTry(cur, load, curChild)
 				if nxt != nil && nxt.This() != nil && !nxt.IsDeleted() {
 					cur = nxt.This()
 					tm.Start(cur)
@@ -1245,7 +1731,7 @@ outer:
 			}
 			if cur.HasChildren() {
 				tm.Set(cur, 0, 0) // 0 for no fields
-				nxt := cur.Child(0)
+				nxt, _ := cur.ChildTry(0)
 				if nxt != nil && nxt.This() != nil && !nxt.IsDeleted() {
 					cur = nxt.This()
 					tm.Set(cur, -1, -1)
@@ -1275,7 +1761,7 @@ outer:
 			if (curChild + 1) < cur.NumChildren() {
 				curChild++
 				tm.Set(cur, curField, curChild)
-				nxt := cur.Child(curChild)
+				nxt, _ := cur.ChildTry(curChild)
 				if nxt != nil && nxt.This() != nil && !nxt.IsDeleted() {
 					cur = nxt.This()
 					tm.Start(cur)
@@ -1330,7 +1816,7 @@ func (n *Node) FuncDownBreadthFirst(level int, data any, fun Func) {
 					return true
 				})
 			}
-			for _, k := range *cur.Children() {
+			for _, k := range kidsSnapshot(cur) {
 				if k != nil && k.This() != nil && !k.IsDeleted() {
 					SetDepth(k, depth+1)
 					queue = append(queue, k)
@@ -1345,7 +1831,7 @@ func (n *Node) FuncDownBreadthFirst(level int, data any, fun Func) {
 //   levels so there is only one update at highest level of modification
 //   All modification starts with UpdateStart() and ends with UpdateEnd()
 
-// after an UpdateEnd, DestroyDeleted is called
+// after an UpdateEnd, the deleted nodes of the tree are destroyed
 
 // NodeSignal returns the main signal for this node that is used for
 // update, child signals.
@@ -1371,6 +1857,7 @@ func (n *Node) NodeSignal() *Signal {
 //	defer n.UpdateEnd(updt)
 //	... code
 func (n *Node) UpdateStart() bool {
+	n.snapshotSave()
 	if n.IsUpdating() || n.IsDestroyed() {
 		return false
 	}
@@ -1378,23 +1865,33 @@ func (n *Node) UpdateStart() bool {
 		n.SetFlag(int(Updating))
 	} else {
 		// pr := prof.Start("ki.Node.UpdateStart")
-		n.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		n.funcDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
 			if !k.IsUpdating() {
 				k.ClearFlagMask(int64(UpdateFlagsMask))
+				k.AsNode().clearUpdatedProps()
 				k.SetFlag(int(Updating))
 				return Continue
 			}
 			return Break // bail -- already updating
-		})
+		}, false)
 		// pr.End()
 	}
 	return true
 }
 
+// clearUpdatedProps clears the record of updated properties, along with the
+// update flags
+func (n *Node) clearUpdatedProps() {
+	mu := n.lockTree()
+	n.updtProps = nil
+	unlockTree(mu)
+}
+
 // UpdateEnd should be called when done updating after an UpdateStart, and
 // passed the result of the UpdateStart call -- if this is true, the
 // NodeSignalUpdated signal will be emitted and the Updating flag will be
-// cleared, and DestroyDeleted called -- otherwise it is a no-op.
+// cleared, and the deleted nodes of the tree destroyed (depending on the
+// Timing of its DeleteManager) -- otherwise it is a no-op.
 func (n *Node) UpdateEnd(updt bool) {
 	if !updt {
 		return
@@ -1403,20 +1900,24 @@ func (n *Node) UpdateEnd(updt bool) {
 		return
 	}
 	if bitflag.HasAnyAtomic(&n.Flag, int(ChildDeleted), int(ChildrenDeleted)) {
-		DelMgr.DestroyDeleted()
+		treeDelMgr(n, false).destroyAtUpdateEnd()
 	}
 	if n.OnlySelfUpdate() {
 		n.ClearFlag(int(Updating))
 		n.NodeSignal().Emit(n.This(), int64(NodeSignalUpdated), n.Flags())
 	} else {
 		// pr := prof.Start("ki.Node.UpdateEnd")
-		n.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		n.funcDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
 			k.ClearFlag(int(Updating)) // note: could check first and break here but good to ensure all clear
 			return true
-		})
+		}, false)
 		// pr.End()
 		n.NodeSignal().Emit(n.This(), int64(NodeSignalUpdated), n.Flags())
 	}
+	n.emitPropsUpdated()
+	if ValidateOnUpdateEnd {
+		n.validateUpdate()
+	}
 }
 
 // UpdateEndNoSig is just like UpdateEnd except it does not emit a
@@ -1430,18 +1931,21 @@ func (n *Node) UpdateEndNoSig(updt bool) {
 		return
 	}
 	if bitflag.HasAnyAtomic(&n.Flag, int(ChildDeleted), int(ChildrenDeleted)) {
-		DelMgr.DestroyDeleted()
+		treeDelMgr(n, false).destroyAtUpdateEnd()
 	}
 	if n.OnlySelfUpdate() {
 		n.ClearFlag(int(Updating))
 		// n.NodeSignal().Emit(n.This(), int64(NodeSignalUpdated), n.Flags())
 	} else {
-		n.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		n.funcDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
 			k.ClearFlag(int(Updating)) // note: could check first and break here but good to ensure all clear
 			return true
-		})
+		}, false)
 		// n.NodeSignal().Emit(n.This(), int64(NodeSignalUpdated), n.Flags())
 	}
+	if ValidateOnUpdateEnd {
+		n.validateUpdate()
+	}
 }
 
 // UpdateSig just emits a NodeSignalUpdated if the Updating flag is not
@@ -1467,10 +1971,10 @@ func (n *Node) Disconnect() {
 
 // DisconnectAll disconnects all the way from me down the tree.
 func (n *Node) DisconnectAll() {
-	n.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+	n.funcDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
 		k.Disconnect()
 		return true
-	})
+	}, false)
 }
 
 //////////////////////////////////////////////////////////////////////////
@@ -1479,25 +1983,43 @@ func (n *Node) DisconnectAll() {
 // SetField sets given field name to given value, using very robust
 // conversion routines to e.g., convert from strings to numbers, and
 // vice-versa, automatically.  Returns error if not successfully set.
-// wrapped in UpdateStart / End and sets the ValUpdated flag.
+// The field is found by name including embedded structs, and can also be
+// a dot-separated path into struct fields.
+// wrapped in UpdateStart / End and sets the ValUpdated flag, and then
+// calls the field watchers of the node whose values changed (see
+// WatchField).  If StrictFields is on, values that are not valid
+// according to the validation tags of the field are rejected.
 func (n *Node) SetField(field string, val any) error {
-	fv := kit.FlatFieldValueByName(n.This(), field)
+	n.snapshotSave()
+	var fv reflect.Value
+	if strings.Contains(field, ".") {
+		fv, _ = fieldPathValue(n.This(), field)
+	} else {
+		fv = kit.FlatFieldValueByName(n.This(), field)
+	}
 	if !fv.IsValid() {
 		return fmt.Errorf("ki.SetField, could not find field %v on node %v", field, n.Nm)
 	}
+	if err := n.validateSetField(field, val); err != nil {
+		return err
+	}
 	updt := n.UpdateStart()
 	var err error
 	if field == "Nm" {
 		n.SetName(kit.ToString(val))
 		n.SetValUpdated()
 	} else {
-		if kit.SetRobust(kit.PtrValue(fv).Interface(), val) {
+		mu := n.lockTree()
+		ok := kit.SetRobust(kit.PtrValue(fv).Interface(), val)
+		unlockTree(mu)
+		if ok {
 			n.SetValUpdated()
 		} else {
 			err = fmt.Errorf("ki.SetField, SetRobust failed to set field %v on node %v to value: %v", field, n.Nm, val)
 		}
 	}
 	n.UpdateEnd(updt)
+	n.fieldWatchCheck("")
 	return err
 }
 
@@ -1529,8 +2051,9 @@ func (n *Node) CopyFrom(frm Ki) error {
 		return err
 	}
 	updt := n.UpdateStart()
-	defer n.UpdateEnd(updt)
 	err := CopyFromRaw(n.This(), frm)
+	n.UpdateEnd(updt)
+	fieldWatchCheckTree(n.This())
 	return err
 }
 
@@ -1547,11 +2070,13 @@ func (n *Node) Clone() Ki {
 // CopyFromRaw performs a raw copy that just does the deep copy of the
 // bits and doesn't do anything with pointers.
 func CopyFromRaw(kn, frm Ki) error {
+	kn.AsNode().snapshotSave()
 	kn.Children().ConfigCopy(kn.This(), *frm.Children())
 	n := kn.AsNode()
 	fmp := *frm.Properties()
 	n.Props = make(Props, len(fmp))
 	n.Props.CopyFrom(fmp, DeepCopy)
+	n.propsChanged()
 
 	kn.This().CopyFieldsFrom(frm)
 	for i, kid := range *kn.Children() {
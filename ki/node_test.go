@@ -172,8 +172,8 @@ func TestNodeDeleteChild(t *testing.T) {
 	if len(parent.Kids) != 0 {
 		t.Errorf("Children length != 0, was %d", len(parent.Kids))
 	}
-	if len(DelMgr.Dels) != 0 { // note: even though using destroy, UpdateEnd does destroy
-		t.Errorf("Deleted length != 0, was %d", len(DelMgr.Dels))
+	if nd := DeleteManager(&parent).NumDeleted(); nd != 0 { // note: even though using destroy, UpdateEnd does destroy
+		t.Errorf("Deleted length != 0, was %d", nd)
 	}
 }
 
@@ -186,8 +186,8 @@ func TestNodeDeleteChildName(t *testing.T) {
 	if len(parent.Kids) != 0 {
 		t.Errorf("Children length != 0, was %d", len(parent.Kids))
 	}
-	if len(DelMgr.Dels) != 0 { // note: even though using destroy, UpdateEnd does destroy
-		t.Errorf("Deleted length != 0, was %d", len(DelMgr.Dels))
+	if nd := DeleteManager(&parent).NumDeleted(); nd != 0 { // note: even though using destroy, UpdateEnd does destroy
+		t.Errorf("Deleted length != 0, was %d", nd)
 	}
 }
 
@@ -816,6 +816,97 @@ ki.Signal Emit from: tree2 sig: NodeSignalUpdated data: 260
 
 }
 
+func TestSortChildren(t *testing.T) {
+	parent := NodeEmbed{}
+	parent.InitName(&parent, "par1")
+	vals := []int{3, 1, 2, 1}
+	for i, v := range vals {
+		kid := parent.AddNewChild(KiT_NodeEmbed, fmt.Sprintf("child%d", i)).(*NodeEmbed)
+		kid.Mbr2 = v
+		kid.SetProp("size", fmt.Sprintf("%d", 10*(4-i)))
+	}
+	names := func() string {
+		nms := make([]string, parent.NumChildren())
+		for i, kid := range parent.Kids {
+			nms[i] = kid.Name()
+		}
+		return strings.Join(nms, ",")
+	}
+
+	res := []string{}
+	recv := Node{}
+	recv.InitName(&recv, "recv")
+	parent.NodeSignal().Connect(&recv, func(r, s Ki, sig int64, d any) {
+		switch NodeSignals(sig) {
+		case NodeSignalChildrenMoved:
+			res = append(res, fmt.Sprintf("moved %v", d))
+		case NodeSignalUpdated:
+			res = append(res, fmt.Sprintf("updated %v", d.(int64)&(1<<uint(ChildMoved)) != 0))
+		}
+	})
+
+	parent.SortChildrenBy("Mbr2", true)
+	if nms := names(); nms != "child1,child3,child2,child0" {
+		t.Errorf("SortChildrenBy Mbr2 ascending (stable): %v", nms)
+	}
+	if len(res) != 2 || res[0] != "moved [1 3 2 0]" || res[1] != "updated true" {
+		t.Errorf("SortChildrenBy signals: %v", res)
+	}
+	if parent.ChildByName("child3", 0) != parent.Child(1) {
+		t.Errorf("ChildByName after sort")
+	}
+	res = res[:0]
+	parent.SortChildrenBy("Mbr2", true)
+	if len(res) != 0 {
+		t.Errorf("sorting sorted children should not signal: %v", res)
+	}
+
+	parent.SortChildrenBy("size", true) // prop, compared numerically
+	if nms := names(); nms != "child3,child2,child1,child0" {
+		t.Errorf("SortChildrenBy size prop: %v", nms)
+	}
+	parent.SortChildrenBy("Nm", false)
+	if nms := names(); nms != "child3,child2,child1,child0" {
+		t.Errorf("SortChildrenBy Nm descending: %v", nms)
+	}
+	parent.SortChildren(func(a, b Ki) bool { return a.Name() < b.Name() })
+	if nms := names(); nms != "child0,child1,child2,child3" {
+		t.Errorf("SortChildren by name: %v", nms)
+	}
+	parent.ReverseChildren()
+	if nms := names(); nms != "child3,child2,child1,child0" {
+		t.Errorf("ReverseChildren: %v", nms)
+	}
+	res = res[:0]
+	if err := parent.MoveChild(0, 2); err != nil {
+		t.Error(err)
+	}
+	if nms := names(); nms != "child2,child1,child3,child0" {
+		t.Errorf("MoveChild: %v", nms)
+	}
+	if len(res) != 2 || res[0] != "moved [1 2 0 3]" {
+		t.Errorf("MoveChild signals: %v", res)
+	}
+	if err := parent.MoveChild(0, 4); err == nil {
+		t.Errorf("MoveChild should fail for invalid index")
+	}
+	if idx, _ := parent.Child(2).IndexInParent(); idx != 2 {
+		t.Errorf("IndexInParent after move: %d", idx)
+	}
+
+	// ConfigChildren also sets ChildMoved when it reorders
+	res = res[:0]
+	config := kit.TypeAndNameList{}
+	for _, nm := range []string{"child0", "child1", "child2", "child3"} {
+		config.Add(KiT_NodeEmbed, nm)
+	}
+	mods, updt := parent.ConfigChildren(config)
+	parent.UpdateEnd(updt)
+	if !mods || names() != "child0,child1,child2,child3" || len(res) != 1 || res[0] != "updated true" {
+		t.Errorf("ConfigChildren move: %v %v", names(), res)
+	}
+}
+
 func TestNodeFieldFunc(t *testing.T) {
 	parent := NodeField{}
 	parent.InitName(&parent, "par1")
@@ -1126,3 +1217,56 @@ func BenchmarkStdNew(b *testing.B) {
 		n.InitName(n, "")
 	}
 }
+
+func TestNodePropUpdates(t *testing.T) {
+	parent := NodeEmbed{}
+	parent.InitName(&parent, "par1")
+	kid := parent.AddNewChild(KiT_NodeEmbed, "child1")
+
+	res := []string{}
+	keys := [][]string{}
+	recv := Node{}
+	recv.InitName(&recv, "recv")
+	parent.NodeSignal().Connect(&recv, func(r, s Ki, sig int64, d any) {
+		switch NodeSignals(sig) {
+		case NodeSignalUpdated:
+			res = append(res, fmt.Sprintf("%v %v", d.(int64)&(1<<uint(PropUpdated)) != 0, s.UpdatedProps()))
+		case NodeSignalPropsUpdated:
+			keys = append(keys, d.([]string))
+		}
+	})
+
+	parent.SetProp("color", "red")
+	parent.SetProps(Props{"size": 2, "align": "left"})
+	parent.DeleteProp("size")
+	parent.DeleteProp("nope") // not set: no update
+	parent.SetPropNoUpdate("bulk", true)
+	exp := "[true [color] true [align size] true [size]]"
+	if fmt.Sprint(res) != exp {
+		t.Errorf("prop update signals: %v\nexpected: %v", res, exp)
+	}
+	if fmt.Sprint(keys) != "[[color] [align size] [size]]" {
+		t.Errorf("props updated signals: %v", keys)
+	}
+
+	// keys accumulate over an update, for the node they were set on
+	res = res[:0]
+	keys = keys[:0]
+	updt := parent.UpdateStart()
+	parent.SetProp("a", 1)
+	kid.SetProp("b", 2)
+	parent.SetProp("c", 3)
+	parent.SetProp("a", 4)
+	parent.UpdateEnd(updt)
+	if fmt.Sprint(res) != "[true [a c]]" || fmt.Sprint(kid.UpdatedProps()) != "[b]" {
+		t.Errorf("prop updates in update: %v %v", res, kid.UpdatedProps())
+	}
+	if fmt.Sprint(keys) != "[[a c]]" {
+		t.Errorf("props updated signals in update: %v", keys)
+	}
+	updt = parent.UpdateStart()
+	if parent.UpdatedProps() != nil || kid.UpdatedProps() != nil || parent.HasFlag(int(PropUpdated)) {
+		t.Errorf("UpdateStart should clear updated props")
+	}
+	parent.UpdateEnd(updt)
+}
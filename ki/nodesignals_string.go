@@ -14,12 +14,14 @@ func _() {
 	_ = x[NodeSignalNil-0]
 	_ = x[NodeSignalUpdated-1]
 	_ = x[NodeSignalDeleting-2]
-	_ = x[NodeSignalsN-3]
+	_ = x[NodeSignalChildrenMoved-3]
+	_ = x[NodeSignalPropsUpdated-4]
+	_ = x[NodeSignalsN-5]
 }
 
-const _NodeSignals_name = "NodeSignalNilNodeSignalUpdatedNodeSignalDeletingNodeSignalsN"
+const _NodeSignals_name = "NodeSignalNilNodeSignalUpdatedNodeSignalDeletingNodeSignalChildrenMovedNodeSignalPropsUpdatedNodeSignalsN"
 
-var _NodeSignals_index = [...]uint8{0, 13, 30, 48, 60}
+var _NodeSignals_index = [...]uint8{0, 13, 30, 48, 71, 93, 105}
 
 func (i NodeSignals) String() string {
 	if i < 0 || i >= NodeSignals(len(_NodeSignals_index)-1) {
@@ -40,9 +42,11 @@ func (i *NodeSignals) FromString(s string) error {
 
 var _NodeSignals_descMap = map[NodeSignals]string{
 	0: `NodeSignalNil is a nil signal value`,
-	1: `NodeSignalUpdated indicates that the node was updated -- the node Flags accumulate the specific changes made since the last update signal -- these flags are sent in the signal data -- strongly recommend using that instead of the flags, which can be subsequently updated by the time a signal is processed`,
+	1: `NodeSignalUpdated indicates that the node was updated -- the node Flags accumulate the specific changes made since the last update signal -- these flags are sent in the signal data -- strongly recommend using that instead of the flags, which can be subsequently updated by the time a signal is processed -- if the PropUpdated flag is set, the keys of the updated properties are returned by the UpdatedProps method, and are sent in the NodeSignalPropsUpdated signal that follows`,
 	2: `NodeSignalDeleting indicates that the node is being deleted from its parent children list -- this is not blocked by Updating status and is delivered immediately. No further notifications are sent -- assume it will be destroyed unless you hear from it again.`,
-	3: ``,
+	3: `NodeSignalChildrenMoved indicates that the children of the node were reordered, by SortChildren, SortChildrenBy, MoveChild or ReverseChildren -- the signal data is a []int permutation where element i is the previous index of the child now at index i. Like NodeSignalDeleting, this is delivered immediately, and the ChildMoved flag is also set for the subsequent NodeSignalUpdated.`,
+	4: `NodeSignalPropsUpdated indicates that properties of the node were set or deleted, by SetProp, SetProps or DeleteProp -- the signal data is a []string of the keys of the changed properties. It is sent right after the NodeSignalUpdated signal for the change, or, for changes made while the node is updating, after the NodeSignalUpdated signal at the end of the update, with all of the keys changed during it.`,
+	5: ``,
 }
 
 func (i NodeSignals) Desc() string {
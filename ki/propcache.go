@@ -0,0 +1,155 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/goki/ki/kit"
+)
+
+// CachePropInherit turns on caching of PropInherit results on each node,
+// which is off by default.  The cached results of a node are invalidated
+// whenever the properties of the node or any of its parents are changed
+// through the Node methods (SetProp, SetProps, DeleteProp, CopyPropsFrom,
+// CopyFrom, merging), or the node or any of its parents is moved to a
+// different parent, so changes only affect the subtree under the changed
+// node.  All cached results are invalidated when trees are loaded,
+// property schemas are declared, or type properties are changed through
+// kit.Types (see kit.TypePropsVersion).
+//
+// Changes made directly to the maps, instead of through the Node methods,
+// are not detected: code that modifies the Props map returned by
+// Properties must call NodePropsChanged on the node, and code that
+// modifies the map returned by kit.Types.Properties(typ, true) must call
+// kit.TypePropsChanged.
+var CachePropInherit = false
+
+// propsVersion counts changes that affect the PropInherit results of all
+// nodes, see PropsChanged
+var propsVersion int64
+
+// propsClock is incremented for each change to the properties or parent
+// of a node, with the new value stored as the propsStamp of the node, so
+// cached results are valid if they were computed at a clock value at or
+// above the stamps of the node and all of its parents.
+var propsClock int64
+
+// PropsChanged invalidates the cached PropInherit results of all nodes --
+// use NodePropsChanged to only invalidate those affected by a change to
+// a given node.
+func PropsChanged() {
+	atomic.AddInt64(&propsVersion, 1)
+}
+
+// NodePropsChanged invalidates the cached PropInherit results of given
+// node and all of the nodes under it -- must be called after modifying
+// the Props map of the node directly, instead of through the Node methods.
+func NodePropsChanged(k Ki) {
+	k.AsNode().propsChanged()
+}
+
+// propsChanged stamps the node with a new propsClock value -- see
+// NodePropsChanged
+func (n *Node) propsChanged() {
+	atomic.StoreInt64(&n.propsStamp, atomic.AddInt64(&propsClock, 1))
+}
+
+// propsStampAbove returns the latest propsStamp of the node and all of
+// its parents
+func (n *Node) propsStampAbove() int64 {
+	mu := n.rlockTree()
+	defer runlockTree(mu)
+	st := int64(0)
+	for pn := n; pn != nil; {
+		if ps := atomic.LoadInt64(&pn.propsStamp); ps > st {
+			st = ps
+		}
+		if pn.Par == nil {
+			break
+		}
+		pn = pn.Par.AsNode()
+	}
+	return st
+}
+
+// propCacheKey is the key of a cached PropInherit result
+type propCacheKey struct {
+	key     string
+	inherit bool
+	typ     bool
+}
+
+// propCacheVal is a cached PropInherit result
+type propCacheVal struct {
+	val any
+	ok  bool
+}
+
+// propCache caches PropInherit results on a node, valid as long as the
+// props and type props versions are those when the results were computed,
+// and the node and its parents have not changed since the propsClock
+// value when they were computed
+type propCache struct {
+	mu       sync.Mutex
+	version  int64
+	tversion int64
+	clock    int64
+	vals     map[propCacheKey]propCacheVal
+}
+
+// valid returns true if the cache is valid for given versions and
+// latest stamp of the node and its parents
+func (pc *propCache) valid(version, tversion, stamp int64) bool {
+	return pc.vals != nil && pc.version == version && pc.tversion == tversion && pc.clock >= stamp
+}
+
+// get returns the cached result for given key, if valid for given
+// versions and stamp
+func (pc *propCache) get(ck propCacheKey, version, tversion, stamp int64) (propCacheVal, bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if !pc.valid(version, tversion, stamp) {
+		return propCacheVal{}, false
+	}
+	cv, ok := pc.vals[ck]
+	return cv, ok
+}
+
+// set caches given result for given key, computed at given versions and
+// clock, resetting the cache if it was for other ones
+func (pc *propCache) set(ck propCacheKey, version, tversion, clock int64, cv propCacheVal) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if pc.vals == nil || pc.version != version || pc.tversion != tversion || pc.clock != clock {
+		pc.vals = make(map[propCacheKey]propCacheVal)
+		pc.version, pc.tversion, pc.clock = version, tversion, clock
+	}
+	pc.vals[ck] = cv
+}
+
+// reset clears the cache
+func (pc *propCache) reset() {
+	pc.mu.Lock()
+	pc.vals = nil
+	pc.mu.Unlock()
+}
+
+// propInheritCached returns the PropInherit result from the cache if
+// valid, and otherwise computes and caches it.  The versions and clock are
+// read before computing, so a result computed during a change is stored
+// as already outdated.
+func (n *Node) propInheritCached(key string, inherit, typ bool) (any, bool) {
+	ck := propCacheKey{key: key, inherit: inherit, typ: typ}
+	version, tversion := atomic.LoadInt64(&propsVersion), kit.TypePropsVersion()
+	clock := atomic.LoadInt64(&propsClock)
+	if cv, ok := n.propCache.get(ck, version, tversion, n.propsStampAbove()); ok {
+		return cv.val, cv.ok
+	}
+	v, ok := n.propInherit(key, inherit, typ)
+	n.propCache.set(ck, version, tversion, clock, propCacheVal{val: v, ok: ok})
+	return v, ok
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/goki/ki/kit"
+)
+
+type propCacheTestNode struct {
+	Node
+}
+
+var KiT_propCacheTestNode = kit.Types.AddType(&propCacheTestNode{}, nil)
+
+// propCacheTestTree returns a chain of nodes of given depth, with the last
+// node of test type
+func propCacheTestTree(depth int) (root, leaf Ki) {
+	rn := &Node{}
+	rn.InitName(rn, "root")
+	leaf = rn
+	for i := 1; i < depth; i++ {
+		leaf = leaf.AddNewChild(KiT_Node, fmt.Sprintf("n%d", i))
+	}
+	leaf = leaf.AddNewChild(KiT_propCacheTestNode, "leaf")
+	return rn, leaf
+}
+
+// checkPropCache checks that cached PropInherit results for given keys on
+// given node match the uncached ones, for all options
+func checkPropCache(t *testing.T, step string, k Ki, keys ...string) {
+	t.Helper()
+	n := k.AsNode()
+	for _, key := range keys {
+		for _, inherit := range []bool{Inherit, NoInherit} {
+			for _, typ := range []bool{TypeProps, NoTypeProps} {
+				cv, cok := n.propInheritCached(key, inherit, typ)
+				v, ok := n.propInherit(key, inherit, typ)
+				if cv != v || cok != ok {
+					t.Errorf("%v: %v inherit: %v typ: %v: cached %v %v != %v %v", step, key, inherit, typ, cv, cok, v, ok)
+				}
+			}
+		}
+	}
+}
+
+func TestPropInheritCache(t *testing.T) {
+	root, leaf := propCacheTestTree(4)
+	mid := leaf.Parent().Parent()
+	keys := []string{"a", "b", "c"}
+	checkPropCache(t, "empty", leaf, keys...)
+
+	root.SetProp("a", 1)
+	checkPropCache(t, "set on root", leaf, keys...)
+	mid.SetProp("a", 2)
+	checkPropCache(t, "set on parent", leaf, keys...)
+	leaf.SetProp("b", 3)
+	checkPropCache(t, "set on leaf", leaf, keys...)
+	mid.DeleteProp("a")
+	checkPropCache(t, "delete on parent", leaf, keys...)
+	if v, _ := leaf.PropInherit("a", Inherit, NoTypeProps); v != 1 {
+		t.Errorf("after delete: %v", v)
+	}
+
+	other := &Node{}
+	other.InitName(other, "other")
+	other.SetProp("a", 4)
+	MoveToParent(leaf.Parent(), other)
+	checkPropCache(t, "move", leaf, keys...)
+	if v, _ := leaf.PropInherit("a", Inherit, NoTypeProps); v != 4 {
+		t.Errorf("after move: %v", v)
+	}
+
+	tp := kit.Types.Properties(KiT_propCacheTestNode, true)
+	kit.SetTypeProp(*tp, "c", 5)
+	checkPropCache(t, "type prop", leaf, keys...)
+	if v, _ := leaf.PropInherit("c", Inherit, TypeProps); v != 5 {
+		t.Errorf("type prop: %v", v)
+	}
+
+	(*other.Properties())["a"] = 6 // direct change
+	NodePropsChanged(other)
+	checkPropCache(t, "direct", leaf, keys...)
+
+	// changes elsewhere do not invalidate the cache
+	oroot, oleaf := propCacheTestTree(3)
+	checkPropCache(t, "other tree", oleaf, keys...)
+	leaf.SetProp("a", 7)
+	sib := leaf.Parent().AddNewChild(KiT_Node, "sib")
+	sib.SetProp("a", 8)
+	ck := propCacheKey{key: "a", inherit: Inherit, typ: TypeProps}
+	on := oleaf.AsNode()
+	if _, ok := on.propCache.get(ck, propsVersion, kit.TypePropsVersion(), on.propsStampAbove()); !ok {
+		t.Errorf("change in other tree invalidated cache")
+	}
+	n := leaf.AsNode()
+	if _, ok := n.propCache.get(ck, propsVersion, kit.TypePropsVersion(), n.propsStampAbove()); ok {
+		t.Errorf("change on leaf did not invalidate cache")
+	}
+	checkPropCache(t, "after leaf change", leaf, keys...)
+	sib.SetProp("a", 9)
+	if _, ok := n.propCache.get(ck, propsVersion, kit.TypePropsVersion(), n.propsStampAbove()); !ok {
+		t.Errorf("change on sibling invalidated cache")
+	}
+	oroot.SetProp("a", 10)
+	if _, ok := on.propCache.get(ck, propsVersion, kit.TypePropsVersion(), on.propsStampAbove()); ok {
+		t.Errorf("change on root did not invalidate cache")
+	}
+	checkPropCache(t, "after root change", oleaf, keys...)
+}
+
+func benchmarkPropInherit(b *testing.B, cache bool) {
+	prv := CachePropInherit
+	CachePropInherit = cache
+	defer func() { CachePropInherit = prv }()
+	root, leaf := propCacheTestTree(20)
+	root.SetProp("color", "red")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		leaf.PropInherit("color", Inherit, TypeProps)
+		leaf.PropInherit("missing", Inherit, TypeProps)
+	}
+}
+
+func BenchmarkPropInherit(b *testing.B) {
+	benchmarkPropInherit(b, true)
+}
+
+func BenchmarkPropInheritUncached(b *testing.B) {
+	benchmarkPropInherit(b, false)
+}
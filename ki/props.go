@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/goki/ki/kit"
@@ -101,9 +102,12 @@ type PropStruct struct {
 
 // PropSlice is a slice of PropStruct, for when order is important within a
 // subset of properties (maps do not retain order) -- can set the value of a
-// property to a PropSlice to create an ordered list of property values.
+// property to a PropSlice to create an ordered list of property values,
+// which is also saved to JSON in that order.
 type PropSlice []PropStruct
 
+var KiT_PropSlice = kit.Types.AddType(&PropSlice{}, nil)
+
 // ElemLabel satisfies the gi.SliceLabeler interface to provide labels for slice elements
 func (ps *PropSlice) ElemLabel(idx int) string {
 	return (*ps)[idx].Name
@@ -204,9 +208,12 @@ func (dest *PropSlice) CopyFrom(src PropSlice, deepCopy bool) {
 	}
 }
 
-// MarshalJSON saves the type information for each struct used in props, as a
-// separate key with the __type: prefix -- this allows the Unmarshal to
-// create actual types
+// MarshalJSON saves the type information for each value used in props that
+// needs it, as a separate key with the __type: prefix (see PropTypeDesc) --
+// this allows the Unmarshal to create actual types.  Keys are saved in
+// sorted order, with the __type: key just before the value it applies to,
+// so the output is deterministic -- use a PropSlice to save properties in
+// a given order instead.
 func (p Props) MarshalJSON() ([]byte, error) {
 	nk := len(p)
 	b := make([]byte, 0, nk*100+20)
@@ -214,45 +221,120 @@ func (p Props) MarshalJSON() ([]byte, error) {
 		b = append(b, []byte("null")...)
 		return b, nil
 	}
+	keys := make([]string, 0, nk)
+	for key := range p {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 	b = append(b, []byte("{")...)
-	cnt := 0
-	var err error
-	for key, val := range p {
-		vt := kit.NonPtrType(reflect.TypeOf(val))
-		vk := vt.Kind()
-		if vk == reflect.Struct {
-			knm := kit.Types.TypeName(vt)
-			tstr := fmt.Sprintf("\"%v%v\": \"%v\",", struTypeKey, key, knm)
-			b = append(b, []byte(tstr)...)
+	for i, key := range keys {
+		b = appendPropJSON(b, key, p[key])
+		if i < nk-1 {
+			b = append(b, []byte(",")...)
 		}
-		kstr := fmt.Sprintf("\"%v\": ", key)
-		b = append(b, []byte(kstr)...)
+	}
+	b = append(b, []byte("}")...)
+	// fmt.Printf("json out: %v\n", string(b))
+	return b, nil
+}
 
-		var kb []byte
-		kb, err = json.Marshal(val)
-		if err != nil {
-			log.Printf("error doing json.Marshall from val: %v\n%v\n", val, err)
-			log.Printf("output to point of error: %v\n", string(b))
-		} else {
-			if vk >= reflect.Int && vk <= reflect.Uint64 && kit.Enums.TypeRegistered(vt) {
-				knm := kit.Types.TypeName(vt)
-				kb, _ = json.Marshal(val)
-				estr := fmt.Sprintf("\"%v(%v)%v\"", enumTypeKey, knm, string(bytes.Trim(kb, "\"")))
-				b = append(b, []byte(estr)...)
-			} else {
-				b = append(b, kb...)
-			}
-		}
-		if cnt < nk-1 {
+// appendPropJSON appends the JSON of given property key and value to b,
+// preceded by the __type: key with the type of the value if needed to load
+// it (see PropTypeDesc), with enum values saved as strings with their type
+// name, and Ki values with the type name of the node
+func appendPropJSON(b []byte, key string, val any) []byte {
+	vt := reflect.TypeOf(val)
+	tdesc := ""
+	enum := false
+	if _, isKi := val.(Ki); isKi {
+		tdesc = kit.Types.TypeName(kit.NonPtrType(vt))
+	} else if vk := kit.NonPtrType(vt).Kind(); vk >= reflect.Int && vk <= reflect.Uint64 && kit.Enums.TypeRegistered(vt) {
+		enum = true
+	} else if propNeedsType(vt) {
+		tdesc, _ = PropTypeDesc(vt)
+	}
+	if tdesc != "" {
+		tstr := fmt.Sprintf("\"%v%v\": \"%v\",", struTypeKey, key, tdesc)
+		b = append(b, []byte(tstr)...)
+	}
+	kstr := fmt.Sprintf("\"%v\": ", key)
+	b = append(b, []byte(kstr)...)
+
+	kb, err := json.Marshal(val)
+	if err != nil {
+		log.Printf("error doing json.Marshall from val: %v\n%v\n", val, err)
+		log.Printf("output to point of error: %v\n", string(b))
+		return b
+	}
+	if enum {
+		knm := kit.Types.TypeName(vt)
+		estr := fmt.Sprintf("\"%v(%v)%v\"", enumTypeKey, knm, string(bytes.Trim(kb, "\"")))
+		return append(b, []byte(estr)...)
+	}
+	return append(b, kb...)
+}
+
+// MarshalJSON saves the properties as a JSON object with the keys in the
+// order of the slice, using the same encoding of values as Props.
+func (ps PropSlice) MarshalJSON() ([]byte, error) {
+	nk := len(ps)
+	b := make([]byte, 0, nk*100+20)
+	if nk == 0 {
+		b = append(b, []byte("null")...)
+		return b, nil
+	}
+	b = append(b, []byte("{")...)
+	for i, pr := range ps {
+		b = appendPropJSON(b, pr.Name, pr.Value)
+		if i < nk-1 {
 			b = append(b, []byte(",")...)
 		}
-		cnt++
 	}
 	b = append(b, []byte("}")...)
-	// fmt.Printf("json out: %v\n", string(b))
 	return b, nil
 }
 
+// UnmarshalJSON loads properties saved by MarshalJSON, in the saved order,
+// or saved as a list of Name, Value structs.
+func (ps *PropSlice) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		*ps = nil
+		return nil
+	}
+	if len(b) > 0 && b[0] == '[' {
+		return json.Unmarshal(b, (*[]PropStruct)(ps))
+	}
+	var pr Props
+	if err := json.Unmarshal(b, &pr); err != nil {
+		return err
+	}
+	// get the order of the keys
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if _, err := dec.Token(); err != nil { // {
+		return err
+	}
+	*ps = make(PropSlice, 0, len(pr))
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		key := tok.(string)
+		if strings.HasPrefix(key, struTypeKey) {
+			continue
+		}
+		if val, has := pr[key]; has {
+			*ps = append(*ps, PropStruct{Name: key, Value: val})
+		}
+	}
+	return nil
+}
+
 // UnmarshalJSON parses the type information in the map to restore actual
 // objects -- this is super inefficient and really needs a native parser, but
 // props are likely to be relatively small
@@ -264,7 +346,7 @@ func (p *Props) UnmarshalJSON(b []byte) error {
 	}
 
 	// load into a temporary map and then process
-	tmp := make(map[string]any)
+	tmp := make(map[string]json.RawMessage)
 	err := json.Unmarshal(b, &tmp)
 	if err != nil {
 		return err
@@ -272,16 +354,17 @@ func (p *Props) UnmarshalJSON(b []byte) error {
 
 	*p = make(Props, len(tmp))
 
-	// create all the structure objects from the list -- have to do this first to get all
-	// the structs made b/c the order is random..
+	// create all the typed values from the list -- have to do this first to get all
+	// the values made b/c the order is random..
 	for key, val := range tmp {
 		if strings.HasPrefix(key, struTypeKey) {
 			pkey := strings.TrimPrefix(key, struTypeKey)
 			rval := tmp[pkey]
-			tn := val.(string)
-			typ := kit.Types.Type(tn)
+			var tn string
+			json.Unmarshal(val, &tn)
+			typ := PropTypeFromDesc(tn)
 			if typ == nil {
-				log.Printf("ki.Props: cannot load struct of type %v -- not registered in kit.Types\n", tn)
+				log.Printf("ki.Props: cannot load value of type %v -- not registered in kit.Types or kit.Enums\n", tn)
 				continue
 			}
 			if IsKi(typ) { // note: not really a good idea to store ki's in maps, but..
@@ -289,8 +372,7 @@ func (p *Props) UnmarshalJSON(b []byte) error {
 				InitNode(kival)
 				if kival != nil {
 					// fmt.Printf("stored new ki of type %v in key: %v\n", typ.String(), pkey)
-					tmpb, _ := json.Marshal(rval) // string rep of this
-					err = kival.ReadJSON(bytes.NewReader(tmpb))
+					err = kival.ReadJSON(bytes.NewReader(rval))
 					if err != nil {
 						log.Printf("ki.Props failed to load Ki struct of type %v with error: %v\n", typ.String(), err)
 					}
@@ -298,11 +380,10 @@ func (p *Props) UnmarshalJSON(b []byte) error {
 				}
 			} else {
 				stval := reflect.New(typ).Interface()
-				// fmt.Printf("stored new struct of type %v in key: %v\n", typ.String(), pkey)
-				tmpb, _ := json.Marshal(rval) // string rep of this
-				err = json.Unmarshal(tmpb, stval)
+				// fmt.Printf("stored new value of type %v in key: %v\n", typ.String(), pkey)
+				err = json.Unmarshal(rval, stval)
 				if err != nil {
-					log.Printf("ki.Props failed to load struct of type %v with error: %v\n", typ.String(), err)
+					log.Printf("ki.Props failed to load value of type %v with error: %v\n", typ.String(), err)
 				}
 				(*p)[pkey] = reflect.ValueOf(stval).Elem().Interface()
 			}
@@ -310,19 +391,20 @@ func (p *Props) UnmarshalJSON(b []byte) error {
 	}
 
 	// now can re-iterate
-	for key, val := range tmp {
+	for key, rval := range tmp {
 		if strings.HasPrefix(key, struTypeKey) {
 			continue
 		}
 		if _, ok := (*p)[key]; ok { // already created -- was a struct -- skip
 			continue
 		}
+		var val any
+		json.Unmarshal(rval, &val)
 		// look for sub-maps, make them props..
 		if _, ok := val.(map[string]any); ok {
 			// fmt.Printf("stored new Props map in key: %v\n", key)
 			subp := Props{}
-			tmpb, _ := json.Marshal(val) // string rep of this
-			err = json.Unmarshal(tmpb, &subp)
+			err = json.Unmarshal(rval, &subp)
 			if err != nil {
 				log.Printf("ki.Props failed to load sub-Props with error: %v\n", err)
 			}
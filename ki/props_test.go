@@ -6,6 +6,7 @@ package ki
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/goki/ki/kit"
@@ -47,3 +48,125 @@ func TestPropsJSonSave(t *testing.T) {
 		// }
 	}
 }
+
+type propsTestStruct struct {
+	A string
+	B int
+}
+
+var KiT_propsTestStruct = kit.Types.AddType(&propsTestStruct{}, nil)
+
+func TestPropsJSONOrder(t *testing.T) {
+	b, err := json.Marshal(PropsTest)
+	if err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < 5; i++ {
+		if nb, _ := json.Marshal(PropsTest); string(nb) != string(b) {
+			t.Errorf("props json output not deterministic:\n%v\n%v", string(b), string(nb))
+		}
+	}
+	b, _ = json.Marshal(Props{"b": propsTestStruct{A: "x", B: 1}, "a": kit.TestFlag2, "c": Props{"z": 1, "y": 2}})
+	exp := `{"a":"__enum:(kit.TestFlags)TestFlag2","__type:b":"ki.propsTestStruct","b":{"A":"x","B":1},"c":{"__type:y":"int","y":2,"__type:z":"int","z":1}}`
+	if string(b) != exp {
+		t.Errorf("props json output:\n%v\nexpected:\n%v", string(b), exp)
+	}
+}
+
+func TestPropSliceJSON(t *testing.T) {
+	ps := PropSlice{{"z", 1.0}, {"a", "str"}, {"m", propsTestStruct{A: "x"}}, {"e", kit.TestFlag1}}
+	pr := Props{"ordered": ps}
+	b, err := json.Marshal(pr)
+	if err != nil {
+		t.Error(err)
+	}
+	var lpr Props
+	if err := json.Unmarshal(b, &lpr); err != nil {
+		t.Error(err)
+	}
+	lps, ok := lpr["ordered"].(PropSlice)
+	if !ok || len(lps) != len(ps) {
+		t.Fatalf("PropSlice not loaded: %#v from %v", lpr["ordered"], string(b))
+	}
+	for i := range ps {
+		if lps[i] != ps[i] {
+			t.Errorf("PropSlice element %d: %v != %v", i, lps[i], ps[i])
+		}
+	}
+
+	// lists of Name, Value structs are also loaded
+	if err := json.Unmarshal([]byte(`[{"Name":"b","Value":2},{"Name":"a","Value":"x"}]`), &lps); err != nil || len(lps) != 2 || lps[0].Name != "b" {
+		t.Errorf("PropSlice list: %v %v", lps, err)
+	}
+}
+
+func TestPropsTypedRoundTrip(t *testing.T) {
+	st := &propsTestStruct{A: "ptr", B: 3}
+	pr := Props{
+		"int":     -17,
+		"int32":   int32(4),
+		"float32": float32(1.5),
+		"float64": 2.5,
+		"bytes":   []byte("abc"),
+		"structs": []propsTestStruct{{A: "a", B: 1}, {A: "b", B: 2}},
+		"ptr":     st,
+		"array":   [2]float32{1, 2},
+		"enumkey": map[kit.TestFlags]string{kit.TestFlag1: "one", kit.TestFlag2: "two"},
+		"enums":   []kit.TestFlags{kit.TestFlag2},
+		"nested":  map[string][]int{"x": {1, 2}},
+		"type":    kit.Type{T: KiT_Node},
+		"generic": []any{"a", 1.0, true},
+		"enum":    kit.TestFlag1,
+		"sub":     Props{"n": int64(5), "ordered": PropSlice{{"z", uint8(1)}, {"a", st}}},
+	}
+	b, err := json.Marshal(pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lpr Props
+	if err := json.Unmarshal(b, &lpr); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pr, lpr) {
+		for k, v := range pr {
+			if !reflect.DeepEqual(v, lpr[k]) {
+				t.Errorf("prop %v: %#v != %#v", k, lpr[k], v)
+			}
+		}
+	}
+	var cpr Props
+	cpr.CopyFrom(lpr, DeepCopy)
+	if !reflect.DeepEqual(pr, cpr) {
+		t.Errorf("deep copy of loaded props not equal")
+	}
+
+	// Ki values
+	kn := &NodeEmbed{}
+	kn.InitName(kn, "kn")
+	kn.Mbr1 = "mbr"
+	b, _ = json.Marshal(Props{"ki": kn})
+	lpr = nil
+	json.Unmarshal(b, &lpr)
+	if lk, ok := lpr["ki"].(*NodeEmbed); !ok || lk.Name() != "kn" || lk.Mbr1 != "mbr" {
+		t.Errorf("Ki prop not loaded: %#v", lpr["ki"])
+	}
+}
+
+func TestPropTypeDesc(t *testing.T) {
+	for _, v := range []any{0, "", []*propsTestStruct{}, map[kit.TestFlags][3]any{}, PropSlice{}, kit.Type{}} {
+		typ := reflect.TypeOf(v)
+		desc, ok := PropTypeDesc(typ)
+		if !ok || PropTypeFromDesc(desc) != typ {
+			t.Errorf("type %v: desc %v %v -> %v", typ, desc, ok, PropTypeFromDesc(desc))
+		}
+	}
+	type unreg struct{}
+	if _, ok := PropTypeDesc(reflect.TypeOf([]unreg{})); ok {
+		t.Errorf("unregistered type should not be described")
+	}
+	for _, desc := range []string{"map[int", "[]", "[x]int", "ki.NotAType", "int]", "map[[]int]int"} {
+		if typ := PropTypeFromDesc(desc); typ != nil {
+			t.Errorf("invalid desc %v -> %v", desc, typ)
+		}
+	}
+}
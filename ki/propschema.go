@@ -0,0 +1,386 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goki/ki/kit"
+)
+
+// PropSpec declares a property key accepted by a node type, see
+// DeclareProps.
+type PropSpec struct {
+
+	// the property key
+	Key string `desc:"the property key"`
+
+	// type of the values, which are converted to this type if possible when StrictProps is on -- nil for any type
+	Type reflect.Type `desc:"type of the values, which are converted to this type if possible when StrictProps is on -- nil for any type"`
+
+	// enum type registered in kit.Enums for the values, which can then also be set as strings or ints when StrictProps is on -- if set, Type is this type
+	Enum reflect.Type `desc:"enum type registered in kit.Enums for the values, which can then also be set as strings or ints when StrictProps is on -- if set, Type is this type"`
+
+	// default value returned by PropInherit with typ = true when the property is not set anywhere -- nil for none
+	Default any `desc:"default value returned by PropInherit with typ = true when the property is not set anywhere -- nil for none"`
+
+	// whether the property is inherited from parents by PropInherit -- if false, parents are not checked even if requested
+	Inherit bool `desc:"whether the property is inherited from parents by PropInherit -- if false, parents are not checked even if requested"`
+
+	// description of the property
+	Desc string `desc:"description of the property"`
+}
+
+// StrictProps turns on validation of property keys and values in SetProp
+// and SetProps, for nodes whose types have declared properties (see
+// DeclareProps): keys that are not declared are rejected, and values are
+// converted to the declared type, or rejected if that is not possible.
+// Rejected properties are not set, and the error is logged.  Nodes of
+// types without declared properties accept any properties.
+var StrictProps = false
+
+// propSchemas is the registry of property schemas by type
+var propSchemas = struct {
+	mu sync.RWMutex
+
+	// specs declared for each type
+	decl map[reflect.Type]map[string]*PropSpec
+
+	// specs for each type including those of embedded types, built on demand
+	all map[reflect.Type]map[string]*PropSpec
+}{decl: make(map[reflect.Type]map[string]*PropSpec), all: make(map[reflect.Type]map[string]*PropSpec)}
+
+// DeclareProps declares property keys accepted by given node type, which
+// also apply to types embedding it, and returns the type, so it can be
+// used along with the type registration, e.g.:
+//
+//	var KiT_MyNode = ki.DeclareProps(kit.Types.AddType(&MyNode{}, nil),
+//		ki.PropSpec{Key: "color", Type: reflect.TypeOf(""), Default: "black", Inherit: true})
+//
+// Invalid specs (an unregistered Enum type, or a Default that is not of
+// the declared type) are logged and skipped -- use DeclarePropsTry to get
+// them as an error instead.
+func DeclareProps(typ reflect.Type, specs ...PropSpec) reflect.Type {
+	if err := DeclarePropsTry(typ, specs...); err != nil {
+		log.Printf("ki.DeclareProps: %v\n", err)
+	}
+	return kit.NonPtrType(typ)
+}
+
+// DeclarePropsTry declares property keys accepted by given node type, as
+// DeclareProps does -- Try version returns an error describing any
+// invalid specs, which are skipped, while the valid ones are declared.
+func DeclarePropsTry(typ reflect.Type, specs ...PropSpec) error {
+	typ = kit.NonPtrType(typ)
+	propSchemas.mu.Lock()
+	defer propSchemas.mu.Unlock()
+	decl := propSchemas.decl[typ]
+	if decl == nil {
+		decl = make(map[string]*PropSpec, len(specs))
+		propSchemas.decl[typ] = decl
+	}
+	var errs []string
+	for i := range specs {
+		ps := specs[i]
+		if ps.Enum != nil {
+			if !kit.Enums.TypeRegistered(ps.Enum) {
+				errs = append(errs, fmt.Sprintf("type %v prop %v: enum type %v not registered in kit.Enums", kit.ShortTypeName(typ), ps.Key, ps.Enum))
+				continue
+			}
+			ps.Type = ps.Enum
+		}
+		if ps.Default != nil {
+			dv, err := ps.Convert(ps.Default)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("type %v: invalid default: %v", kit.ShortTypeName(typ), err))
+				continue
+			}
+			ps.Default = dv
+		}
+		decl[ps.Key] = &ps
+	}
+	propSchemas.all = make(map[reflect.Type]map[string]*PropSpec) // embedding types need rebuilding
+	PropsChanged()
+	if errs != nil {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// PropSpecs returns the property specs declared for given type and the
+// types it embeds, by key -- nil if none are declared.  The returned map
+// must not be modified.
+func PropSpecs(typ reflect.Type) map[string]*PropSpec {
+	typ = kit.NonPtrType(typ)
+	propSchemas.mu.RLock()
+	specs, has := propSchemas.all[typ]
+	propSchemas.mu.RUnlock()
+	if has {
+		return specs
+	}
+	propSchemas.mu.Lock()
+	defer propSchemas.mu.Unlock()
+	specs = allPropSpecs(typ, nil)
+	propSchemas.all[typ] = specs
+	return specs
+}
+
+// allPropSpecs adds the specs declared for given type and the types it
+// embeds to given map (made if needed), with those of the outer type
+// taking precedence -- must be called under the schema lock
+func allPropSpecs(typ reflect.Type, specs map[string]*PropSpec) map[string]*PropSpec {
+	for key, ps := range propSchemas.decl[typ] {
+		if specs == nil {
+			specs = make(map[string]*PropSpec)
+		}
+		if _, has := specs[key]; !has {
+			specs[key] = ps
+		}
+	}
+	if typ.Kind() != reflect.Struct {
+		return specs
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if f := typ.Field(i); f.Anonymous {
+			specs = allPropSpecs(kit.NonPtrType(f.Type), specs)
+		}
+	}
+	return specs
+}
+
+// PropSpecFor returns the spec of given property key for given node, or
+// nil if not declared for its type
+func PropSpecFor(k Ki, key string) *PropSpec {
+	return nodePropSpecs(k)[key]
+}
+
+// nodePropSpecs returns the property specs of the type of given node, nil
+// if none or if the node is not initialized
+func nodePropSpecs(k Ki) map[string]*PropSpec {
+	if k == nil || k.This() == nil {
+		return nil
+	}
+	return PropSpecs(Type(k))
+}
+
+// Convert returns given value converted to the type of the spec: enums
+// can be given as values of the enum type, strings or ints, and other
+// types as any value that kit.SetRobust can convert -- returns an error
+// if the value cannot be converted
+func (ps *PropSpec) Convert(val any) (any, error) {
+	if ps.Type == nil || val == nil || reflect.TypeOf(val) == ps.Type {
+		return val, nil
+	}
+	nv := reflect.New(ps.Type)
+	if ps.Enum != nil {
+		vv := reflect.ValueOf(val)
+		switch {
+		case vv.Kind() == reflect.String:
+			if err := kit.Enums.SetAnyEnumValueFromString(nv, vv.String()); err != nil {
+				return nil, fmt.Errorf("prop %v: invalid %v value: %v", ps.Key, kit.ShortTypeName(ps.Enum), val)
+			}
+			return nv.Elem().Interface(), nil
+		case vv.Kind() >= reflect.Int && vv.Kind() <= reflect.Uint64:
+			iv := kit.EnumIfaceToInt64(val)
+			if !kit.Enums.IsBitFlag(ps.Enum) && (iv < 0 || iv >= kit.Enums.NVals(nv.Elem().Interface())) {
+				return nil, fmt.Errorf("prop %v: %v value out of range: %v", ps.Key, kit.ShortTypeName(ps.Enum), val)
+			}
+			return kit.EnumIfaceFromInt64(iv, ps.Enum), nil
+		}
+		return nil, fmt.Errorf("prop %v: cannot convert %T to %v", ps.Key, val, kit.ShortTypeName(ps.Enum))
+	}
+	if reflect.TypeOf(val).AssignableTo(ps.Type) {
+		nv.Elem().Set(reflect.ValueOf(val))
+		return nv.Elem().Interface(), nil
+	}
+	if !kit.SetRobust(nv.Interface(), val) {
+		return nil, fmt.Errorf("prop %v: cannot convert %T value %v to %v", ps.Key, val, val, ps.Type)
+	}
+	return nv.Elem().Interface(), nil
+}
+
+// ValidateProp checks given property key and value against the declared
+// properties of the type of given node, returning the value converted to
+// the declared type -- returns the value as is if the type has no
+// declared properties, and an error if the key is not declared or the
+// value cannot be converted.
+func ValidateProp(k Ki, key string, val any) (any, error) {
+	specs := nodePropSpecs(k)
+	if specs == nil {
+		return val, nil
+	}
+	ps, has := specs[key]
+	if !has {
+		return nil, fmt.Errorf("ki %v: property %v is not declared for type %v", k.Name(), key, kit.ShortTypeName(Type(k)))
+	}
+	cv, err := ps.Convert(val)
+	if err != nil {
+		return nil, fmt.Errorf("ki %v: %v", k.Name(), err)
+	}
+	return cv, nil
+}
+
+// strictProp validates given property if StrictProps is on, returning
+// the converted value and false if it is invalid, logging the error
+func strictProp(k Ki, key string, val any) (any, bool) {
+	if !StrictProps {
+		return val, true
+	}
+	cv, err := ValidateProp(k, key, val)
+	if err != nil {
+		log.Printf("ki.SetProp: %v\n", err)
+		return nil, false
+	}
+	return cv, true
+}
+
+// PropSources are the sources of the effective value of a property
+type PropSources int32
+
+//go:generate stringer -type=PropSources
+
+var KiT_PropSources = kit.Enums.AddEnum(PropSourcesN, kit.NotBitFlag, nil)
+
+const (
+	// PropOwn means the property is set on the node itself.
+	PropOwn PropSources = iota
+
+	// PropInherited means the property is set on a parent of the node.
+	PropInherited
+
+	// PropType means the property is a type property (kit.Types) of the
+	// type of the node or of a parent.
+	PropType
+
+	// PropDefault means the value is the declared default of the property.
+	PropDefault
+
+	PropSourcesN
+)
+
+// EffectiveProp is the effective value of a property of a node, with its
+// source, as returned by EffectiveProps
+type EffectiveProp struct {
+
+	// the property key
+	Key string `desc:"the property key"`
+
+	// the effective value
+	Value any `desc:"the effective value"`
+
+	// where the value comes from
+	Source PropSources `desc:"where the value comes from"`
+
+	// the node that the value was set on, or whose type it is a property of -- nil for defaults, and the parent for values from PropInherit methods of other types
+	From Ki `desc:"the node that the value was set on, or whose type it is a property of -- nil for defaults, and the parent for values from PropInherit methods of other types"`
+}
+
+// EffectiveProps returns the effective properties of given node, sorted by
+// key: its own properties, and all the properties declared for its type,
+// resolved as by PropInherit with typ = true and inherit as declared for
+// each property, including those with default values.
+func EffectiveProps(k Ki) []EffectiveProp {
+	n := k.AsNode()
+	specs := nodePropSpecs(k)
+	mu := n.rlockTree()
+	keys := make([]string, 0, len(n.Props)+len(specs))
+	for key := range n.Props {
+		keys = append(keys, key)
+	}
+	for key := range specs {
+		if _, has := n.Props[key]; !has {
+			keys = append(keys, key)
+		}
+	}
+	runlockTree(mu)
+	sort.Strings(keys)
+	var eps []EffectiveProp
+	for _, key := range keys {
+		inherit := true
+		ps := specs[key]
+		if ps != nil {
+			inherit = ps.Inherit
+		}
+		v, ok := n.propLookup(key, inherit, true)
+		if !ok {
+			if ps == nil || ps.Default == nil {
+				continue
+			}
+			eps = append(eps, EffectiveProp{Key: key, Value: ps.Default, Source: PropDefault})
+			continue
+		}
+		sv, src, from, ok := propSource(k, key, inherit)
+		if !ok || !reflect.DeepEqual(sv, v) { // from a PropInherit method of a parent type
+			src, from = PropInherited, n.Parent()
+		}
+		eps = append(eps, EffectiveProp{Key: key, Value: v, Source: src, From: from})
+	}
+	return eps
+}
+
+// propLookup returns the value of given property key using the PropInherit
+// lookup order: the node's own props, then the PropInherit method of its
+// parent if inherit (so types can override it), and then the type props of
+// the node if typ.  Declared defaults are applied by PropInherit.
+func (n *Node) propLookup(key string, inherit, typ bool) (any, bool) {
+	mu := n.rlockTree()
+	v, ok := n.Props[key]
+	par := n.Par
+	runlockTree(mu)
+	if ok {
+		return v, true
+	}
+	if inherit && par != nil {
+		if v, ok := par.PropInherit(key, inherit, typ); ok {
+			return v, true
+		}
+	}
+	if typ {
+		return kit.Types.Prop(Type(n.This()), key)
+	}
+	return nil, false
+}
+
+// propSource returns the value of given property key as found by the Node
+// PropInherit method on given node with typ = true, along with where it
+// comes from and the node it came from (nil for defaults).  It does not
+// know about PropInherit methods of other types, which can return other
+// values.
+func propSource(k Ki, key string, inherit bool) (any, PropSources, Ki, bool) {
+	n := k.AsNode()
+	mu := n.rlockTree()
+	v, ok := n.Props[key]
+	par := n.Par
+	runlockTree(mu)
+	if ok {
+		return v, PropOwn, k, true
+	}
+	if inherit && par != nil {
+		pinherit := true
+		ps := PropSpecFor(par, key)
+		if ps != nil && !ps.Inherit {
+			pinherit = false
+		}
+		if v, src, from, ok := propSource(par, key, pinherit); ok {
+			if src == PropOwn {
+				src = PropInherited
+			}
+			return v, src, from, true
+		}
+		if ps != nil && ps.Default != nil {
+			return ps.Default, PropDefault, nil, true
+		}
+	}
+	if v, ok := kit.Types.Prop(Type(k), key); ok {
+		return v, PropType, k, true
+	}
+	return nil, PropOwn, nil, false
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goki/ki/kit"
+)
+
+type propsTestNode struct {
+	Node
+}
+
+var KiT_propsTestNode = DeclareProps(kit.Types.AddType(&propsTestNode{}, nil),
+	PropSpec{Key: "color", Type: reflect.TypeOf(""), Default: "black", Inherit: true},
+	PropSpec{Key: "size", Type: reflect.TypeOf(0), Default: 10},
+	PropSpec{Key: "timing", Enum: KiT_DestroyTimings},
+)
+
+type propsTestSub struct {
+	propsTestNode
+}
+
+var KiT_propsTestSub = DeclareProps(kit.Types.AddType(&propsTestSub{}, nil),
+	PropSpec{Key: "extra"},
+	PropSpec{Key: "size", Type: reflect.TypeOf(0.0), Default: 20.0},
+)
+
+// propsInvalidNode gets invalid specs declared in TestDeclarePropsInvalid
+type propsInvalidNode struct {
+	Node
+}
+
+var KiT_propsInvalidNode = kit.Types.AddType(&propsInvalidNode{}, nil)
+
+// propsOverrideNode overrides PropInherit to compute the color prop
+type propsOverrideNode struct {
+	Node
+}
+
+var KiT_propsOverrideNode = kit.Types.AddType(&propsOverrideNode{}, nil)
+
+func (n *propsOverrideNode) PropInherit(key string, inherit, typ bool) (any, bool) {
+	if key == "color" {
+		return "blue", true
+	}
+	return n.Node.PropInherit(key, inherit, typ)
+}
+
+func TestPropSchema(t *testing.T) {
+	specs := PropSpecs(KiT_propsTestSub)
+	if len(specs) != 4 || specs["size"].Default != 20.0 || specs["color"] == nil {
+		t.Errorf("sub type specs: %v", specs)
+	}
+
+	StrictProps = true
+	defer func() { StrictProps = false }()
+	par := &Node{}
+	par.InitName(par, "par")
+	par.SetProp("anything", 1) // no schema
+	kid := par.AddNewChild(KiT_propsTestNode, "kid")
+	kid.SetProp("colr", "red")
+	kid.SetProp("size", "12")
+	kid.SetProp("timing", "DestroyManual")
+	if kid.Prop("colr") != nil || kid.Prop("size") != 12 || kid.Prop("timing") != DestroyManual || par.Prop("anything") != 1 {
+		t.Errorf("strict props: %v", *kid.Properties())
+	}
+	kid.SetProp("timing", 1)
+	kid.SetProp("size", "big")
+	if kid.Prop("timing") != DestroyImmediate || kid.Prop("size") != 12 {
+		t.Errorf("strict props: %v", *kid.Properties())
+	}
+	kid.SetProp("timing", 7)
+	kid.AsNode().SetProps(Props{"size": 3.0, "nope": 1})
+	if kid.Prop("timing") != DestroyImmediate || kid.Prop("size") != 3 || kid.Prop("nope") != nil {
+		t.Errorf("strict props: %v", *kid.Properties())
+	}
+	if _, err := ValidateProp(kid, "color", 1); err != nil { // converted to "1"
+		t.Errorf("ValidateProp: %v", err)
+	}
+}
+
+func TestDeclarePropsInvalid(t *testing.T) {
+	err := DeclarePropsTry(KiT_propsInvalidNode,
+		PropSpec{Key: "bogus", Enum: reflect.TypeOf(0)}, // not an enum
+		PropSpec{Key: "size", Type: reflect.TypeOf(0), Default: "big"},
+		PropSpec{Key: "color", Type: reflect.TypeOf(""), Default: "black"},
+	)
+	if err == nil || !strings.Contains(err.Error(), "prop bogus: enum type int not registered") || !strings.Contains(err.Error(), "invalid default") {
+		t.Errorf("invalid specs should be reported: %v", err)
+	}
+	specs := PropSpecs(KiT_propsInvalidNode)
+	if len(specs) != 1 || specs["color"] == nil {
+		t.Errorf("only the valid spec should be declared: %v", specs)
+	}
+}
+
+func TestPropInheritSchema(t *testing.T) {
+	par := &Node{}
+	par.InitName(par, "par")
+	kid := par.AddNewChild(KiT_propsTestNode, "kid")
+	sub := kid.AddNewChild(KiT_propsTestSub, "sub")
+
+	if v, ok := sub.PropInherit("color", Inherit, TypeProps); !ok || v != "black" {
+		t.Errorf("default: %v %v", v, ok)
+	}
+	if _, ok := sub.PropInherit("color", Inherit, NoTypeProps); ok {
+		t.Errorf("default should only be used with typ")
+	}
+	par.SetProp("color", "red")
+	par.SetProp("size", 5)
+	if v, _ := sub.PropInherit("color", Inherit, TypeProps); v != "red" {
+		t.Errorf("inherited: %v", v)
+	}
+	if v, _ := sub.PropInherit("size", Inherit, TypeProps); v != 20.0 {
+		t.Errorf("size is not inherited: %v", v)
+	}
+	if v, _ := par.AddNewChild(KiT_Node, "plain").PropInherit("size", Inherit, TypeProps); v != 5 {
+		t.Errorf("undeclared props are inherited: %v", v)
+	}
+
+	sub.SetProp("extra", true)
+	eps := EffectiveProps(sub)
+	str := ""
+	for _, ep := range eps {
+		from := "nil"
+		if ep.From != nil {
+			from = ep.From.Name()
+		}
+		str += fmt.Sprintf("%v=%v %v %v; ", ep.Key, ep.Value, ep.Source, from)
+	}
+	exp := "color=red PropInherited par; extra=true PropOwn sub; size=20 PropDefault nil; "
+	if str != exp {
+		t.Errorf("effective props:\n%v\nexpected:\n%v", str, exp)
+	}
+
+	par.SetProp("anything", 1)
+	ovr := par.AddNewChild(KiT_propsOverrideNode, "ovr")
+	osub := ovr.AddNewChild(KiT_Node, "osub").AddNewChild(KiT_propsTestSub, "osub2")
+	if v, _ := osub.PropInherit("color", Inherit, TypeProps); v != "blue" {
+		t.Errorf("parent PropInherit override: %v", v)
+	}
+	if v, _ := osub.PropInherit("anything", Inherit, NoTypeProps); v != 1 {
+		t.Errorf("inherited through override: %v", v)
+	}
+	if eps := EffectiveProps(osub); len(eps) < 1 || eps[0].Value != "blue" || eps[0].Source != PropInherited || eps[0].From.Name() != "osub" {
+		t.Errorf("effective props through override: %v", eps)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/goki/ki/kit"
+)
+
+// Props values are saved to JSON with the type of the value recorded in a
+// separate key with the __type: prefix, as a type descriptor string, for
+// all values that would not otherwise be loaded back as the same type: the
+// JSON types string, float64 and bool, and Props maps, do not need one.
+// Descriptors use Go syntax for pointer, slice, array and map types, the
+// builtin names for basic types, any for the empty interface, and the
+// kit.Types or kit.Enums short names for named types, e.g.,
+// map[kit.TestFlags][]*ki.MyStruct.  Values of types that cannot be
+// described (e.g., unregistered named types) are saved without a type and
+// loaded as plain JSON values, as are values within containers of
+// interface type other than Props and PropSlice.
+
+// propBasicTypes are the unnamed types that can be used in prop type
+// descriptors, by name
+var propBasicTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, v := range []any{false, 0, int8(0), int16(0), int32(0), int64(0), uint(0), uint8(0), uint16(0), uint32(0), uint64(0), uintptr(0), float32(0), float64(0), ""} {
+		t := reflect.TypeOf(v)
+		propBasicTypes[t.Name()] = t
+	}
+	propBasicTypes["any"] = reflect.TypeOf((*any)(nil)).Elem()
+}
+
+// propNeedsType returns true if values of given type need a type
+// descriptor to be loaded back as the same type
+func propNeedsType(t reflect.Type) bool {
+	switch t {
+	case nil, propBasicTypes["string"], propBasicTypes["float64"], propBasicTypes["bool"], KiT_Props:
+		return false
+	}
+	return true
+}
+
+// PropTypeDesc returns the type descriptor used to save prop values of
+// given type, and false if the type cannot be described -- see
+// PropTypeFromDesc for the reverse
+func PropTypeDesc(t reflect.Type) (string, bool) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		ed, ok := PropTypeDesc(t.Elem())
+		return "*" + ed, ok
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if t.Name() != "" {
+			break
+		}
+		ed, ok := PropTypeDesc(t.Elem())
+		if !ok {
+			return "", false
+		}
+		switch t.Kind() {
+		case reflect.Slice:
+			return "[]" + ed, true
+		case reflect.Array:
+			return "[" + strconv.Itoa(t.Len()) + "]" + ed, true
+		}
+		kd, ok := PropTypeDesc(t.Key())
+		return "map[" + kd + "]" + ed, ok
+	case reflect.Interface:
+		if t.Name() == "" && t.NumMethod() == 0 {
+			return "any", true
+		}
+		return "", false
+	}
+	if t.Name() == "" {
+		return "", false
+	}
+	if t.PkgPath() == "" {
+		_, ok := propBasicTypes[t.Name()]
+		return t.Name(), ok
+	}
+	nm := kit.Types.TypeName(t)
+	if kit.Types.Type(nm) != t && kit.Enums.Enum(nm) != t {
+		return "", false
+	}
+	return nm, true
+}
+
+// PropTypeFromDesc returns the type for given type descriptor, as
+// returned by PropTypeDesc -- nil if it is not valid or uses types that
+// are not registered
+func PropTypeFromDesc(desc string) reflect.Type {
+	t, rest := propTypeParse(desc)
+	if rest != "" {
+		return nil
+	}
+	return t
+}
+
+// propTypeParse parses the type descriptor at the start of given string,
+// returning the type (nil if invalid) and the rest of the string
+func propTypeParse(desc string) (reflect.Type, string) {
+	switch {
+	case strings.HasPrefix(desc, "*"):
+		et, rest := propTypeParse(desc[1:])
+		if et == nil {
+			return nil, rest
+		}
+		return reflect.PtrTo(et), rest
+	case strings.HasPrefix(desc, "[]"):
+		et, rest := propTypeParse(desc[2:])
+		if et == nil {
+			return nil, rest
+		}
+		return reflect.SliceOf(et), rest
+	case strings.HasPrefix(desc, "["):
+		ri := strings.Index(desc, "]")
+		if ri < 0 {
+			return nil, ""
+		}
+		n, err := strconv.Atoi(desc[1:ri])
+		et, rest := propTypeParse(desc[ri+1:])
+		if err != nil || n < 0 || et == nil {
+			return nil, rest
+		}
+		return reflect.ArrayOf(n, et), rest
+	case strings.HasPrefix(desc, "map["):
+		kt, rest := propTypeParse(desc[4:])
+		if kt == nil || !strings.HasPrefix(rest, "]") {
+			return nil, rest
+		}
+		et, rest := propTypeParse(rest[1:])
+		if et == nil || !kt.Comparable() {
+			return nil, rest
+		}
+		return reflect.MapOf(kt, et), rest
+	}
+	ei := strings.IndexAny(desc, "[]*")
+	if ei < 0 {
+		ei = len(desc)
+	}
+	nm, rest := desc[:ei], desc[ei:]
+	if t, ok := propBasicTypes[nm]; ok {
+		return t, rest
+	}
+	if t := kit.Types.Type(nm); t != nil {
+		return t, rest
+	}
+	return kit.Enums.Enum(nm), rest
+}
@@ -27,7 +27,9 @@ const (
 	// accumulate the specific changes made since the last update signal --
 	// these flags are sent in the signal data -- strongly recommend using
 	// that instead of the flags, which can be subsequently updated by the
-	// time a signal is processed
+	// time a signal is processed -- if the PropUpdated flag is set, the keys
+	// of the updated properties are returned by the UpdatedProps method, and
+	// are sent in the NodeSignalPropsUpdated signal that follows
 	NodeSignalUpdated
 
 	// NodeSignalDeleting indicates that the node is being deleted from its
@@ -36,6 +38,22 @@ const (
 	// it will be destroyed unless you hear from it again.
 	NodeSignalDeleting
 
+	// NodeSignalChildrenMoved indicates that the children of the node were
+	// reordered, by SortChildren, SortChildrenBy, MoveChild or
+	// ReverseChildren -- the signal data is a []int permutation where
+	// element i is the previous index of the child now at index i.  Like
+	// NodeSignalDeleting, this is delivered immediately, and the ChildMoved
+	// flag is also set for the subsequent NodeSignalUpdated.
+	NodeSignalChildrenMoved
+
+	// NodeSignalPropsUpdated indicates that properties of the node were set
+	// or deleted, by SetProp, SetProps or DeleteProp -- the signal data is
+	// a []string of the keys of the changed properties.  It is sent right
+	// after the NodeSignalUpdated signal for the change, or, for changes
+	// made while the node is updating, after the NodeSignalUpdated signal
+	// at the end of the update, with all of the keys changed during it.
+	NodeSignalPropsUpdated
+
 	NodeSignalsN
 )
 
@@ -7,6 +7,7 @@ package ki
 import (
 	"fmt"
 	"reflect"
+	"sync"
 
 	"github.com/goki/ki/kit"
 )
@@ -150,13 +151,13 @@ func (sl *Slice) IndexOf(kid Ki, startIdx int) (int, bool) {
 // SliceIndexByName returns index of first element that has given name, false if
 // not found. See IndexOf for info on startIdx.
 func SliceIndexByName(sl *[]Ki, name string, startIdx int) (int, bool) {
-	return SliceIndexByFunc(sl, startIdx, func(ch Ki) bool { return ch.Name() == name })
+	return SliceIndexByFunc(sl, startIdx, func(ch Ki) bool { return ch.AsNode().Nm == name })
 }
 
 // IndexByName returns index of first element that has given name, false if
 // not found. See IndexOf for info on startIdx
 func (sl *Slice) IndexByName(name string, startIdx int) (int, bool) {
-	return sl.IndexByFunc(startIdx, func(ch Ki) bool { return ch.Name() == name })
+	return sl.IndexByFunc(startIdx, func(ch Ki) bool { return ch.AsNode().Nm == name })
 }
 
 // SliceIndexByType returns index of element that either is that type or embeds
@@ -241,6 +242,7 @@ func SliceInsert(sl *[]Ki, k Ki, idx int) {
 // Insert item at index -- does not do any parent updating etc -- use Ki/Node
 // method unless you know what you are doing.
 func (sl *Slice) Insert(k Ki, idx int) {
+	sl.resetNameIndex()
 	SliceInsert((*[]Ki)(sl), k, idx)
 }
 
@@ -263,6 +265,7 @@ func SliceDeleteAtIndex(sl *[]Ki, idx int) error {
 // deleted item -- optimized version for avoiding memory leaks.  returns error
 // if index is invalid.
 func (sl *Slice) DeleteAtIndex(idx int) error {
+	sl.resetNameIndex()
 	return SliceDeleteAtIndex((*[]Ki)(sl), idx)
 }
 
@@ -318,7 +321,7 @@ func (sl *Slice) TypeAndNames() kit.TypeAndNameList {
 	}
 	tn := make(kit.TypeAndNameList, len(*sl))
 	for _, kid := range *sl {
-		tn.Add(Type(kid), kid.Name())
+		tn.Add(Type(kid), kid.AsNode().Nm)
 	}
 	return tn
 }
@@ -333,6 +336,10 @@ func (sl *Slice) TypeAndNames() kit.TypeAndNameList {
 // values.
 func (sl *Slice) Config(n Ki, config kit.TypeAndNameList) (mods, updt bool) {
 	mods, updt = false, false
+	if n != nil {
+		n.AsNode().loadChildren()
+		n.AsNode().snapshotSave()
+	}
 	// first make a map for looking up the indexes of the names
 	nm := make(map[string]int)
 	for i, tn := range config {
@@ -342,7 +349,7 @@ func (sl *Slice) Config(n Ki, config kit.TypeAndNameList) (mods, updt bool) {
 	sz := len(*sl)
 	for i := sz - 1; i >= 0; i-- {
 		kid := (*sl)[i]
-		knm := kid.Name()
+		knm := kid.AsNode().Nm
 		ti, ok := nm[knm]
 		if !ok {
 			sl.configDeleteKid(kid, i, n, &mods, &updt)
@@ -358,7 +365,7 @@ func (sl *Slice) Config(n Ki, config kit.TypeAndNameList) (mods, updt bool) {
 			nkid := NewOfType(tn.Type)
 			nkid.SetName(tn.Name)
 			InitNode(nkid)
-			sl.Insert(nkid, i)
+			sl.lockedInsert(n, nkid, i)
 			if n != nil {
 				SetParent(nkid, n)
 				n.SetChildAdded()
@@ -366,14 +373,42 @@ func (sl *Slice) Config(n Ki, config kit.TypeAndNameList) (mods, updt bool) {
 		} else {
 			if kidx != i {
 				setMods(n, &mods, &updt)
+				mu := lockTreeOf(n)
 				sl.Move(kidx, i)
+				unlockTree(mu)
+				if n != nil {
+					n.SetFlag(int(ChildMoved))
+				}
 			}
 		}
 	}
-	DelMgr.DestroyDeleted()
+	treeDelMgr(n, false).destroyAtUpdateEnd()
 	return
 }
 
+// lockTreeOf acquires the write lock of the tree containing given node,
+// which can be nil, returning the mutex to pass to unlockTree.
+func lockTreeOf(n Ki) *sync.RWMutex {
+	if n == nil {
+		return nil
+	}
+	return n.AsNode().lockTree()
+}
+
+// lockedInsert inserts kid at given index, under the write lock of
+// given parent node if non-nil, passing on its tree mutex to the kid.
+func (sl *Slice) lockedInsert(n Ki, kid Ki, idx int) {
+	if n == nil {
+		sl.Insert(kid, idx)
+		return
+	}
+	nn := n.AsNode()
+	mu := nn.lockTree()
+	setTreeMu(kid.AsNode(), nn.treeMu)
+	sl.Insert(kid, idx)
+	unlockTree(mu)
+}
+
 func setMods(n Ki, mods *bool, updt *bool) {
 	if !*mods {
 		*mods = true
@@ -384,6 +419,7 @@ func setMods(n Ki, mods *bool, updt *bool) {
 }
 
 func (sl *Slice) configDeleteKid(kid Ki, i int, n Ki, mods, updt *bool) {
+	snapshotSaveTree(kid)
 	if !*mods {
 		*mods = true
 		if n != nil {
@@ -394,8 +430,13 @@ func (sl *Slice) configDeleteKid(kid Ki, i int, n Ki, mods, updt *bool) {
 	kid.SetFlag(int(NodeDeleted))
 	kid.NodeSignal().Emit(kid, int64(NodeSignalDeleting), nil)
 	SetParent(kid, nil)
-	DelMgr.Add(kid)
+	mu := lockTreeOf(n)
 	sl.DeleteAtIndex(i)
+	if n != nil {
+		n.AsNode().nameIdx = nil
+	}
+	unlockTree(mu)
+	treeDelMgr(n, true).Add(kid)
 	UpdateReset(kid) // it won't get the UpdateEnd from us anymore -- init fresh in any case
 }
 
@@ -421,7 +462,7 @@ func (sl *Slice) ConfigCopy(n Ki, frm Slice) {
 		cfg := make(kit.TypeAndNameList, sz)
 		for i, kid := range frm {
 			cfg[i].Type = Type(kid)
-			cfg[i].Name = kid.Name()
+			cfg[i].Name = kid.AsNode().Nm
 		}
 		mods, updt := sl.Config(n, cfg)
 		if mods && n != nil {
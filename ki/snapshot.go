@@ -0,0 +1,367 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/goki/ki/bitflag"
+)
+
+// Snapshot is a copy-on-write snapshot of a tree, which is much cheaper than
+// a full Clone for large trees that change a little at a time: taking the
+// snapshot does not copy anything, and the state of each node is only saved
+// just before it is first modified after the snapshot was taken (by the Node
+// methods that modify nodes, and by UpdateStart).  Nodes that are removed
+// from the tree are saved along with their entire subtree, as they could
+// then be modified by code that does not know about the snapshot.
+//
+// The View method returns a read-only view of the tree as of the time of
+// the snapshot, as a tree of Ki nodes of the same types as the original,
+// which supports all the normal traversal, path, property and field access
+// methods.  The view is built lazily: View only makes a shallow copy of the
+// root (from its saved state, or the unmodified live node), and the
+// children of each view node are only copied when first accessed, using
+// the ChildLoader mechanism, so the cost of a view is proportional to the
+// part of it that is actually used.  The snapshot keeps tracking changes
+// until all of the view has been built, or it is released.  Any attempt to
+// modify a view node via the Node methods panics.
+//
+// A snapshot that is no longer needed must be released by calling Release,
+// which is done automatically when all of the view has been built, or when
+// the root of the live tree is destroyed.  Any parts of the view that were
+// not yet built are then built all at once, so the view always reflects
+// the tree as of the time of the snapshot.
+//
+// Changes made directly to node fields (instead of via Node methods) are
+// captured if they are wrapped in UpdateStart / End on the node, or if
+// SnapshotSave is called on the node before modifying it.  Direct changes
+// to Kids (e.g., Kids.Move) are likewise only captured by calling
+// UpdateStart or SnapshotSave on the parent first.
+type Snapshot struct {
+
+	// root of the live tree that the snapshot was taken of
+	Root Ki
+
+	// saved state of nodes changed since the snapshot
+	saved map[*Node]*snapState
+
+	// the view, once built
+	view Ki
+
+	// view nodes whose children have not yet been built, with their live nodes
+	unbuilt map[*Node]*Node
+
+	mu sync.Mutex
+}
+
+// snapState is the saved state of a node
+type snapState struct {
+
+	// pointer to shallow copy of the node struct
+	val reflect.Value
+
+	// copy of the Kids slice, as that is modified in place
+	kids Slice
+
+	// copy of the Props map, as that is modified in place
+	props Props
+}
+
+// snapshots holds the active snapshots (that are still tracking changes),
+// by the root node they were taken of
+var snapshots = struct {
+	byRoot map[*Node][]*Snapshot
+	mu     sync.RWMutex
+}{byRoot: make(map[*Node][]*Snapshot)}
+
+// numSnapshots is the number of active snapshots, for a fast check
+var numSnapshots int32
+
+// NewSnapshot takes a copy-on-write snapshot of the tree under given
+// root node -- see Snapshot for details.  Call Release on the snapshot
+// when it is no longer needed, to stop tracking changes.
+func NewSnapshot(root Ki) *Snapshot {
+	sn := &Snapshot{Root: root, saved: make(map[*Node]*snapState), unbuilt: make(map[*Node]*Node)}
+	rn := root.AsNode()
+	snapshots.mu.Lock()
+	snapshots.byRoot[rn] = append(snapshots.byRoot[rn], sn)
+	snapshots.mu.Unlock()
+	atomic.AddInt32(&numSnapshots, 1)
+	return sn
+}
+
+// View returns the read-only view of the tree as of the time the snapshot
+// was taken, creating its root on the first call -- the rest of the view
+// is built as it is accessed.  Returns nil if the snapshot was released
+// before View was called.
+func (sn *Snapshot) View() Ki {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	if sn.view != nil || sn.saved == nil {
+		return sn.view
+	}
+	TreeRLock(sn.Root)
+	sn.view = sn.buildView(sn.Root, nil)
+	TreeRUnlock(sn.Root)
+	if len(sn.unbuilt) == 0 {
+		sn.release()
+	}
+	return sn.view
+}
+
+// Release stops tracking changes to the live tree and frees the saved
+// state, after building any parts of the view that were not yet built.
+// This is done automatically once all of the view has been built, and
+// when the root of the live tree is destroyed.
+func (sn *Snapshot) Release() {
+	sn.mu.Lock()
+	sn.release()
+	sn.mu.Unlock()
+}
+
+// NumSaved returns the number of nodes whose state has been saved
+// because they changed since the snapshot was taken.
+func (sn *Snapshot) NumSaved() int {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	return len(sn.saved)
+}
+
+// release does the release, under the lock
+func (sn *Snapshot) release() {
+	if sn.saved == nil {
+		return
+	}
+	if len(sn.unbuilt) > 0 {
+		TreeRLock(sn.Root)
+		for len(sn.unbuilt) > 0 {
+			for vn, ln := range sn.unbuilt {
+				sn.buildKids(vn.Ths, ln)
+			}
+		}
+		TreeRUnlock(sn.Root)
+	}
+	sn.saved = nil
+	rn := sn.Root.AsNode()
+	snapshots.mu.Lock()
+	snaps := snapshots.byRoot[rn]
+	for i, s := range snaps {
+		if s == sn {
+			snaps = append(snaps[:i], snaps[i+1:]...)
+			break
+		}
+	}
+	if len(snaps) == 0 {
+		delete(snapshots.byRoot, rn)
+	} else {
+		snapshots.byRoot[rn] = snaps
+	}
+	snapshots.mu.Unlock()
+	atomic.AddInt32(&numSnapshots, -1)
+}
+
+// releaseSnapshots releases all the snapshots taken of given root node --
+// called when it is destroyed
+func releaseSnapshots(n *Node) {
+	if atomic.LoadInt32(&numSnapshots) == 0 {
+		return
+	}
+	snapshots.mu.RLock()
+	snaps := append([]*Snapshot(nil), snapshots.byRoot[n]...)
+	snapshots.mu.RUnlock()
+	for _, sn := range snaps {
+		sn.Release()
+	}
+}
+
+// SnapshotSave saves the current state of given node in any active
+// snapshots of a tree containing it, that have not already saved it.
+// This must be called before modifying a node directly, instead of via
+// the Node methods or within UpdateStart / End.  Panics if the node is
+// part of a Snapshot view.
+func SnapshotSave(k Ki) {
+	k.AsNode().snapshotSave()
+}
+
+// snapshotSave is called at the start of all Node methods that modify it
+// -- see SnapshotSave
+func (n *Node) snapshotSave() {
+	if bitflag.HasAtomic(&n.Flag, int(SnapshotView)) {
+		panic(fmt.Sprintf("ki.Node %v: cannot modify a read-only Snapshot view node", n.Nm))
+	}
+	if atomic.LoadInt32(&numSnapshots) == 0 {
+		return
+	}
+	for _, sn := range activeSnapshots(n) {
+		sn.save(n)
+	}
+}
+
+// snapshotSaveTree saves the entire subtree under given node in any
+// active snapshots -- called before removing a node from its parent.
+func snapshotSaveTree(k Ki) {
+	if atomic.LoadInt32(&numSnapshots) == 0 {
+		return
+	}
+	snaps := activeSnapshots(k.AsNode())
+	if len(snaps) == 0 {
+		return
+	}
+	k.AsNode().funcDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		for _, sn := range snaps {
+			sn.save(k.AsNode())
+		}
+		return Continue
+	}, false)
+}
+
+// activeSnapshots returns the active snapshots of any tree containing
+// given node.
+func activeSnapshots(n *Node) []*Snapshot {
+	var snaps []*Snapshot
+	snapshots.mu.RLock()
+	defer snapshots.mu.RUnlock()
+	var k Ki = n
+	for k != nil {
+		kn := k.AsNode()
+		snaps = append(snaps, snapshots.byRoot[kn]...)
+		k = kn.Parent()
+	}
+	return snaps
+}
+
+// save saves the state of given node if not already saved
+func (sn *Snapshot) save(n *Node) {
+	k := n.This()
+	if k == nil {
+		return
+	}
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	if sn.saved == nil {
+		return
+	}
+	if _, has := sn.saved[n]; has {
+		return
+	}
+	mu := n.rlockTree()
+	st := &snapState{val: reflect.New(reflect.TypeOf(k).Elem())}
+	st.val.Elem().Set(reflect.ValueOf(k).Elem())
+	st.kids = append(Slice(nil), n.Kids...)
+	st.props = copyPropsShallow(n.Props)
+	runlockTree(mu)
+	sn.saved[n] = st
+}
+
+// buildView returns the view node for given live node, using its saved
+// state if it has one, and otherwise its current state.  Its children are
+// built when first accessed, by viewLoader, or when the snapshot is
+// released.
+func (sn *Snapshot) buildView(live Ki, par Ki) Ki {
+	ln := live.AsNode()
+	var src reflect.Value
+	kids, props := ln.Kids, ln.Props
+	if st, has := sn.saved[ln]; has {
+		src = st.val.Elem()
+		kids, props = st.kids, st.props
+	} else {
+		src = reflect.ValueOf(live).Elem()
+	}
+	nv := reflect.New(src.Type())
+	nv.Elem().Set(src)
+	vk := nv.Interface().(Ki)
+	sn.finishView(vk, ln, par, kids, props)
+	return vk
+}
+
+// finishView sets up given shallow copy view of given live node, with given
+// parent view node, children and props.  The live node may have been
+// destroyed, so its type info is obtained from the view node.
+func (sn *Snapshot) finishView(vk Ki, ln *Node, par Ki, kids Slice, props Props) {
+	vn := vk.AsNode()
+	vn.Ths = vk
+	vn.Par = par
+	clearNodeState(vn)
+	bitflag.ClearMask(&vn.Flag, int64(UpdateFlagsMask))
+	bitflag.Clear(&vn.Flag, int(Updating), int(ChildrenUnloaded))
+	bitflag.Set(&vn.Flag, int(SnapshotView))
+	vn.Props = copyPropsShallow(props)
+	if KiHasKiFields(vn) {
+		val := reflect.ValueOf(vk).Elem()
+		foffs := KiFieldOffs(vn)
+		for i, fnm := range KiFieldNames(vn) {
+			lfn := kiFieldNode(ln, foffs[i])
+			fkids, fprops := lfn.Kids, lfn.Props
+			fv := val.FieldByName(fnm)
+			if st, has := sn.saved[lfn]; has {
+				fv.Set(st.val.Elem())
+				fkids, fprops = st.kids, st.props
+			}
+			sn.finishView(fv.Addr().Interface().(Ki), lfn, vk, fkids, fprops)
+		}
+	}
+	vn.Kids = nil
+	if len(kids) > 0 {
+		vn.loader = sn.viewLoader
+		bitflag.Set(&vn.Flag, int(ChildrenUnloaded))
+		sn.unbuilt[vn] = ln
+	}
+}
+
+// viewLoader is the ChildLoader of the view nodes, which builds their
+// children if they were not already built on release, and releases the
+// snapshot once the whole view is built.
+func (sn *Snapshot) viewLoader(vk Ki) error {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	vn := vk.AsNode()
+	ln, has := sn.unbuilt[vn]
+	if !has {
+		return nil
+	}
+	TreeRLock(sn.Root)
+	sn.buildKids(vk, ln)
+	TreeRUnlock(sn.Root)
+	if len(sn.unbuilt) == 0 {
+		sn.release()
+	}
+	return nil
+}
+
+// buildKids builds the children of given view node from the saved children
+// of given live node, or its current children if it has not been saved,
+// and marks them as loaded.
+func (sn *Snapshot) buildKids(vk Ki, ln *Node) {
+	kids := ln.Kids
+	if st, has := sn.saved[ln]; has {
+		kids = st.kids
+	}
+	vn := vk.AsNode()
+	vkids := make(Slice, 0, len(kids))
+	for _, kid := range kids {
+		if kid != nil {
+			vkids = append(vkids, sn.buildView(kid, vk))
+		}
+	}
+	vn.Kids = vkids
+	delete(sn.unbuilt, vn)
+	bitflag.ClearAtomic(&vn.Flag, int(ChildrenUnloaded))
+}
+
+// copyPropsShallow returns a shallow copy of given props, nil if nil
+func copyPropsShallow(pr Props) Props {
+	if pr == nil {
+		return nil
+	}
+	cp := make(Props, len(pr))
+	for k, v := range pr {
+		cp[k] = v
+	}
+	return cp
+}
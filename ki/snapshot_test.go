@@ -0,0 +1,217 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"testing"
+)
+
+func snapshotTestTree() *NodeField {
+	parent := &NodeField{}
+	parent.InitName(parent, "par1")
+	child1 := parent.AddNewChild(KiT_NodeField, "child1")
+	child2 := parent.AddNewChild(KiT_NodeEmbed, "child2")
+	parent.AddNewChild(KiT_NodeEmbed, "child3")
+	child1.AddNewChild(KiT_NodeEmbed, "subchild1")
+	child2.AddNewChild(KiT_NodeEmbed, "subchild2")
+	child2.SetProp("prop", 1)
+	parent.Field1.Mbr1 = "field1"
+	return parent
+}
+
+func TestSnapshot(t *testing.T) {
+	parent := snapshotTestTree()
+	child1 := parent.Child(0)
+	child2 := parent.Child(1).(*NodeEmbed)
+	sub2 := child2.Child(0)
+	origPaths := []string{}
+	parent.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		origPaths = append(origPaths, k.Path())
+		return Continue
+	})
+
+	sn := NewSnapshot(parent)
+	if sn.NumSaved() != 0 {
+		t.Errorf("new snapshot should not save anything")
+	}
+
+	// modify the live tree in various ways
+	child2.SetName("renamed")
+	child2.SetProp("prop", 2)
+	child2.SetField("Mbr2", 42)
+	sub2.SetProp("subprop", "x")
+	parent.Field1.SetField("Mbr1", "changed")
+	parent.DeleteChild(child1, DestroyKids)
+	parent.AddNewChild(KiT_NodeEmbed, "child4")
+	updt := parent.UpdateStart()
+	parent.Kids.Swap(0, 1)
+	parent.UpdateEnd(updt)
+	DeleteManager(parent).DestroyDeleted()
+
+	if sn.NumSaved() == 0 || sn.NumSaved() > 8 {
+		t.Errorf("unexpected number of saved nodes: %d", sn.NumSaved())
+	}
+	untouched := parent.ChildByName("child3", 0)
+	vw := sn.View()
+	if vw == nil {
+		t.Fatal("nil snapshot view")
+	}
+	if vw == Ki(parent) || vw.Child(1) == Ki(child2) {
+		t.Errorf("view should not contain live nodes")
+	}
+	viewPaths := []string{}
+	vw.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		viewPaths = append(viewPaths, k.Path())
+		return Continue
+	})
+	if len(viewPaths) != len(origPaths) {
+		t.Fatalf("view paths: %v != orig: %v", viewPaths, origPaths)
+	}
+	for i := range viewPaths {
+		if viewPaths[i] != origPaths[i] {
+			t.Errorf("view path: %v != orig: %v", viewPaths[i], origPaths[i])
+		}
+	}
+	vc2 := vw.FindPath("/par1/child2")
+	if vc2 == nil {
+		t.Fatal("FindPath failed in view")
+	}
+	if vc2.Prop("prop") != 1 || vc2.(*NodeEmbed).Mbr2 != 0 {
+		t.Errorf("view child2 state changed: %v %v", vc2.Prop("prop"), vc2.(*NodeEmbed).Mbr2)
+	}
+	if vs2 := vc2.Child(0); vs2.Prop("subprop") != nil || vs2.Parent() != vc2 {
+		t.Errorf("view subchild2 state changed")
+	}
+	if vw.(*NodeField).Field1.Mbr1 != "field1" || vw.(*NodeField).Field1.Parent() != vw {
+		t.Errorf("view field changed: %v", vw.(*NodeField).Field1.Mbr1)
+	}
+	if vw.ChildByName("child1", 0).NumChildren() != 1 {
+		t.Errorf("view deleted child1 should retain its children")
+	}
+	if vc3 := vw.ChildByName("child3", 0); vc3 == nil || vc3 == untouched {
+		t.Errorf("view should have a copy of untouched child3")
+	}
+
+	// live tree is as modified
+	if child2.Prop("prop") != 2 || child2.Mbr2 != 42 || parent.Field1.Mbr1 != "changed" {
+		t.Errorf("live tree not modified")
+	}
+	if parent.NumChildren() != 3 || parent.Child(0).Name() != "child3" {
+		t.Errorf("live tree children not modified")
+	}
+
+	// view is read-only and no longer tracking
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("modifying view should panic")
+			}
+		}()
+		vc2.SetProp("prop", 3)
+	}()
+	child2.SetProp("prop", 5)
+	if vc2.Prop("prop") != 1 || sn.NumSaved() != 0 {
+		t.Errorf("view should be independent of live tree after View")
+	}
+	sn2 := NewSnapshot(parent)
+	if sn2.View().Child(0).Name() != "child3" {
+		t.Errorf("new snapshot view should reflect current tree")
+	}
+	sn2.Release()
+}
+
+func TestSnapshotLazyView(t *testing.T) {
+	parent := snapshotTestTree()
+	child2 := parent.Child(1)
+	sn := NewSnapshot(parent)
+	vw := sn.View()
+	if len(vw.AsNode().Kids) != 0 || ChildrenLoaded(vw) {
+		t.Errorf("view children should not be built until accessed")
+	}
+
+	// changes after View but before the view is built are not seen
+	child2.SetProp("prop", 2)
+	child2.Child(0).SetName("renamed")
+	parent.AddNewChild(KiT_NodeEmbed, "child4")
+	if sn.NumSaved() != 3 {
+		t.Errorf("snapshot should still track changes: %d", sn.NumSaved())
+	}
+	if vw.NumChildren() != 3 {
+		t.Errorf("view should have the original children: %d", vw.NumChildren())
+	}
+	vc2 := vw.Child(1)
+	if vc2.Prop("prop") != 1 || vc2.Child(0).Name() != "subchild2" {
+		t.Errorf("view child2 state changed: %v %v", vc2.Prop("prop"), vc2.Child(0).Name())
+	}
+	if sn.NumSaved() == 0 {
+		t.Errorf("snapshot should track changes until the whole view is built")
+	}
+	vw.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+		return Continue
+	})
+	if sn.NumSaved() != 0 || numSnapshots != 0 {
+		t.Errorf("snapshot should be released once the whole view is built")
+	}
+}
+
+func TestSnapshotDestroy(t *testing.T) {
+	parent := snapshotTestTree()
+	sn := NewSnapshot(parent)
+	parent.Child(0).SetProp("prop", 2)
+	parent.Destroy()
+	if sn.View() != nil || numSnapshots != 0 {
+		t.Errorf("snapshot should be released when its root is destroyed")
+	}
+}
+
+func TestSnapshotRelease(t *testing.T) {
+	parent := snapshotTestTree()
+	sn := NewSnapshot(parent.Child(1))
+	parent.Child(1).SetProp("prop", 2)
+	parent.Child(0).SetProp("prop", 2) // outside of snapshot
+	if sn.NumSaved() != 1 {
+		t.Errorf("snapshot of subtree should save 1 node, saved: %d", sn.NumSaved())
+	}
+	sn.Release()
+	if sn.View() != nil || numSnapshots != 0 {
+		t.Errorf("released snapshot should have no view")
+	}
+}
+
+func TestSnapshotReleaseView(t *testing.T) {
+	for _, destroy := range []bool{false, true} {
+		parent := snapshotTestTree()
+		child2 := parent.Child(1)
+		child2.Child(0).SetProp("x", 1)
+		sn := NewSnapshot(parent)
+		vw := sn.View()
+
+		// live changes, then release before the view is built
+		child2.AddNewChild(KiT_NodeEmbed, "subchild3")
+		child2.Child(0).SetProp("x", 2)
+		parent.Child(0).Child(0).SetName("renamed")
+		if destroy {
+			parent.Destroy()
+		} else {
+			sn.Release()
+		}
+		if numSnapshots != 0 {
+			t.Errorf("snapshot should be released")
+		}
+		var paths []string
+		vw.FuncDownMeFirst(0, nil, func(k Ki, level int, d any) bool {
+			paths = append(paths, k.Path())
+			return Continue
+		})
+		exp := "[/par1 /par1.Field1 /par1/child1 /par1/child1.Field1 /par1/child1/subchild1 /par1/child2 /par1/child2/subchild2 /par1/child3]"
+		if fmt.Sprint(paths) != exp {
+			t.Errorf("view after release (destroy: %v):\n%v\nexpected:\n%v", destroy, paths, exp)
+		}
+		if x := vw.Child(1).Child(0).Prop("x"); x != 1 {
+			t.Errorf("view after release (destroy: %v) shows live prop: %v", destroy, x)
+		}
+	}
+}
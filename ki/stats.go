@@ -0,0 +1,424 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unsafe"
+
+	"github.com/goki/ki/kit"
+)
+
+// TreeStats are statistics about a tree, returned by Stats, for finding
+// the sources of memory growth and the like.  Sizes in bytes are
+// approximate: they include the node structs and the memory they refer to
+// (strings, slices, maps, pointers to non-Ki values), counting shared
+// memory only once, but not the Go runtime overhead of maps etc.  The
+// statistics of the subtree under any node are available from Subtree.
+type TreeStats struct {
+
+	// total number of nodes, including Ki fields
+	Nodes int `json:"nodes" desc:"total number of nodes, including Ki fields"`
+
+	// number of nodes without children
+	Leaves int `json:"leaves" desc:"number of nodes without children"`
+
+	// number of nodes with children that are not loaded yet -- see SetChildLoader
+	Unloaded int `json:"unloaded" desc:"number of nodes with children that are not loaded yet -- see SetChildLoader"`
+
+	// number of nodes by short type name
+	Types map[string]int `json:"types" desc:"number of nodes by short type name"`
+
+	// number of nodes at each depth below the root
+	Depths []int `json:"depths" desc:"number of nodes at each depth below the root"`
+
+	// maximum number of children of any node
+	MaxFanOut int `json:"max_fan_out" desc:"maximum number of children of any node"`
+
+	// path of the (first) node with the maximum number of children
+	MaxFanOutPath string `json:"max_fan_out_path" desc:"path of the (first) node with the maximum number of children"`
+
+	// total number of Props entries
+	Props int `json:"props" desc:"total number of Props entries"`
+
+	// number of Props entries by key
+	PropKeys map[string]int `json:"prop_keys" desc:"number of Props entries by key"`
+
+	// approximate size of the Props maps and their values, in bytes
+	PropsBytes int64 `json:"props_bytes" desc:"approximate size of the Props maps and their values, in bytes"`
+
+	// total number of signal connections by Signal field name, e.g., NodeSig
+	SignalCons map[string]int `json:"signal_cons" desc:"total number of signal connections by Signal field name, e.g., NodeSig"`
+
+	// maximum number of connections of any one Signal
+	MaxSignalCons int `json:"max_signal_cons" desc:"maximum number of connections of any one Signal"`
+
+	// path and field name of the (first) Signal with the maximum number of connections
+	MaxSignalConsPath string `json:"max_signal_cons_path" desc:"path and field name of the (first) Signal with the maximum number of connections"`
+
+	// approximate retained size of the whole tree, in bytes
+	Bytes int64 `json:"bytes" desc:"approximate retained size of the whole tree, in bytes"`
+
+	// statistics for the subtrees under each child of the root, largest first -- see Subtree for any other node
+	Subtrees []SubtreeStats `json:"subtrees" desc:"statistics for the subtrees under each child of the root, largest first -- see Subtree for any other node"`
+
+	// number of nodes and size of the subtree under each node in the tree
+	subtrees map[Ki]SubtreeStats
+}
+
+// SubtreeStats are the statistics for one subtree in TreeStats
+type SubtreeStats struct {
+
+	// path of the subtree root
+	Path string `json:"path" desc:"path of the subtree root"`
+
+	// short type name of the subtree root
+	Type string `json:"type" desc:"short type name of the subtree root"`
+
+	// number of nodes in the subtree
+	Nodes int `json:"nodes" desc:"number of nodes in the subtree"`
+
+	// approximate retained size of the subtree, in bytes
+	Bytes int64 `json:"bytes" desc:"approximate retained size of the subtree, in bytes"`
+}
+
+// Stats returns statistics about the tree under root: node counts by type,
+// a depth histogram, the maximum fan-out, Props entry counts and sizes,
+// signal connection counts and the approximate retained size of the tree
+// and of the subtree under each node.  Children are not loaded by Stats.
+func Stats(root Ki) *TreeStats {
+	sc := newStatsCounter()
+	ts := sc.ts
+	nodes, bytes := sc.node(root, 0, false)
+	ts.Bytes = bytes
+	if nodes > 0 {
+		nk := numKids(root, false)
+		for i := 0; i < nk; i++ {
+			kid, err := childTry(root, false, i)
+			if err != nil || kid == nil {
+				continue
+			}
+			if st, ok := ts.Subtree(kid); ok {
+				ts.Subtrees = append(ts.Subtrees, st)
+			}
+		}
+		sort.SliceStable(ts.Subtrees, func(i, j int) bool {
+			return ts.Subtrees[i].Bytes > ts.Subtrees[j].Bytes
+		})
+	}
+	return ts
+}
+
+// Subtree returns the statistics for the subtree under given node, which
+// can be any node in the tree, including Ki fields, or false if it is not
+// in the tree.  Memory shared with nodes that come before it in the tree
+// is only counted in those nodes, as in the tree totals -- RetainedBytes
+// counts all of the memory of a subtree on its own.
+func (ts *TreeStats) Subtree(k Ki) (SubtreeStats, bool) {
+	st, ok := ts.subtrees[k]
+	if !ok {
+		return st, false
+	}
+	st.Path = k.Path()
+	st.Type = kit.ShortTypeName(Type(k))
+	return st, true
+}
+
+// RetainedBytes returns the approximate retained size of the subtree under
+// given node, in bytes -- see TreeStats
+func RetainedBytes(k Ki) int64 {
+	sc := newStatsCounter()
+	_, bytes := sc.node(k, 0, false)
+	return bytes
+}
+
+// statsCounter holds the state of Stats
+type statsCounter struct {
+	ts *TreeStats
+
+	// addresses of memory already counted
+	seen map[uintptr]bool
+}
+
+func newStatsCounter() *statsCounter {
+	ts := &TreeStats{Types: make(map[string]int), PropKeys: make(map[string]int), SignalCons: make(map[string]int), subtrees: make(map[Ki]SubtreeStats)}
+	return &statsCounter{ts: ts, seen: make(map[uintptr]bool)}
+}
+
+// node adds the stats of the subtree under given node at given depth, and
+// returns its number of nodes and size -- field is true for Ki fields,
+// whose struct is part of their owner
+func (sc *statsCounter) node(k Ki, depth int, field bool) (int, int64) {
+	if k == nil || k.This() == nil {
+		return 0, 0
+	}
+	ts := sc.ts
+	n := k.AsNode()
+	ts.Nodes++
+	ts.Types[kit.ShortTypeName(Type(k))]++
+	for len(ts.Depths) <= depth {
+		ts.Depths = append(ts.Depths, 0)
+	}
+	ts.Depths[depth]++
+	nk := numKids(k, false)
+	if nk == 0 {
+		if n.HasFlag(int(ChildrenUnloaded)) {
+			ts.Unloaded++
+		} else {
+			ts.Leaves++
+		}
+	}
+	if nk > ts.MaxFanOut {
+		ts.MaxFanOut = nk
+		ts.MaxFanOutPath = k.Path()
+	}
+
+	// signals
+	for _, sf := range signalFields(k) {
+		sf.sig.Mu.RLock()
+		ncons := len(sf.sig.Cons)
+		sf.sig.Mu.RUnlock()
+		if ncons == 0 {
+			continue
+		}
+		ts.SignalCons[sf.name] += ncons
+		if ncons > ts.MaxSignalCons {
+			ts.MaxSignalCons = ncons
+			ts.MaxSignalConsPath = k.Path() + " " + sf.name
+		}
+	}
+
+	// props
+	var propsBytes int64
+	mu := n.rlockTree()
+	props := n.Props
+	for key, val := range props {
+		ts.Props++
+		ts.PropKeys[key]++
+		propsBytes += sc.value(reflect.ValueOf(&key).Elem())
+		propsBytes += sc.value(reflect.ValueOf(&val).Elem())
+	}
+	runlockTree(mu)
+	ts.PropsBytes += propsBytes
+
+	// the node struct and what it refers to, other than props and Ki
+	var bytes int64
+	v := reflect.ValueOf(k).Elem()
+	if !field {
+		bytes += int64(v.Type().Size())
+	}
+	bytes += sc.structExtra(v) + propsBytes
+
+	nodes := 1
+	for i := 0; i < NumKiFields(n); i++ {
+		fn, fb := sc.node(KiField(n, i), depth+1, true)
+		nodes += fn
+		bytes += fb
+	}
+	for i := 0; i < nk; i++ {
+		kid, err := childTry(k, false, i)
+		if err != nil || kid == nil {
+			continue
+		}
+		kn, kb := sc.node(kid, depth+1, false)
+		nodes += kn
+		bytes += kb
+	}
+	ts.subtrees[k] = SubtreeStats{Nodes: nodes, Bytes: bytes}
+	return nodes, bytes
+}
+
+// structExtra returns the size of the memory referred to by the fields of
+// given struct value, excluding Ki fields and pointers, Props and signal
+// receiver functions, which are counted separately
+func (sc *statsCounter) structExtra(v reflect.Value) int64 {
+	var bytes int64
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		fv := v.Field(i)
+		switch {
+		case f.Type == KiT_Signal:
+			sig := (*Signal)(unsafe.Pointer(fv.UnsafeAddr()))
+			sig.Mu.RLock()
+			bytes += int64(len(sig.Cons)) * int64(KiType.Size()+reflect.TypeOf(RecvFunc(nil)).Size())
+			sig.Mu.RUnlock()
+		case f.Type == KiT_Props:
+		case f.Anonymous && f.Type.Kind() == reflect.Struct:
+			bytes += sc.structExtra(fv)
+		case f.Type.Kind() == reflect.Struct && reflect.PtrTo(f.Type).Implements(KiType):
+			// Ki fields are counted as nodes
+		default:
+			bytes += sc.extra(fv)
+		}
+	}
+	return bytes
+}
+
+// value returns the size of given value, including the memory it refers to
+func (sc *statsCounter) value(v reflect.Value) int64 {
+	return int64(v.Type().Size()) + sc.extra(v)
+}
+
+// extra returns the size of the memory referred to by given value, not
+// including the value itself -- Ki pointers are not followed, and each
+// block of memory is only counted once
+func (sc *statsCounter) extra(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() == 0 {
+			return 0
+		}
+		return int64(v.Len())
+	case reflect.Slice:
+		if v.IsNil() || !sc.first(v.Pointer()) {
+			return 0
+		}
+		bytes := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		if statsHasExtra(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				bytes += sc.extra(v.Index(i))
+			}
+		}
+		return bytes
+	case reflect.Array:
+		var bytes int64
+		if statsHasExtra(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				bytes += sc.extra(v.Index(i))
+			}
+		}
+		return bytes
+	case reflect.Map:
+		if v.IsNil() || !sc.first(v.Pointer()) {
+			return 0
+		}
+		bytes := int64(v.Len()) * int64(v.Type().Key().Size()+v.Type().Elem().Size())
+		if statsHasExtra(v.Type().Key()) || statsHasExtra(v.Type().Elem()) {
+			it := v.MapRange()
+			for it.Next() {
+				bytes += sc.extra(it.Key()) + sc.extra(it.Value())
+			}
+		}
+		return bytes
+	case reflect.Ptr:
+		if v.IsNil() || v.Type().Implements(KiType) || !sc.first(v.Pointer()) {
+			return 0
+		}
+		return sc.value(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		ev := v.Elem()
+		if ev.Type().Implements(KiType) {
+			return 0
+		}
+		if ev.Kind() == reflect.Ptr || ev.Kind() == reflect.Map || ev.Kind() == reflect.Slice {
+			return sc.extra(ev)
+		}
+		return sc.value(ev) // boxed values are stored separately
+	case reflect.Struct:
+		var bytes int64
+		for i := 0; i < v.NumField(); i++ {
+			bytes += sc.extra(v.Field(i))
+		}
+		return bytes
+	}
+	return 0
+}
+
+// first returns true if given address has not been counted yet, and
+// records it as counted
+func (sc *statsCounter) first(addr uintptr) bool {
+	if sc.seen[addr] {
+		return false
+	}
+	sc.seen[addr] = true
+	return true
+}
+
+// statsHasExtra returns true if values of given type can refer to other
+// memory
+func statsHasExtra(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Ptr, reflect.Interface, reflect.Struct, reflect.Array:
+		return true
+	}
+	return false
+}
+
+// Table returns the stats formatted as aligned text tables
+func (ts *TreeStats) Table() string {
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "nodes\t%d\n", ts.Nodes)
+	fmt.Fprintf(tw, "leaves\t%d\n", ts.Leaves)
+	if ts.Unloaded > 0 {
+		fmt.Fprintf(tw, "unloaded\t%d\n", ts.Unloaded)
+	}
+	fmt.Fprintf(tw, "max depth\t%d\n", len(ts.Depths)-1)
+	fmt.Fprintf(tw, "max fan-out\t%d\t%v\n", ts.MaxFanOut, ts.MaxFanOutPath)
+	fmt.Fprintf(tw, "props\t%d\t%d bytes\n", ts.Props, ts.PropsBytes)
+	if ts.MaxSignalCons > 0 {
+		fmt.Fprintf(tw, "max signal cons\t%d\t%v\n", ts.MaxSignalCons, ts.MaxSignalConsPath)
+	}
+	fmt.Fprintf(tw, "bytes\t%d\n", ts.Bytes)
+
+	fmt.Fprintf(tw, "\ntype\tnodes\n")
+	for _, tn := range statsSortedKeys(ts.Types) {
+		fmt.Fprintf(tw, "%v\t%d\n", tn, ts.Types[tn])
+	}
+	fmt.Fprintf(tw, "\ndepth\tnodes\n")
+	for d, nn := range ts.Depths {
+		fmt.Fprintf(tw, "%d\t%d\n", d, nn)
+	}
+	if len(ts.PropKeys) > 0 {
+		fmt.Fprintf(tw, "\nprop\tentries\n")
+		for _, key := range statsSortedKeys(ts.PropKeys) {
+			fmt.Fprintf(tw, "%v\t%d\n", key, ts.PropKeys[key])
+		}
+	}
+	if len(ts.SignalCons) > 0 {
+		fmt.Fprintf(tw, "\nsignal\tcons\n")
+		for _, snm := range statsSortedKeys(ts.SignalCons) {
+			fmt.Fprintf(tw, "%v\t%d\n", snm, ts.SignalCons[snm])
+		}
+	}
+	if len(ts.Subtrees) > 0 {
+		fmt.Fprintf(tw, "\nsubtree\ttype\tnodes\tbytes\n")
+		for _, st := range ts.Subtrees {
+			fmt.Fprintf(tw, "%v\t%v\t%d\t%d\n", st.Path, st.Type, st.Nodes, st.Bytes)
+		}
+	}
+	tw.Flush()
+	return sb.String()
+}
+
+// JSON returns the stats as indented JSON
+func (ts *TreeStats) JSON() ([]byte, error) {
+	return json.MarshalIndent(ts, "", "  ")
+}
+
+// statsSortedKeys returns the keys of given counts, largest count first,
+// and then in name order
+func statsSortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if m[keys[i]] != m[keys[j]] {
+			return m[keys[i]] > m[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	root := &NodeField{}
+	root.InitName(root, "root")
+	big := root.AddNewChild(KiT_NodeEmbed, "big").(*NodeEmbed)
+	for i := 0; i < 5; i++ {
+		kid := big.AddNewChild(KiT_Node, fmt.Sprintf("kid%d", i))
+		kid.SetProp("idx", i)
+	}
+	big.Mbr1 = strings.Repeat("x", 10000)
+	small := root.AddNewChild(KiT_Node, "small")
+	small.SetProp("name", "small")
+	recv := Node{}
+	recv.InitName(&recv, "recv")
+	big.NodeSignal().Connect(&recv, func(r, s Ki, sig int64, d any) {})
+	big.Child(0).NodeSignal().Connect(&recv, func(r, s Ki, sig int64, d any) {})
+
+	ts := Stats(root)
+	if ts.Nodes != 9 || ts.Leaves != 7 || ts.Types["ki.Node"] != 6 || ts.Types["ki.NodeEmbed"] != 2 {
+		t.Errorf("node counts: %v %v %v", ts.Nodes, ts.Leaves, ts.Types)
+	}
+	if fmt.Sprint(ts.Depths) != "[1 3 5]" {
+		t.Errorf("depths: %v", ts.Depths)
+	}
+	if ts.MaxFanOut != 5 || ts.MaxFanOutPath != "/root/big" {
+		t.Errorf("fan out: %v %v", ts.MaxFanOut, ts.MaxFanOutPath)
+	}
+	if ts.Props != 6 || ts.PropKeys["idx"] != 5 || ts.PropsBytes <= 0 {
+		t.Errorf("props: %v %v %v", ts.Props, ts.PropKeys, ts.PropsBytes)
+	}
+	if ts.SignalCons["NodeSig"] != 2 || ts.MaxSignalCons != 1 || ts.MaxSignalConsPath != "/root/big NodeSig" {
+		t.Errorf("signals: %v %v %v", ts.SignalCons, ts.MaxSignalCons, ts.MaxSignalConsPath)
+	}
+	if len(ts.Subtrees) != 2 || ts.Subtrees[0].Path != "/root/big" || ts.Subtrees[0].Nodes != 6 || ts.Subtrees[0].Bytes < 10000 {
+		t.Errorf("subtrees: %v", ts.Subtrees)
+	}
+	if ts.Bytes != RetainedBytes(root) || ts.Bytes <= ts.Subtrees[0].Bytes+ts.Subtrees[1].Bytes {
+		t.Errorf("bytes: %v %v", ts.Bytes, ts.Subtrees)
+	}
+	if RetainedBytes(big) != ts.Subtrees[0].Bytes {
+		t.Errorf("subtree bytes: %v %v", RetainedBytes(big), ts.Subtrees[0].Bytes)
+	}
+	kid, _ := ts.Subtree(big.Child(1))
+	if kid.Path != "/root/big/kid1" || kid.Nodes != 1 || kid.Bytes != RetainedBytes(big.Child(1)) {
+		t.Errorf("subtree of deeper node: %v", kid)
+	}
+	fld, _ := ts.Subtree(&root.Field1)
+	rst, _ := ts.Subtree(root)
+	if fld.Nodes != 1 || fld.Bytes <= 0 || rst.Nodes != 9 || rst.Bytes != ts.Bytes {
+		t.Errorf("subtree of field / root: %v %v", fld, rst)
+	}
+	if _, ok := ts.Subtree(&recv); ok {
+		t.Errorf("subtree of node outside the tree")
+	}
+
+	tbl := ts.Table()
+	for _, s := range []string{"nodes            9\n", "ki.Node       6\n", "/root/big    ki.NodeEmbed  6"} {
+		if !strings.Contains(tbl, s) {
+			t.Errorf("table missing %q:\n%v", s, tbl)
+		}
+	}
+	b, err := ts.JSON()
+	if err != nil {
+		t.Error(err)
+	}
+	var rt TreeStats
+	if err := json.Unmarshal(b, &rt); err != nil || rt.Nodes != 9 || rt.Types["ki.Node"] != 6 {
+		t.Errorf("json: %v %v", err, string(b))
+	}
+}
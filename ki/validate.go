@@ -0,0 +1,332 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/goki/ki/bitflag"
+	"github.com/goki/ki/kit"
+)
+
+// ViolationTypes are the different kinds of tree integrity violations
+// that can be found by Validate.
+type ViolationTypes int32
+
+//go:generate stringer -type=ViolationTypes
+
+var KiT_ViolationTypes = kit.Enums.AddEnum(ViolationTypesN, kit.NotBitFlag, nil)
+
+const (
+	// ViolationNilChild means a nil entry was found in the Kids of a node.
+	ViolationNilChild ViolationTypes = iota
+
+	// ViolationNilThis means a node has a nil This pointer -- it was never
+	// initialized or added properly.
+	ViolationNilThis
+
+	// ViolationWrongParent means the Par pointer of a child or Ki field does
+	// not point to the node that contains it.
+	ViolationWrongParent
+
+	// ViolationMultipleParents means the same node appears more than once in
+	// the tree, either in the Kids of different parents or repeated within
+	// the same Kids -- this also catches cycles.
+	ViolationMultipleParents
+
+	// ViolationDuplicateName means two children of the same parent have the
+	// same name, so paths and ChildByName are ambiguous.
+	ViolationDuplicateName
+
+	// ViolationFieldInChildren means a node with the IsField flag is in the
+	// Kids of a node, or a Ki field is missing the IsField flag.
+	ViolationFieldInChildren
+
+	// ViolationDeletedAttached means a node with the NodeDeleted flag is still
+	// attached in the tree.
+	ViolationDeletedAttached
+
+	// ViolationDestroyedAttached means a node with the NodeDestroyed flag is
+	// still attached in the tree.
+	ViolationDestroyedAttached
+
+	ViolationTypesN
+)
+
+// Violation records one tree integrity problem found by Validate.
+type Violation struct {
+
+	// type of violation
+	Type ViolationTypes `desc:"type of violation"`
+
+	// path to the offending node, built from the actual tree structure (not from its Par pointers) -- for a nil child, the path of the parent plus the [index] of the entry
+	Path string `desc:"path to the offending node, built from the actual tree structure (not from its Par pointers) -- for a nil child, the path of the parent plus the [index] of the entry"`
+
+	// the offending node -- nil for ViolationNilChild
+	Node Ki `desc:"the offending node -- nil for ViolationNilChild"`
+
+	// the node that contains the offending node, as a child or field -- nil for the root
+	Parent Ki `desc:"the node that contains the offending node, as a child or field -- nil for the root"`
+
+	// human-readable description of the problem
+	Msg string `desc:"human-readable description of the problem"`
+
+	// true if the problem was fixed by ValidateRepair
+	Repaired bool `desc:"true if the problem was fixed by ValidateRepair"`
+}
+
+// String returns a one-line description of the violation.
+func (vi *Violation) String() string {
+	str := fmt.Sprintf("%v at %v: %v", vi.Type, vi.Path, vi.Msg)
+	if vi.Repaired {
+		str += " (repaired)"
+	}
+	return str
+}
+
+// Error implements the error interface, so violations can be returned as errors.
+func (vi *Violation) Error() string {
+	return vi.String()
+}
+
+// ValidateOnUpdateEnd causes UpdateEnd to Validate the updated subtree and
+// log any violations found.  This is expensive and intended for debugging:
+// it is turned on by building with the kidebug build tag.
+var ValidateOnUpdateEnd = false
+
+// Validate walks the entire tree under root, including Ki fields, and
+// checks its structural integrity, returning the list of violations found
+// (nil if the tree is valid).  Trees can only become corrupted by code that
+// manipulates the Kids or Par fields directly, so this is mainly a debugging
+// tool.  The tree is read directly under the tree read lock, if tree
+// locking is on.  See ValidateRepair to also fix the problems.
+func Validate(root Ki) []Violation {
+	v := validator{}
+	v.run(root)
+	return v.viols
+}
+
+// ValidateRepair calls Validate and then repairs all the violations found,
+// marking them as Repaired: nil, destroyed and IsField children are dropped, nodes
+// that appear in multiple places are kept only under their Par (or the
+// first place found if Par is none of them), Par pointers are fixed,
+// missing This pointers are initialized, stale NodeDeleted flags are
+// cleared, Ki fields are re-initialized, and duplicate names are made
+// unique.  Repairs are made directly, without update signals -- wrap in
+// UpdateStart / End to signal the changes.
+func ValidateRepair(root Ki) []Violation {
+	v := validator{}
+	v.run(root)
+	if len(v.viols) == 0 {
+		return nil
+	}
+	v.repair(root)
+	for i := range v.viols {
+		v.viols[i].Repaired = true
+	}
+	return v.viols
+}
+
+// validator holds the state for a Validate pass.
+type validator struct {
+	viols []Violation
+
+	// nodes visited so far
+	seen map[*Node]bool
+
+	// all the nodes that contain each node in their Kids, in walk order
+	pars map[*Node][]Ki
+
+	// nodes with problems in their Ki fields
+	badFields []Ki
+
+	// nodes with duplicate child names
+	dupNames []Ki
+}
+
+func (v *validator) add(vt ViolationTypes, path string, k, par Ki, msg string, args ...any) {
+	v.viols = append(v.viols, Violation{Type: vt, Path: path, Node: k, Parent: par, Msg: fmt.Sprintf(msg, args...)})
+}
+
+// run does the validation pass, holding the tree read lock
+func (v *validator) run(root Ki) {
+	if root == nil {
+		return
+	}
+	v.seen = make(map[*Node]bool)
+	v.pars = make(map[*Node][]Ki)
+	path := root.Path()
+	TreeRLock(root)
+	defer TreeRUnlock(root)
+	v.walk(root, nil, path, false)
+}
+
+// walk validates node k, which is contained in par (nil for root) at given path.
+// It only accesses the raw node fields, as the lock is held.
+func (v *validator) walk(k Ki, par Ki, path string, field bool) {
+	n := k.AsNode()
+	if par != nil && !field {
+		v.pars[n] = append(v.pars[n], par)
+	}
+	if !field && par != nil && bitflag.HasAtomic(&n.Flag, int(IsField)) {
+		v.add(ViolationFieldInChildren, path, k, par, "node with IsField flag is in the Kids of: %v", par.AsNode().Nm)
+	}
+	if v.seen[n] {
+		v.add(ViolationMultipleParents, path, k, par, "node is already in the tree at another location")
+		return
+	}
+	v.seen[n] = true
+	destroyed := bitflag.HasAtomic(&n.Flag, int(NodeDestroyed))
+	switch {
+	case destroyed:
+		v.add(ViolationDestroyedAttached, path, k, par, "destroyed node is still in the tree")
+	case n.Ths == nil:
+		v.add(ViolationNilThis, path, k, par, "node has a nil This pointer")
+	}
+	if par != nil && n.Par != par {
+		pnm := "nil"
+		if n.Par != nil {
+			pnm = n.Par.AsNode().Nm
+		}
+		v.add(ViolationWrongParent, path, k, par, "Par is: %v instead of: %v", pnm, par.AsNode().Nm)
+	}
+	if par != nil && bitflag.HasAtomic(&n.Flag, int(NodeDeleted)) {
+		v.add(ViolationDeletedAttached, path, k, par, "deleted node is still in the tree")
+	}
+	if n.Ths != nil && !destroyed && KiHasKiFields(n) {
+		v.walkFields(k, path)
+	}
+	nmap := make(map[string]struct{}, len(n.Kids))
+	dup := false
+	for i, kid := range n.Kids {
+		if kid == nil {
+			v.add(ViolationNilChild, fmt.Sprintf("%v/[%d]", path, i), nil, k, "nil child at index: %d", i)
+			continue
+		}
+		knm := kid.AsNode().Nm
+		if _, has := nmap[knm]; has {
+			v.add(ViolationDuplicateName, path+"/"+EscapePathName(knm), kid, k, "duplicate child name at index: %d", i)
+			dup = true
+		}
+		nmap[knm] = struct{}{}
+		v.walk(kid, k, path+"/"+EscapePathName(knm), false)
+	}
+	if dup {
+		v.dupNames = append(v.dupNames, k)
+	}
+}
+
+// walkFields validates the Ki fields of k, which must have a valid This
+func (v *validator) walkFields(k Ki, path string) {
+	n := k.AsNode()
+	bad := false
+	for _, fo := range KiFieldOffs(n) {
+		fn := kiFieldNode(n, fo)
+		fpath := path + "." + EscapePathName(fn.Nm)
+		if fn.Ths == nil {
+			v.add(ViolationNilThis, fpath, fn, k, "Ki field has a nil This pointer")
+			bad = true
+			continue
+		}
+		if !bitflag.HasAtomic(&fn.Flag, int(IsField)) {
+			v.add(ViolationFieldInChildren, fpath, fn.Ths, k, "Ki field is missing the IsField flag")
+			bad = true
+		}
+		if fn.Par != k {
+			bad = true // reported by walk
+		}
+		v.walk(fn.Ths, k, fpath, true)
+	}
+	if bad {
+		v.badFields = append(v.badFields, k)
+	}
+}
+
+// repair fixes the violations found in the run pass.  The Kids of each
+// node are fixed under the write lock, and then nodes are initialized
+// and names uniquified using the regular Ki methods.
+func (v *validator) repair(root Ki) {
+	var inits []Ki
+	if root.AsNode().Ths == nil {
+		inits = append(inits, root)
+	}
+	done := make(map[*Node]bool)
+	TreeLock(root)
+	v.repairKids(root, done, &inits)
+	TreeUnlock(root)
+	for _, k := range inits {
+		InitNode(k)
+	}
+	for _, k := range v.badFields {
+		KiInitKiFields(k)
+	}
+	for _, k := range v.dupNames {
+		UniquifyNames(k)
+	}
+}
+
+// repairKids drops invalid children of k, and fixes the remaining ones,
+// recursively -- called under the write lock.
+func (v *validator) repairKids(k Ki, done map[*Node]bool, inits *[]Ki) {
+	n := k.AsNode()
+	if done[n] {
+		return
+	}
+	done[n] = true
+	if n.Ths != nil && KiHasKiFields(n) {
+		for _, fo := range KiFieldOffs(n) {
+			fn := kiFieldNode(n, fo)
+			if fn.Ths != nil {
+				v.repairKids(fn.Ths, done, inits)
+			}
+		}
+	}
+	kids := n.Kids[:0]
+	for _, kid := range n.Kids {
+		if kid == nil {
+			continue
+		}
+		kn := kid.AsNode()
+		if bitflag.HasAnyAtomic(&kn.Flag, int(NodeDestroyed), int(IsField)) || done[kn] || v.keeper(kn) != k {
+			continue
+		}
+		bitflag.ClearAtomic(&kn.Flag, int(NodeDeleted))
+		kn.Par = k
+		setTreeMu(kn, n.treeMu)
+		if kn.Ths == nil {
+			*inits = append(*inits, kid)
+		}
+		kids = append(kids, kid)
+		v.repairKids(kid, done, inits)
+	}
+	for i := len(kids); i < len(n.Kids); i++ {
+		n.Kids[i] = nil // allow GC
+	}
+	n.Kids = kids
+}
+
+// keeper returns the parent that should keep given node in its Kids:
+// its Par if that is one of the parents containing it, else the first.
+func (v *validator) keeper(kn *Node) Ki {
+	pars := v.pars[kn]
+	if len(pars) == 0 {
+		return nil
+	}
+	for _, p := range pars {
+		if p == kn.Par {
+			return p
+		}
+	}
+	return pars[0]
+}
+
+// validateUpdate is called at UpdateEnd when ValidateOnUpdateEnd is set.
+func (n *Node) validateUpdate() {
+	viols := Validate(n.This())
+	for i := range viols {
+		log.Printf("ki.UpdateEnd Validate: %v\n", viols[i].String())
+	}
+}
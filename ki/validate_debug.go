@@ -0,0 +1,12 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build kidebug
+
+package ki
+
+// building with the kidebug tag validates the tree after every UpdateEnd
+func init() {
+	ValidateOnUpdateEnd = true
+}
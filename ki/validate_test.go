@@ -0,0 +1,105 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ki
+
+import (
+	"testing"
+)
+
+func validateTestTree() (*NodeField, Ki, Ki, Ki) {
+	parent := &NodeField{}
+	parent.InitName(parent, "par1")
+	child1 := parent.AddNewChild(KiT_NodeField, "child1")
+	child2 := parent.AddNewChild(KiT_NodeEmbed, "child2")
+	child3 := parent.AddNewChild(KiT_NodeEmbed, "child3")
+	child1.AddNewChild(KiT_NodeEmbed, "subchild1")
+	return parent, child1, child2, child3
+}
+
+func countViolations(viols []Violation, vt ViolationTypes) int {
+	cnt := 0
+	for i := range viols {
+		if viols[i].Type == vt {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+func TestValidateValid(t *testing.T) {
+	parent, _, _, _ := validateTestTree()
+	if viols := Validate(parent); len(viols) != 0 {
+		t.Errorf("valid tree had violations: %v", viols)
+	}
+	if viols := ValidateRepair(parent); viols != nil {
+		t.Errorf("valid tree had repairs: %v", viols)
+	}
+}
+
+func TestValidateCorrupt(t *testing.T) {
+	parent, child1, child2, child3 := validateTestTree()
+	sub := child1.Child(0)
+	pn := parent.AsNode()
+	pn.Kids = append(pn.Kids, nil)                              // nil child
+	child2.AsNode().Par = child1                                // wrong parent
+	child1.AsNode().Kids = append(child1.AsNode().Kids, child3) // multiple parents
+	child3.SetName("child2")                                    // duplicate name
+	pn.Kids = append(pn.Kids, &parent.Field1)                   // field in children
+	sub.SetFlag(int(NodeDeleted))                               // deleted attached
+	ne := &NodeEmbed{}
+	ne.Nm = "nothis"
+	ne.Par = child1
+	child1.AsNode().Kids = append(child1.AsNode().Kids, ne) // nil this
+	parent.Field1.Par = nil                                 // field wrong parent
+
+	viols := Validate(parent)
+	exp := map[ViolationTypes]int{
+		ViolationNilChild:        1,
+		ViolationNilThis:         1,
+		ViolationWrongParent:     3, // child3 is first found under child1
+		ViolationMultipleParents: 2, // Field1 and child3
+		ViolationDuplicateName:   1,
+		ViolationFieldInChildren: 1,
+		ViolationDeletedAttached: 1,
+	}
+	for vt, n := range exp {
+		if cnt := countViolations(viols, vt); cnt != n {
+			t.Errorf("%v: got %d violations, expected %d: %v", vt, cnt, n, viols)
+		}
+	}
+	for i := range viols {
+		if viols[i].Type == ViolationNilChild && viols[i].Path != "/par1/[3]" {
+			t.Errorf("nil child path: %v", viols[i].Path)
+		}
+		if viols[i].Type == ViolationWrongParent && viols[i].Node == child2 && viols[i].Path != "/par1/child2" {
+			t.Errorf("wrong parent path: %v", viols[i].Path)
+		}
+	}
+
+	rep := ValidateRepair(parent)
+	if len(rep) != len(viols) {
+		t.Errorf("repair found %d violations, validate found %d", len(rep), len(viols))
+	}
+	for i := range rep {
+		if !rep[i].Repaired {
+			t.Errorf("not repaired: %v", rep[i].String())
+		}
+	}
+	if viols := Validate(parent); len(viols) != 0 {
+		t.Errorf("repaired tree still had violations: %v", viols)
+	}
+	if parent.NumChildren() != 3 {
+		t.Errorf("repaired tree should have 3 children, has: %d", parent.NumChildren())
+	}
+	if child3.Parent() != parent || child1.NumChildren() != 2 {
+		t.Errorf("child3 should be kept only in its Par")
+	}
+	if ne.This() != ne || sub.IsDeleted() || parent.Field1.Parent() != parent {
+		t.Errorf("node state not repaired")
+	}
+	if parent.ChildByName("child2", 0) == parent.ChildByName(child3.Name(), 0) {
+		t.Errorf("duplicate names not uniquified: %v", child3.Name())
+	}
+}
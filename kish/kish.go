@@ -0,0 +1,637 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package kish provides an interactive shell for navigating and editing a Ki
+tree as if it were a filesystem, for embedding in programs that register
+their own node types.  Nodes are addressed by paths relative to the current
+node, or absolute from the root, using the usual ki path syntax (names
+separated by /, Ki fields by ., and [idx] for children by index), plus ..
+for the parent.
+
+The shell reads commands line by line, so it can be run interactively or
+scripted over any io.Reader / io.Writer.  A line ending in a tab character
+lists the completions of the line instead of running it, and Complete
+provides completion directly, for use with a line editor.
+
+Commands:
+
+	cd [path]               change the current node (default: root)
+	ls [path]               list the Ki fields and children of the node
+	pwd                     print the path of the current node
+	cat [path]              show the type, fields and props of the node
+	set field=value...      set fields of the current node (SetField)
+	setprop key=value...    set props of the current node
+	mkchild type name       add a new child of given type to the current node
+	rm path...              delete nodes
+	mv path dest            move a node into dest, or rename it if dest
+	                        does not exist but its parent does
+	undo                    undo the last change
+	save [file]             save the tree as JSON
+	help                    list the commands
+	exit                    end the shell
+*/
+package kish
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// Shell is an interactive shell operating on a Ki tree
+type Shell struct {
+
+	// root of the tree
+	Root ki.Ki
+
+	// current node
+	Cur ki.Ki
+
+	// default file name for save
+	Filename string
+
+	// print a prompt with the current path before reading each line
+	Prompt bool
+
+	// maximum number of changes that can be undone
+	UndoMax int
+
+	// copies of the tree before each change, for undo
+	undo []ki.Ki
+
+	// set by the exit command
+	done bool
+}
+
+// New returns a new shell on the tree under given root, with the prompt
+// turned on
+func New(root ki.Ki) *Shell {
+	return &Shell{Root: root, Cur: root, Prompt: true, UndoMax: 100}
+}
+
+// command is a shell command
+type command struct {
+	args string
+	help string
+
+	// true if the command changes the tree, so it can be undone
+	change bool
+
+	fun func(sh *Shell, args []string, w io.Writer) error
+}
+
+// commands are all the shell commands by name
+var commands map[string]*command
+
+func init() {
+	commands = map[string]*command{
+		"cd":      {"[path]", "change the current node (default: root)", false, (*Shell).cmdCd},
+		"ls":      {"[path]", "list the Ki fields and children of the node", false, (*Shell).cmdLs},
+		"pwd":     {"", "print the path of the current node", false, (*Shell).cmdPwd},
+		"cat":     {"[path]", "show the type, fields and props of the node", false, (*Shell).cmdCat},
+		"set":     {"field=value...", "set fields of the current node", true, (*Shell).cmdSet},
+		"setprop": {"key=value...", "set props of the current node", true, (*Shell).cmdSetProp},
+		"mkchild": {"type name", "add a new child of given type to the current node", true, (*Shell).cmdMkChild},
+		"rm":      {"path...", "delete nodes", true, (*Shell).cmdRm},
+		"mv":      {"path dest", "move a node into dest, or rename it if dest does not exist", true, (*Shell).cmdMv},
+		"undo":    {"", "undo the last change", false, (*Shell).cmdUndo},
+		"save":    {"[file]", "save the tree as JSON", false, (*Shell).cmdSave},
+		"help":    {"", "list the commands", false, (*Shell).cmdHelp},
+		"exit":    {"", "end the shell", false, (*Shell).cmdExit},
+	}
+}
+
+// Run runs the shell, reading commands from in until it ends or the exit
+// command, and writing the output to out.  Errors in commands are written
+// to out and do not end the shell.  Lines ending in a tab character list
+// the completions of the line instead.
+func (sh *Shell) Run(in io.Reader, out io.Writer) error {
+	sc := bufio.NewScanner(in)
+	sh.done = false
+	for !sh.done {
+		if sh.Prompt {
+			fmt.Fprintf(out, "%v> ", sh.Cur.Path())
+		}
+		if !sc.Scan() {
+			break
+		}
+		line := sc.Text()
+		if strings.HasSuffix(line, "\t") {
+			fmt.Fprintln(out, strings.Join(sh.Complete(strings.TrimSuffix(line, "\t")), " "))
+			continue
+		}
+		if err := sh.Exec(line, out); err != nil {
+			fmt.Fprintf(out, "error: %v\n", err)
+		}
+	}
+	return sc.Err()
+}
+
+// Exec runs the command in given line, writing its output to w
+func (sh *Shell) Exec(line string, w io.Writer) error {
+	args := strings.Fields(line)
+	if len(args) == 0 || strings.HasPrefix(args[0], "#") {
+		return nil
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command: %v", args[0])
+	}
+	if !cmd.change {
+		return cmd.fun(sh, args[1:], w)
+	}
+	if sh.UndoMax <= 0 {
+		return cmd.fun(sh, args[1:], w)
+	}
+	sh.undo = append(sh.undo, sh.Root.Clone())
+	err := cmd.fun(sh, args[1:], w)
+	if err != nil { // nothing changed
+		sh.popUndo().Destroy()
+		return err
+	}
+	sh.trimUndo()
+	return nil
+}
+
+// trimUndo destroys the oldest copies of the tree for undo beyond UndoMax
+func (sh *Shell) trimUndo() {
+	n := len(sh.undo) - sh.UndoMax
+	if n <= 0 {
+		return
+	}
+	for _, k := range sh.undo[:n] {
+		k.Destroy()
+	}
+	copy(sh.undo, sh.undo[n:])
+	for i := len(sh.undo) - n; i < len(sh.undo); i++ {
+		sh.undo[i] = nil
+	}
+	sh.undo = sh.undo[:len(sh.undo)-n]
+}
+
+// popUndo removes and returns the last copy of the tree for undo
+func (sh *Shell) popUndo() ki.Ki {
+	last := len(sh.undo) - 1
+	prev := sh.undo[last]
+	sh.undo[last] = nil
+	sh.undo = sh.undo[:last]
+	return prev
+}
+
+// Resolve returns the node at given path: absolute if it starts with /,
+// and otherwise relative to the current node.  Paths can include .. for
+// the parent (which stops at the root), [idx] for children by index, and
+// .field for Ki fields.
+func (sh *Shell) Resolve(path string) (ki.Ki, error) {
+	cur := sh.Cur
+	if strings.HasPrefix(path, "/") {
+		cur = sh.Root
+		path = strings.TrimPrefix(path, "/")
+		if nm := ki.EscapePathName(sh.Root.Name()); path == nm || strings.HasPrefix(path, nm+"/") {
+			path = strings.TrimPrefix(path, nm)
+		}
+	}
+	for _, pe := range strings.Split(path, "/") {
+		switch pe {
+		case "", ".":
+			continue
+		case "..":
+			if cur != sh.Root && cur.Parent() != nil {
+				cur = cur.Parent()
+			}
+			continue
+		}
+		fels := strings.Split(pe, ".")
+		if fels[0] != "" {
+			cur = childByPathName(cur, ki.UnescapePathName(fels[0]))
+			if cur == nil {
+				return nil, fmt.Errorf("%v: not found", path)
+			}
+		}
+		for _, fe := range fels[1:] {
+			cur = ki.KiFieldByName(cur.AsNode(), ki.UnescapePathName(fe))
+			if cur == nil {
+				return nil, fmt.Errorf("%v: field not found", path)
+			}
+		}
+	}
+	return cur, nil
+}
+
+// childByPathName returns the child with given name or [idx] index, or nil
+func childByPathName(k ki.Ki, nm string) ki.Ki {
+	if strings.HasPrefix(nm, "[") && strings.HasSuffix(nm, "]") {
+		idx, err := strconv.Atoi(nm[1 : len(nm)-1])
+		if err != nil {
+			return nil
+		}
+		if idx < 0 {
+			idx += k.NumChildren()
+		}
+		kid, err := k.ChildTry(idx)
+		if err != nil {
+			return nil
+		}
+		return kid
+	}
+	return k.ChildByName(nm, 0)
+}
+
+// resolveArg resolves the optional path argument, defaulting to the
+// current node
+func (sh *Shell) resolveArg(args []string) (ki.Ki, error) {
+	switch len(args) {
+	case 0:
+		return sh.Cur, nil
+	case 1:
+		return sh.Resolve(args[0])
+	}
+	return nil, errors.New("too many arguments")
+}
+
+func (sh *Shell) cmdCd(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		sh.Cur = sh.Root
+		return nil
+	}
+	k, err := sh.resolveArg(args)
+	if err != nil {
+		return err
+	}
+	sh.Cur = k
+	return nil
+}
+
+func (sh *Shell) cmdLs(args []string, w io.Writer) error {
+	k, err := sh.resolveArg(args)
+	if err != nil {
+		return err
+	}
+	for _, fnm := range ki.KiFieldNames(k.AsNode()) {
+		fmt.Fprintf(w, ".%v\n", fnm)
+	}
+	for _, kid := range *k.Children() {
+		suffix := ""
+		if kid.HasChildren() {
+			suffix = "/"
+		}
+		fmt.Fprintf(w, "%v%v\t%v\n", kid.Name(), suffix, kit.ShortTypeName(ki.Type(kid)))
+	}
+	return nil
+}
+
+func (sh *Shell) cmdPwd(args []string, w io.Writer) error {
+	_, err := fmt.Fprintln(w, sh.Cur.Path())
+	return err
+}
+
+func (sh *Shell) cmdCat(args []string, w io.Writer) error {
+	k, err := sh.resolveArg(args)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%v %v\n", k.Path(), kit.ShortTypeName(ki.Type(k)))
+	for _, fnm := range fieldNames(k) {
+		fmt.Fprintf(w, "  %v = %v\n", fnm, kit.ToString(kit.FlatFieldValueByName(k, fnm).Interface()))
+	}
+	pr := *k.Properties()
+	keys := make([]string, 0, len(pr))
+	for key := range pr {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "  prop %v = %v\n", key, kit.ToString(pr[key]))
+	}
+	return nil
+}
+
+func (sh *Shell) cmdSet(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("expected field=value")
+	}
+	for _, a := range args {
+		fnm, val, ok := strings.Cut(a, "=")
+		if !ok {
+			return fmt.Errorf("expected field=value: %v", a)
+		}
+		if err := sh.Cur.SetField(fnm, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sh *Shell) cmdSetProp(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("expected key=value")
+	}
+	for _, a := range args {
+		key, val, ok := strings.Cut(a, "=")
+		if !ok {
+			return fmt.Errorf("expected key=value: %v", a)
+		}
+		sh.Cur.SetProp(key, parseValue(val))
+	}
+	return nil
+}
+
+// parseValue returns given string as an int, float64 or bool if it is
+// one, and otherwise as a string
+func parseValue(s string) any {
+	if i, err := strconv.Atoi(s); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	return s
+}
+
+func (sh *Shell) cmdMkChild(args []string, w io.Writer) error {
+	if len(args) != 2 {
+		return errors.New("expected type name")
+	}
+	typ, err := FindType(args[0])
+	if err != nil {
+		return err
+	}
+	if sh.Cur.ChildByName(args[1], 0) != nil {
+		return fmt.Errorf("child named %v already exists", args[1])
+	}
+	sh.Cur.AddNewChild(typ, args[1])
+	return nil
+}
+
+// FindType returns the registered Ki type with given name, which is either
+// the package-qualified name (e.g., ki.Node) or the type name alone if
+// that is unique among the registered Ki types.
+func FindType(name string) (reflect.Type, error) {
+	if typ := kit.Types.Type(name); typ != nil {
+		if !reflect.PtrTo(typ).Implements(ki.KiType) {
+			return nil, fmt.Errorf("type %v is not a Ki type", name)
+		}
+		return typ, nil
+	}
+	var found reflect.Type
+	for _, tn := range kiTypeNames() {
+		if strings.HasSuffix(tn, "."+name) {
+			if found != nil {
+				return nil, fmt.Errorf("type name %v is ambiguous", name)
+			}
+			found = kit.Types.Type(tn)
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("type %v not found", name)
+	}
+	return found, nil
+}
+
+// kiTypeNames returns the sorted names of all the registered Ki types
+func kiTypeNames() []string {
+	var tns []string
+	for tn, typ := range kit.Types.Types {
+		if typ.Kind() == reflect.Struct && reflect.PtrTo(typ).Implements(ki.KiType) {
+			tns = append(tns, tn)
+		}
+	}
+	sort.Strings(tns)
+	return tns
+}
+
+func (sh *Shell) cmdRm(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("expected path")
+	}
+	var dels []ki.Ki
+	for _, a := range args {
+		k, err := sh.Resolve(a)
+		if err != nil {
+			return err
+		}
+		if err := sh.checkMovable(k); err != nil {
+			return err
+		}
+		dels = append(dels, k)
+	}
+	for _, k := range dels {
+		k.Delete(ki.DestroyKids)
+	}
+	return nil
+}
+
+// checkMovable returns an error if given node cannot be removed or moved:
+// the root, Ki fields, and the current node or its parents
+func (sh *Shell) checkMovable(k ki.Ki) error {
+	switch {
+	case k == sh.Root:
+		return errors.New("cannot remove or move the root")
+	case k.IsField():
+		return fmt.Errorf("cannot remove or move Ki field: %v", k.Path())
+	case sh.Cur == k || sh.Cur.ParentLevel(k) >= 0:
+		return fmt.Errorf("cannot remove or move the current node or its parents: %v", k.Path())
+	}
+	return nil
+}
+
+func (sh *Shell) cmdMv(args []string, w io.Writer) error {
+	if len(args) != 2 {
+		return errors.New("expected path dest")
+	}
+	k, err := sh.Resolve(args[0])
+	if err != nil {
+		return err
+	}
+	if err := sh.checkMovable(k); err != nil {
+		return err
+	}
+	nm := k.Name()
+	dest, err := sh.Resolve(args[1])
+	if err != nil { // rename into the parent of dest
+		dir, base := "", args[1]
+		if i := strings.LastIndex(args[1], "/"); i >= 0 {
+			dir, base = args[1][:i+1], args[1][i+1:]
+		}
+		if dest, err = sh.Resolve(dir); err != nil {
+			return err
+		}
+		nm = ki.UnescapePathName(base)
+	}
+	if dest == k || dest.ParentLevel(k) >= 0 {
+		return errors.New("cannot move a node into itself")
+	}
+	if ex := dest.ChildByName(nm, 0); ex != nil && ex != k {
+		return fmt.Errorf("%v already has a child named %v", dest.Path(), nm)
+	}
+	if k.Parent() != dest {
+		ki.MoveToParent(k, dest)
+	}
+	k.SetName(nm)
+	return nil
+}
+
+func (sh *Shell) cmdUndo(args []string, w io.Writer) error {
+	if len(sh.undo) == 0 {
+		return errors.New("nothing to undo")
+	}
+	prev := sh.popUndo()
+	defer prev.Destroy()
+	path := sh.Cur.Path()
+	if err := sh.Root.CopyFrom(prev); err != nil {
+		return err
+	}
+	sh.Cur = sh.Root
+	if cur, err := sh.Resolve(path); err == nil {
+		sh.Cur = cur
+	}
+	return nil
+}
+
+func (sh *Shell) cmdSave(args []string, w io.Writer) error {
+	fnm := sh.Filename
+	if len(args) > 0 {
+		fnm = args[0]
+	}
+	if fnm == "" {
+		return errors.New("expected file")
+	}
+	if err := sh.Root.SaveJSON(fnm); err != nil {
+		return err
+	}
+	sh.Filename = fnm
+	return nil
+}
+
+func (sh *Shell) cmdHelp(args []string, w io.Writer) error {
+	for _, nm := range commandNames() {
+		cmd := commands[nm]
+		fmt.Fprintf(w, "%-24s%v\n", nm+" "+cmd.args, cmd.help)
+	}
+	return nil
+}
+
+func (sh *Shell) cmdExit(args []string, w io.Writer) error {
+	sh.done = true
+	return nil
+}
+
+// commandNames returns the sorted command names
+func commandNames() []string {
+	nms := make([]string, 0, len(commands))
+	for nm := range commands {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms)
+	return nms
+}
+
+// fieldNames returns the names of the exported value fields of given node,
+// excluding those of ki.Node, Ki fields and signals
+func fieldNames(k ki.Ki) []string {
+	var fnms []string
+	kit.FlatFieldsTypeFunc(ki.Type(k), func(typ reflect.Type, field reflect.StructField) bool {
+		switch {
+		case field.PkgPath != "" || typ == ki.KiT_Node:
+		case field.Type == ki.KiT_Signal:
+		case reflect.PtrTo(field.Type).Implements(ki.KiType):
+		default:
+			fnms = append(fnms, field.Name)
+		}
+		return true
+	})
+	return fnms
+}
+
+// Complete returns the possible completions of the last word of given
+// line: command names for the first word, field names for set, prop keys
+// for setprop, type names for the first argument of mkchild, and otherwise
+// paths.  Each completion is the entire last word.
+func (sh *Shell) Complete(line string) []string {
+	args := strings.Fields(line)
+	if len(args) == 0 || strings.HasSuffix(line, " ") {
+		args = append(args, "")
+	}
+	word := args[len(args)-1]
+	var cands []string
+	switch {
+	case len(args) == 1:
+		cands = commandNames()
+	case args[0] == "set":
+		for _, fnm := range fieldNames(sh.Cur) {
+			cands = append(cands, fnm+"=")
+		}
+	case args[0] == "setprop":
+		for key := range *sh.Cur.Properties() {
+			cands = append(cands, key+"=")
+		}
+		sort.Strings(cands)
+	case args[0] == "mkchild" && len(args) == 2:
+		for _, tn := range kiTypeNames() {
+			cands = append(cands, tn)
+		}
+	case args[0] == "mkchild":
+		return nil
+	default:
+		return sh.completePath(word)
+	}
+	var comps []string
+	for _, c := range cands {
+		if strings.HasPrefix(c, word) {
+			comps = append(comps, c)
+		}
+	}
+	return comps
+}
+
+// completePath returns the completions of given path: the children of the
+// node at its directory part, with / appended to those with children, or
+// its Ki fields after a .
+func (sh *Shell) completePath(word string) []string {
+	dir, base := "", word
+	if i := strings.LastIndex(word, "/"); i >= 0 {
+		dir, base = word[:i+1], word[i+1:]
+	}
+	if i := strings.LastIndex(base, "."); i >= 0 && base != "." && base != ".." {
+		k, err := sh.Resolve(dir + base[:i])
+		if err != nil {
+			return nil
+		}
+		var comps []string
+		for _, fnm := range ki.KiFieldNames(k.AsNode()) {
+			if strings.HasPrefix(fnm, base[i+1:]) {
+				comps = append(comps, dir+base[:i+1]+fnm)
+			}
+		}
+		return comps
+	}
+	k, err := sh.Resolve(dir)
+	if err != nil {
+		return nil
+	}
+	var comps []string
+	for _, kid := range *k.Children() {
+		nm := ki.EscapePathName(kid.Name())
+		if !strings.HasPrefix(nm, base) {
+			continue
+		}
+		if kid.HasChildren() {
+			nm += "/"
+		}
+		comps = append(comps, dir+nm)
+	}
+	return comps
+}
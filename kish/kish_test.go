@@ -0,0 +1,143 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kish
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+type testNode struct {
+	ki.Node
+	Val   int
+	Label string
+	Sub   ki.Node
+}
+
+var KiT_testNode = kit.Types.AddType(&testNode{}, nil)
+
+func testTree() *testNode {
+	root := &testNode{}
+	root.InitName(root, "root")
+	a := root.AddNewChild(KiT_testNode, "a")
+	a.AddNewChild(KiT_testNode, "a1")
+	root.AddNewChild(KiT_testNode, "b")
+	return root
+}
+
+// runScript runs given script lines in a shell without a prompt
+func runScript(t *testing.T, sh *Shell, lines ...string) string {
+	var out bytes.Buffer
+	if err := sh.Run(strings.NewReader(strings.Join(lines, "\n")+"\n"), &out); err != nil {
+		t.Error(err)
+	}
+	return out.String()
+}
+
+func TestShell(t *testing.T) {
+	root := testTree()
+	sh := New(root)
+	sh.Prompt = false
+
+	out := runScript(t, sh, "ls", "cd a", "pwd", "cd ..", "cd /root/a/a1", "pwd", "cd", "pwd")
+	exp := ".Sub\na/\tkish.testNode\nb\tkish.testNode\n/root/a\n/root/a/a1\n/root\n"
+	if out != exp {
+		t.Errorf("navigation:\n%v\nexpected:\n%v", out, exp)
+	}
+
+	out = runScript(t, sh, "cd b", "set Val=3 Label=hi", "setprop x=1.5 y=yes", "cat", "set Bogus=1", "cd [0]")
+	exp = "/root/b kish.testNode\n  Val = 3\n  Label = hi\n  prop x = 1.5\n  prop y = yes\nerror:"
+	if !strings.HasPrefix(out, exp) || strings.Count(out, "error:") != 2 {
+		t.Errorf("set:\n%v", out)
+	}
+	b := root.ChildByName("b", 0).(*testNode)
+	if b.Val != 3 || b.Label != "hi" || b.Prop("x") != 1.5 {
+		t.Errorf("set values: %v %v %v", b.Val, b.Label, b.Prop("x"))
+	}
+
+	runScript(t, sh, "cd /", "mkchild testNode c", "mkchild kish.testNode d", "mv c a", "mv d a/e", "rm b")
+	if d := ki.Dump(root, ki.DumpOpts{}); d != "root kish.testNode\n└── a kish.testNode\n    ├── a1 kish.testNode\n    ├── c kish.testNode\n    └── e kish.testNode\n" {
+		t.Errorf("edits:\n%v", d)
+	}
+	out = runScript(t, sh, "cd a/a1", "rm ..", "mkchild Nope x", "exit", "pwd")
+	if strings.Count(out, "error:") != 2 {
+		t.Errorf("expected errors:\n%v", out)
+	}
+
+	runScript(t, sh, "cd /", "undo", "undo", "undo")
+	if d := ki.Dump(root, ki.DumpOpts{}); d != "root kish.testNode\n├── a kish.testNode\n│   └── a1 kish.testNode\n├── b kish.testNode\n├── c kish.testNode\n└── d kish.testNode\n" {
+		t.Errorf("undo:\n%v", d)
+	}
+	if b := root.ChildByName("b", 0).(*testNode); b.Val != 3 {
+		t.Errorf("undo should preserve fields: %v", b.Val)
+	}
+
+	fnm := filepath.Join(t.TempDir(), "tree.json")
+	runScript(t, sh, "save "+fnm)
+	if _, err := os.Stat(fnm); err != nil || sh.Filename != fnm {
+		t.Errorf("save: %v", err)
+	}
+}
+
+func TestUndoMax(t *testing.T) {
+	root := testTree()
+	sh := New(root)
+	sh.Prompt = false
+	sh.UndoMax = 2
+	runScript(t, sh, "mkchild testNode c")
+	first := sh.undo[0]
+	runScript(t, sh, "mkchild testNode d", "mkchild testNode e")
+	if len(sh.undo) != 2 || !first.IsDestroyed() {
+		t.Errorf("evicted undo copy should be destroyed: %d %v", len(sh.undo), first.IsDestroyed())
+	}
+	last := sh.undo[1]
+	runScript(t, sh, "mkchild Nope x") // fails: its copy is dropped
+	if len(sh.undo) != 2 || sh.undo[1] != last {
+		t.Errorf("failed command should only drop its own undo copy: %d", len(sh.undo))
+	}
+	sh.UndoMax = 0
+	runScript(t, sh, "mkchild Nope x")
+	if len(sh.undo) != 2 {
+		t.Errorf("failed command with undo off should not drop undo copies: %d", len(sh.undo))
+	}
+	runScript(t, sh, "undo", "undo")
+	if len(sh.undo) != 0 || !last.IsDestroyed() || root.NumChildren() != 3 {
+		t.Errorf("undo should destroy the copies it restores: %v %d", last.IsDestroyed(), root.NumChildren())
+	}
+}
+
+func TestComplete(t *testing.T) {
+	root := testTree()
+	sh := New(root)
+	tests := []struct {
+		line string
+		exp  string
+	}{
+		{"c", "cat cd"},
+		{"cd ", "a/ b"},
+		{"cd a/", "a/a1"},
+		{"cd .S", ".Sub"},
+		{"cd a.", "a.Sub"},
+		{"set L", "Label="},
+		{"mkchild kish.t", "kish.testNode"},
+		{"mkchild x ", ""},
+		{"ls nope/", ""},
+	}
+	for _, tt := range tests {
+		if c := strings.Join(sh.Complete(tt.line), " "); c != tt.exp {
+			t.Errorf("complete %q: %q, expected %q", tt.line, c, tt.exp)
+		}
+	}
+	sh.Prompt = false
+	if out := runScript(t, sh, "cd a\t", "cd a"); out != "a/\n" || sh.Cur.Name() != "a" {
+		t.Errorf("complete in Run: %q", out)
+	}
+}
@@ -247,9 +247,13 @@ func FieldValueByPath(stru any, path string) (reflect.Value, bool) {
 }
 
 // FlatFieldTag returns given tag value in field in type or embedded structs
-// within type, by name -- empty string if not set or field not found
+// within type, by name -- empty string if not set or field not found.
+// Uses the cached TypeInfo.
 func FlatFieldTag(typ reflect.Type, nm, tag string) string {
-	fld, ok := typ.FieldByName(nm)
+	if fi := TypeInfo(typ).Field(nm); fi != nil {
+		return fi.Tags[tag]
+	}
+	fld, ok := NonPtrType(typ).FieldByName(nm) // e.g., embedded struct fields
 	if !ok {
 		return ""
 	}
@@ -8,11 +8,18 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
 // SetFromDefaultTags sets values of fields in given struct based on
-// `def:` default value field tags.
+// `def:` default value field tags, using the cached TypeInfo -- the field
+// is set to the first default value in the tag, or, for a tag with only
+// ranges, e.g., def:"0:1", to the start of the first range if it is not
+// already in one of them.  Fields without a def tag are left as is, except
+// that struct fields (including Ki fields), pointers to structs, and the
+// elements of slices, arrays and maps of structs are set from their own
+// field tags.
 func SetFromDefaultTags(obj any) error {
 	if IfaceIsNil(obj) {
 		return nil
@@ -21,37 +28,257 @@ func SetFromDefaultTags(obj any) error {
 	if ov.Kind() == reflect.Pointer && ov.IsNil() {
 		return nil
 	}
-	val := NonPtrValue(ov)
-	typ := val.Type()
+	return setDefaults(NonPtrValue(ov), make(map[uintptr]bool))
+}
+
+// setDefaults sets the fields of given struct value from their def tags,
+// recursing into nested structs -- visited prevents pointer cycles
+func setDefaults(val reflect.Value, visited map[uintptr]bool) error {
+	si := TypeInfo(val.Type())
+	if si == nil {
+		return nil
+	}
 	var err error
-	for i := 0; i < typ.NumField(); i++ {
-		f := typ.Field(i)
-		fv := val.Field(i)
-		def, ok := f.Tag.Lookup("def")
-		if NonPtrType(f.Type).Kind() == reflect.Struct && (!ok || def == "") {
-			fi := PtrValue(fv).Interface()
-			if IfaceIsNil(fi) {
-				continue
-			}
-			SetFromDefaultTags(fi)
+	for _, fi := range si.Fields {
+		if !fi.Exported {
 			continue
 		}
-		if !ok || def == "" {
+		fv := val.FieldByIndex(fi.Index)
+		if !fv.CanSet() {
 			continue
 		}
-		if def[0] == '{' || def[0] == '[' { // complex type
-			def = strings.ReplaceAll(def, `'`, `"`) // allow single quote to work as double quote for JSON format
-		} else {
-			def = strings.Split(def, ",")[0]
-			if strings.Contains(def, ":") { // don't do ranges
-				continue
+		if !fi.HasDefault() {
+			if ferr := setNestedDefaults(fv, visited); ferr != nil {
+				err = ferr
 			}
+			continue
 		}
-		ok = SetRobust(PtrValue(fv).Interface(), def) // overkill but whatever
-		if !ok {
-			err = fmt.Errorf("SetFromDefaultTags: was not able to set field: %s in object of type: %s from val: %s", f.Name, typ.Name(), def)
+		if ferr := setFieldDefault(fi, fv); ferr != nil {
+			err = fmt.Errorf("SetFromDefaultTags: %v in object of type: %s", ferr, val.Type().Name())
 			log.Println(err)
 		}
 	}
 	return err
 }
+
+// setNestedDefaults sets the defaults of the structs within given value:
+// the value itself, what it points to, or its elements
+func setNestedDefaults(val reflect.Value, visited map[uintptr]bool) error {
+	switch val.Kind() {
+	case reflect.Pointer:
+		if val.IsNil() || visited[val.Pointer()] {
+			return nil
+		}
+		visited[val.Pointer()] = true
+		return setNestedDefaults(val.Elem(), visited)
+	case reflect.Struct:
+		return setDefaults(val, visited)
+	case reflect.Slice, reflect.Array:
+		if !hasNestedFields(val.Type().Elem()) {
+			return nil
+		}
+		var err error
+		for i := 0; i < val.Len(); i++ {
+			if ierr := setNestedDefaults(val.Index(i), visited); ierr != nil {
+				err = ierr
+			}
+		}
+		return err
+	case reflect.Map:
+		if !hasNestedFields(val.Type().Elem()) {
+			return nil
+		}
+		var err error
+		for _, k := range val.MapKeys() { // map elements must be copied to set
+			ev := reflect.New(val.Type().Elem()).Elem()
+			ev.Set(val.MapIndex(k))
+			if ierr := setNestedDefaults(ev, visited); ierr != nil {
+				err = ierr
+			}
+			val.SetMapIndex(k, ev)
+		}
+		return err
+	}
+	return nil
+}
+
+// setFieldDefault sets given field value from the def tag of the field
+func setFieldDefault(fi *FieldInfo, fv reflect.Value) error {
+	switch {
+	case fi.DefTag != "" && fi.Default == nil:
+		return fmt.Errorf("was not able to set field: %s from val: %s", fi.Name, fi.DefTag)
+	case fi.DefTag == "": // only ranges
+		if _, ok := ToFloat(fv.Interface()); !ok {
+			return fmt.Errorf("field: %s is not a number for def ranges", fi.Name)
+		}
+		if fi.IsDefault(fv.Interface()) {
+			return nil
+		}
+		dr := fi.DefRanges[0]
+		st := dr.Min
+		if !dr.HasMin {
+			st = dr.Max
+		}
+		if !SetRobust(PtrValue(fv).Interface(), st) {
+			return fmt.Errorf("was not able to set field: %s from range start: %v", fi.Name, st)
+		}
+	case isRefKind(fi.Type.Kind()): // fresh value, not shared with the default
+		SetRobust(PtrValue(fv).Interface(), fi.DefTag)
+	default:
+		fv.Set(reflect.ValueOf(fi.Default))
+	}
+	return nil
+}
+
+// SetFieldToDefault sets the field at given path in given struct (pointer)
+// to its default value, as in SetFromDefaultTags -- the path has field
+// names separated by dots, as returned by NonDefaultFields, and can also
+// have an [index] or [key] for elements of slices, arrays and maps, e.g.,
+// Items[2].Name.
+// Fields without a def tag are set to the zero value, with the fields of
+// structs then set from their def tags (Ki fields are not zeroed).
+func SetFieldToDefault(obj any, path string) error {
+	if IfaceIsNil(obj) {
+		return fmt.Errorf("SetFieldToDefault: nil object")
+	}
+	ov := reflect.ValueOf(obj)
+	if ov.Kind() != reflect.Pointer || ov.IsNil() {
+		return fmt.Errorf("SetFieldToDefault: object must be a non-nil pointer to a struct, not: %v", ov.Type())
+	}
+	err := setPathDefault(NonPtrValue(ov), path)
+	if err != nil {
+		return fmt.Errorf("SetFieldToDefault: path: %v in object of type: %v: %v", path, ov.Type().Elem().Name(), err)
+	}
+	return nil
+}
+
+// setPathDefault sets the field at given path within given struct value
+// to its default value
+func setPathDefault(val reflect.Value, path string) error {
+	pe, rest, _ := strings.Cut(path, ".")
+	name, idx, hasIdx := strings.Cut(pe, "[")
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("field: %v is not in a struct", name)
+	}
+	si := TypeInfo(val.Type())
+	fi := si.Field(name)
+	if fi == nil {
+		if sf, ok := val.Type().FieldByName(name); ok && sf.Anonymous && !hasIdx && rest != "" { // embedded struct
+			return setPathDefault(val.FieldByIndex(sf.Index), rest)
+		}
+		return fmt.Errorf("field: %v not found", name)
+	}
+	fv := val.FieldByIndex(fi.Index)
+	if !fi.Exported || !fv.CanSet() {
+		return fmt.Errorf("field: %v is not exported", name)
+	}
+	if !hasIdx {
+		if rest == "" {
+			return resetField(fi, fv)
+		}
+		return setPathDefault(NonPtrValue(fv), rest)
+	}
+	if !strings.HasSuffix(idx, "]") {
+		return fmt.Errorf("missing ] in: %v", pe)
+	}
+	idx = strings.TrimSuffix(idx, "]")
+	setElem := func(ev reflect.Value) error {
+		if rest == "" {
+			ev.Set(reflect.Zero(ev.Type()))
+			return setNestedDefaults(ev, make(map[uintptr]bool))
+		}
+		return setPathDefault(NonPtrValue(ev), rest)
+	}
+	cv := NonPtrValue(fv)
+	switch cv.Kind() {
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(idx)
+		if err != nil || i < 0 || i >= cv.Len() {
+			return fmt.Errorf("index: %v out of range for field: %v", idx, name)
+		}
+		return setElem(cv.Index(i))
+	case reflect.Map:
+		kv := reflect.New(cv.Type().Key())
+		if !SetRobust(kv.Interface(), idx) {
+			return fmt.Errorf("invalid key: %v for field: %v", idx, name)
+		}
+		mv := cv.MapIndex(kv.Elem())
+		if !mv.IsValid() {
+			return fmt.Errorf("key: %v not found in field: %v", idx, name)
+		}
+		ev := reflect.New(cv.Type().Elem()).Elem() // map elements must be copied to set
+		ev.Set(mv)
+		if err := setElem(ev); err != nil {
+			return err
+		}
+		cv.SetMapIndex(kv.Elem(), ev)
+		return nil
+	}
+	return fmt.Errorf("field: %v cannot be indexed", name)
+}
+
+// resetField sets given field to its default value
+func resetField(fi *FieldInfo, fv reflect.Value) error {
+	if fi.HasDefault() {
+		return setFieldDefault(fi, fv)
+	}
+	if !fi.IsKi {
+		fv.Set(reflect.Zero(fi.Type))
+	}
+	return setNestedDefaults(fv, make(map[uintptr]bool))
+}
+
+// NonDefaultFields returns the paths of the fields of given struct (or
+// pointer to it) whose values differ from their defaults (see
+// FieldInfo.IsDefault), e.g., for saving only the non-default values.
+// Struct fields (including Ki fields) without a def tag are recursed into,
+// with paths separated by dots, e.g., Pos.X -- other fields without a def
+// tag, including slices, maps and pointers, are listed if they are not
+// the zero value.  Fields with a json:"-" or copy:"-" tag are not part of
+// the saved state of a value and are skipped.
+func NonDefaultFields(obj any) []string {
+	if IfaceIsNil(obj) {
+		return nil
+	}
+	var paths []string
+	nonDefaultFields(NonPtrValue(reflect.ValueOf(obj)), "", &paths)
+	return paths
+}
+
+// nonDefaultFields adds the paths of the non-default fields of given
+// struct value to paths
+func nonDefaultFields(val reflect.Value, path string, paths *[]string) {
+	si := TypeInfo(val.Type())
+	if si == nil {
+		return
+	}
+	for _, fi := range si.Fields {
+		if !fi.Exported || fi.Tags["json"] == "-" || fi.Tags["copy"] == "-" {
+			continue
+		}
+		fv := val.FieldByIndex(fi.Index)
+		if !fv.CanInterface() {
+			continue
+		}
+		fpath := fi.Path
+		if path != "" {
+			fpath = path + "." + fi.Path
+		}
+		if !fi.HasDefault() && fv.Kind() == reflect.Struct {
+			nonDefaultFields(fv, fpath, paths)
+			continue
+		}
+		if !fi.IsDefault(fv.Interface()) {
+			*paths = append(*paths, fpath)
+		}
+	}
+}
+
+// isRefKind returns true for kinds of values that refer to shared data
+func isRefKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Slice, reflect.Map, reflect.Pointer, reflect.Interface, reflect.Chan, reflect.Func:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type dfItem struct {
+	Name string  `def:"item"`
+	Wt   float32 `def:"1,2,5:10"`
+}
+
+type dfStruct struct {
+	Size   int     `def:"2,4"`
+	Ranged float64 `def:"0.1:1"`
+	URL    string  `def:"http://host:80"`
+	Item   dfItem
+	Items  []dfItem
+	Map    map[string]dfItem
+	Ptr    *dfItem
+	Count  int
+	Skip   int `json:"-"`
+}
+
+func TestDefaults(t *testing.T) {
+	si := TypeInfo(reflect.TypeOf(dfStruct{}))
+	wfi := TypeInfo(reflect.TypeOf(dfItem{})).Field("Wt")
+	if !reflect.DeepEqual(wfi.DefOptions, []any{float32(1), float32(2)}) || len(wfi.DefRanges) != 1 || !wfi.DefRanges[0].Contains(7) {
+		t.Errorf("def options and ranges not parsed: %v %v", wfi.DefOptions, wfi.DefRanges)
+	}
+	if ufi := si.Field("URL"); ufi.Default != "http://host:80" || ufi.RulesErr != nil {
+		t.Errorf("string def with : should not be a range: %v %v", ufi.Default, ufi.RulesErr)
+	}
+
+	ds := &dfStruct{Ranged: 5, Items: make([]dfItem, 2), Map: map[string]dfItem{"a": {}}, Ptr: &dfItem{}}
+	if err := SetFromDefaultTags(ds); err != nil {
+		t.Error(err)
+	}
+	want := dfItem{"item", 1}
+	if ds.Size != 2 || ds.Ranged != 0.1 || ds.Item != want || ds.Items[1] != want || ds.Map["a"] != want || *ds.Ptr != want {
+		t.Errorf("SetFromDefaultTags did not set all defaults: %+v", ds)
+	}
+	ds.Ranged = 0.5
+	SetFromDefaultTags(ds)
+	if ds.Ranged != 0.5 {
+		t.Errorf("SetFromDefaultTags should keep values within def range: %v", ds.Ranged)
+	}
+
+	ds.Items, ds.Map, ds.Ptr = nil, nil, nil
+	if nd := NonDefaultFields(ds); nd != nil {
+		t.Errorf("all fields should be default: %v", nd)
+	}
+	ds.Size = 4
+	ds.Ranged = 2
+	ds.Item.Wt = 7
+	ds.Item.Name = "other"
+	ds.Items = []dfItem{want, want}
+	ds.Count = 3
+	ds.Skip = 1
+	if got := strings.Join(NonDefaultFields(ds), " "); got != "Ranged Item.Name Items Count" {
+		t.Errorf("wrong non-default fields: %v", got)
+	}
+
+	ds.Items[1].Wt = 3
+	for _, path := range []string{"Ranged", "Item.Name", "Items[1].Wt", "Count"} {
+		if err := SetFieldToDefault(ds, path); err != nil {
+			t.Error(err)
+		}
+	}
+	if ds.Ranged != 0.1 || ds.Item.Name != "item" || ds.Items[1].Wt != 1 || ds.Count != 0 || ds.Size != 4 {
+		t.Errorf("SetFieldToDefault did not reset fields: %+v", ds)
+	}
+	ds.Map = map[string]dfItem{"k": {Name: "x"}}
+	if err := SetFieldToDefault(ds, "Map[k].Name"); err != nil || ds.Map["k"].Name != "item" {
+		t.Errorf("SetFieldToDefault did not reset map element field: %v %v", err, ds.Map)
+	}
+	if err := SetFieldToDefault(ds, "Nope"); err == nil {
+		t.Errorf("SetFieldToDefault should fail for missing field")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -19,6 +20,8 @@ type Type struct {
 	T reflect.Type
 }
 
+var KiT_Type = Types.AddType(&Type{}, nil)
+
 // ShortTypeName returns short package-qualified name of the type: package dir + "." + type name
 func (k Type) ShortTypeName() string {
 	return Types.TypeName(k.T)
@@ -120,20 +123,38 @@ func (k *Type) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 }
 
 // StructTags returns a map[string]string of the tag string from a reflect.StructTag value
-// e.g., from StructField.Tag
+// e.g., from StructField.Tag -- values can contain spaces and escaped quotes,
+// as for reflect.StructTag.Get
 func StructTags(tags reflect.StructTag) map[string]string {
 	if len(tags) == 0 {
 		return nil
 	}
-	flds := strings.Fields(string(tags))
-	smap := make(map[string]string, len(flds))
-	for _, fld := range flds {
-		cli := strings.Index(fld, ":")
-		if cli < 0 || len(fld) < cli+3 {
-			continue
+	tag := string(tags)
+	smap := make(map[string]string)
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " \t\r\n")
+		cli := strings.Index(tag, ":\"")
+		if cli <= 0 || strings.ContainsAny(tag[:cli], " \t\r\n\"") {
+			break
+		}
+		key := tag[:cli]
+		tag = tag[cli+1:]
+		i := 1 // scan the quoted value
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		vl, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			break
 		}
-		vl := strings.TrimSuffix(fld[cli+2:], `"`)
-		smap[fld[:cli]] = vl
+		smap[key] = vl
+		tag = tag[i+1:]
 	}
 	return smap
 }
@@ -0,0 +1,316 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kit
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FieldInfo describes a field of a struct type, as returned by TypeInfo,
+// with the information from its tags parsed once.
+type FieldInfo struct {
+
+	// the field name
+	Name string `desc:"the field name"`
+
+	// dot-separated path of the field from the struct, through the anonymous embedded structs it is in, e.g., B.A.Mbr1
+	Path string `desc:"dot-separated path of the field from the struct, through the anonymous embedded structs it is in, e.g., B.A.Mbr1"`
+
+	// index sequence of the field, for reflect.Value.FieldByIndex
+	Index []int `desc:"index sequence of the field, for reflect.Value.FieldByIndex"`
+
+	// the type of the field
+	Type reflect.Type `desc:"the type of the field"`
+
+	// the reflect info for the field
+	Field reflect.StructField `desc:"the reflect info for the field"`
+
+	// the parsed field tags, by key (see StructTags)
+	Tags map[string]string `desc:"the parsed field tags, by key (see StructTags)"`
+
+	// the anonymous embedded struct type the field is declared in -- nil if declared in the struct itself
+	EmbeddedFrom reflect.Type `desc:"the anonymous embedded struct type the field is declared in -- nil if declared in the struct itself"`
+
+	// whether the field is a Ki node struct (see KiType)
+	IsKi bool `desc:"whether the field is a Ki node struct (see KiType)"`
+
+	// whether the field is exported
+	Exported bool `desc:"whether the field is exported"`
+
+	// the value of the def tag converted to the type of the field -- nil if there is no def tag or it cannot be converted (see DefTag)
+	Default any `desc:"the value of the def tag converted to the type of the field -- nil if there is no def tag or it cannot be converted (see DefTag)"`
+
+	// the def tag value used for the default, as used by SetFromDefaultTags: complex values in JSON format with single quotes allowed, and the first of a list of values -- empty if none, or for ranges
+	DefTag string `desc:"the def tag value used for the default, as used by SetFromDefaultTags: complex values in JSON format with single quotes allowed, and the first of a list of values -- empty if none, or for ranges"`
+
+	// all of the default values in the def tag, converted to the type of the field, e.g., 1 and 2 for def:"1,2,5:10" -- the first is Default
+	DefOptions []any `desc:"all of the default values in the def tag, converted to the type of the field, e.g., 1 and 2 for def:\"1,2,5:10\" -- the first is Default"`
+
+	// ranges of default values in the def tag, for numeric fields, e.g., 5:10 for def:"1,2,5:10"
+	DefRanges []DefRange `desc:"ranges of default values in the def tag, for numeric fields, e.g., 5:10 for def:\"1,2,5:10\""`
+
+	// the validation rules from the tags of the field -- nil if none (see FieldRules, Validate)
+	Rules *FieldRules `desc:"the validation rules from the tags of the field -- nil if none (see FieldRules, Validate)"`
+
+	// error in the validation rule tags of the field, e.g., a min that is not a number, which is reported by Validate
+	RulesErr error `desc:"error in the validation rule tags of the field, e.g., a min that is not a number, which is reported by Validate"`
+
+	// depth of embedding, for precedence of names
+	depth int
+}
+
+// Tag returns the value of given tag of the field, empty if not set
+func (fi *FieldInfo) Tag(key string) string {
+	return fi.Tags[key]
+}
+
+// StructInfo describes the fields of a struct type, as returned by
+// TypeInfo -- it must not be modified.
+type StructInfo struct {
+
+	// the struct type
+	Type reflect.Type `desc:"the struct type"`
+
+	// the primary fields of the struct, including those of anonymous embedded structs, in order, as in FlatFields
+	Fields []*FieldInfo `desc:"the primary fields of the struct, including those of anonymous embedded structs, in order, as in FlatFields"`
+
+	// fields by name, with outer fields taking precedence over embedded ones
+	byName map[string]*FieldInfo
+}
+
+// Field returns the info for given field name -- nil if not found
+func (si *StructInfo) Field(name string) *FieldInfo {
+	if si == nil {
+		return nil
+	}
+	return si.byName[name]
+}
+
+// FieldTag returns the value of given tag of given field name, empty if
+// not set or the field is not found
+func (si *StructInfo) FieldTag(name, tag string) string {
+	fi := si.Field(name)
+	if fi == nil {
+		return ""
+	}
+	return fi.Tags[tag]
+}
+
+// FieldByPath returns the info for given dot-separated path of field
+// names through nested struct fields, e.g., Pos.X -- nil if not found
+func (si *StructInfo) FieldByPath(path string) *FieldInfo {
+	var fi *FieldInfo
+	for _, pe := range strings.Split(path, ".") {
+		if si == nil {
+			return nil
+		}
+		fi = si.Field(pe)
+		if fi == nil {
+			if sf, ok := si.Type.FieldByName(pe); ok && sf.Anonymous { // embedded struct
+				si = TypeInfo(sf.Type)
+				continue
+			}
+			return nil
+		}
+		si = TypeInfo(fi.Type)
+	}
+	return fi
+}
+
+// KiType is the Ki interface type, set by the ki package, for flagging Ki
+// fields in TypeInfo (kit cannot depend on ki) -- see SetKiType
+var KiType reflect.Type
+
+// SetKiType sets the KiType, resetting the cached TypeInfo
+func SetKiType(typ reflect.Type) {
+	typeInfos.mu.Lock()
+	KiType = typ
+	typeInfos.m = make(map[reflect.Type]*StructInfo)
+	typeInfos.mu.Unlock()
+}
+
+// typeInfos caches the TypeInfo by type
+var typeInfos = struct {
+	mu sync.RWMutex
+	m  map[reflect.Type]*StructInfo
+}{m: make(map[reflect.Type]*StructInfo)}
+
+// TypeInfo returns the field info for given struct type (or pointer to
+// it), which is built on first use and cached -- nil if not a struct.
+func TypeInfo(typ reflect.Type) *StructInfo {
+	if typ == nil {
+		return nil
+	}
+	typ = NonPtrType(typ)
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	typeInfos.mu.RLock()
+	si, has := typeInfos.m[typ]
+	typeInfos.mu.RUnlock()
+	if has {
+		return si
+	}
+	si = &StructInfo{Type: typ, byName: make(map[string]*FieldInfo)}
+	typeInfos.mu.RLock()
+	kitype := KiType
+	typeInfos.mu.RUnlock()
+	si.addFields(typ, nil, "", nil, 0, kitype)
+	typeInfos.mu.Lock()
+	if prv, has := typeInfos.m[typ]; has {
+		si = prv
+	} else {
+		typeInfos.m[typ] = si
+	}
+	typeInfos.mu.Unlock()
+	return si
+}
+
+// addFields adds the fields of given struct type, embedded in the info
+// struct type at given index, path and depth
+func (si *StructInfo) addFields(typ reflect.Type, emb reflect.Type, path string, index []int, depth int, kitype reflect.Type) {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		idx := append(append([]int(nil), index...), i)
+		if f.Type.Kind() == reflect.Struct && f.Anonymous {
+			si.addFields(f.Type, f.Type, path+f.Name+".", idx, depth+1, kitype)
+			continue
+		}
+		fi := &FieldInfo{Name: f.Name, Path: path + f.Name, Index: idx, Type: f.Type, Field: f, Tags: StructTags(f.Tag), EmbeddedFrom: emb, Exported: f.PkgPath == "", depth: depth}
+		fi.IsKi = kitype != nil && f.Type.Kind() == reflect.Struct && reflect.PtrTo(f.Type).Implements(kitype)
+		fi.setDefault()
+		fi.setRules()
+		si.Fields = append(si.Fields, fi)
+		if prv, has := si.byName[f.Name]; !has || prv.depth > depth {
+			si.byName[f.Name] = fi
+		}
+	}
+}
+
+// DefRange is a range of default values in a def tag, e.g., 0.1:1 --
+// either end can be omitted, e.g., 1: for values of at least 1
+type DefRange struct {
+
+	// the minimum value, if HasMin
+	Min float64 `desc:"the minimum value, if HasMin"`
+
+	// the maximum value, if HasMax
+	Max float64 `desc:"the maximum value, if HasMax"`
+
+	// whether there is a minimum value
+	HasMin bool `desc:"whether there is a minimum value"`
+
+	// whether there is a maximum value
+	HasMax bool `desc:"whether there is a maximum value"`
+}
+
+// Contains returns true if given value is within the range
+func (dr DefRange) Contains(f float64) bool {
+	return (!dr.HasMin || f >= dr.Min) && (!dr.HasMax || f <= dr.Max)
+}
+
+// setDefault sets the default values and ranges from the def tag:
+// complex values are a single option in JSON format, with single quotes
+// allowed, and otherwise the tag is a comma-separated list of values and,
+// for numeric fields, min:max ranges.
+func (fi *FieldInfo) setDefault() {
+	def, ok := fi.Field.Tag.Lookup("def")
+	if !ok || def == "" {
+		return
+	}
+	var opts []string
+	if def[0] == '{' || def[0] == '[' { // complex type
+		opts = []string{strings.ReplaceAll(def, `'`, `"`)} // allow single quote to work as double quote for JSON format
+	} else {
+		num := isNumKind(NonPtrType(fi.Type).Kind())
+		for i, ds := range strings.Split(def, ",") {
+			if i > 0 {
+				ds = strings.TrimSpace(ds)
+			}
+			if lo, hi, isRange := strings.Cut(ds, ":"); isRange && num {
+				var dr DefRange
+				var lerr, herr error
+				if lo != "" {
+					dr.Min, lerr = strconv.ParseFloat(strings.TrimSpace(lo), 64)
+					dr.HasMin = lerr == nil
+				}
+				if hi != "" {
+					dr.Max, herr = strconv.ParseFloat(strings.TrimSpace(hi), 64)
+					dr.HasMax = herr == nil
+				}
+				if lerr == nil && herr == nil {
+					fi.DefRanges = append(fi.DefRanges, dr)
+				}
+				continue
+			}
+			opts = append(opts, ds)
+		}
+	}
+	if len(opts) == 0 {
+		return
+	}
+	fi.DefTag = opts[0]
+	if !fi.Exported {
+		return
+	}
+	for i, ds := range opts {
+		nv := reflect.New(fi.Type)
+		if !SetRobust(nv.Interface(), ds) {
+			continue
+		}
+		if i == 0 {
+			fi.Default = nv.Elem().Interface()
+		}
+		fi.DefOptions = append(fi.DefOptions, nv.Elem().Interface())
+	}
+}
+
+// HasDefault returns true if the field has default values or ranges in
+// its def tag
+func (fi *FieldInfo) HasDefault() bool {
+	return fi.DefTag != "" || len(fi.DefRanges) > 0
+}
+
+// IsDefault returns true if given value of the field is one of its
+// default values or within one of its default ranges, or, if it does not
+// have a def tag, if it is the zero value
+func (fi *FieldInfo) IsDefault(val any) bool {
+	if !fi.HasDefault() {
+		return IfaceIsNil(val) || reflect.ValueOf(val).IsZero()
+	}
+	return inDefaults(fi.DefOptions, fi.DefRanges, val)
+}
+
+// inDefaults returns true if given value is one of given default values,
+// or a number within one of given default ranges -- this is what both
+// IsDefault and Validate use for the def tag
+func inDefaults(opts []any, ranges []DefRange, val any) bool {
+	for _, dv := range opts {
+		if reflect.DeepEqual(val, dv) {
+			return true
+		}
+	}
+	if len(ranges) == 0 {
+		return false
+	}
+	f, ok := ToFloat(val)
+	if !ok {
+		return false
+	}
+	for _, dr := range ranges {
+		if dr.Contains(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNumKind returns true for kinds of numbers
+func isNumKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kit
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type tiEmbed struct {
+	Size int     `def:"2" desc:"the size, in units"`
+	Mbr  float32 `def:"0.5,0.1:1"`
+	priv int
+}
+
+type tiStruct struct {
+	tiEmbed
+	Size   float64           `def:"3" view:"-" desc:"outer size \"shadows\" the embedded one"`
+	Field  A                 `desc:"not an embedded struct"`
+	Flag   TestFlags         `def:"2"`
+	Names  []string          `def:"['a', 'b']"`
+	Map    map[string]int    `def:"{'a': 1}"`
+	Bad    int               `def:"bad"`
+	Ranged float32           `def:"0:1"`
+	Tagged map[string]string `json:"-" xml:"tagged,attr"`
+}
+
+func TestTypeInfo(t *testing.T) {
+	prv := KiType
+	SetKiType(reflect.TypeOf((*AIf)(nil)).Elem()) // *A implements AIf
+	defer SetKiType(prv)
+
+	si := TypeInfo(reflect.TypeOf(&tiStruct{}))
+	if si == nil || si != TypeInfo(reflect.TypeOf(tiStruct{})) {
+		t.Fatalf("TypeInfo should be cached for the struct type")
+	}
+	if TypeInfo(reflect.TypeOf(0)) != nil {
+		t.Errorf("TypeInfo of non-struct should be nil")
+	}
+	var flds []string
+	for _, fi := range si.Fields {
+		flds = append(flds, fmt.Sprintf("%v%v", fi.Path, fi.Index))
+	}
+	exp := "[tiEmbed.Size[0 0] tiEmbed.Mbr[0 1] tiEmbed.priv[0 2] Size[1] Field[2] Flag[3] Names[4] Map[5] Bad[6] Ranged[7] Tagged[8]]"
+	if fmt.Sprint(flds) != exp {
+		t.Errorf("fields:\n%v\nexpected:\n%v", flds, exp)
+	}
+
+	sz := si.Field("Size")
+	if sz.EmbeddedFrom != nil || sz.Default != 3.0 || sz.Tag("desc") != `outer size "shadows" the embedded one` || sz.Tag("view") != "-" {
+		t.Errorf("outer Size field: %+v", sz)
+	}
+	mbr := si.Field("Mbr")
+	if mbr.EmbeddedFrom != reflect.TypeOf(tiEmbed{}) || mbr.Default != float32(0.5) {
+		t.Errorf("embedded field: %+v", mbr)
+	}
+	if si.Fields[0].Tag("desc") != "the size, in units" || si.FieldTag("Tagged", "xml") != "tagged,attr" {
+		t.Errorf("tags: %v %v", si.Fields[0].Tags, si.Field("Tagged").Tags)
+	}
+	if !si.Field("Field").IsKi || si.Field("Flag").IsKi || si.Field("priv").Exported {
+		t.Errorf("IsKi / Exported flags")
+	}
+	if si.Field("Flag").Default != TestFlags(2) || si.Field("Bad").Default != nil || si.Field("Ranged").DefTag != "" {
+		t.Errorf("defaults: %v %v", si.Field("Flag").Default, si.Field("Bad").Default)
+	}
+	if FlatFieldTag(reflect.TypeOf(tiStruct{}), "tiEmbed", "def") != "" || FlatFieldTag(reflect.TypeOf(tiStruct{}), "Mbr", "def") != "0.5,0.1:1" {
+		t.Errorf("FlatFieldTag")
+	}
+
+	var s1, s2 tiStruct
+	err := SetFromDefaultTags(&s1)
+	SetFromDefaultTags(&s2)
+	if err == nil || s1.Size != 3 || s1.tiEmbed.Size != 2 || s1.Mbr != 0.5 || s1.Flag != TestFlags(2) || fmt.Sprint(s1.Names) != "[a b]" || s1.Map["a"] != 1 {
+		t.Errorf("SetFromDefaultTags: %+v %v", s1, err)
+	}
+	s1.Names[0] = "x"
+	s1.Map["a"] = 2
+	if s2.Names[0] != "a" || s2.Map["a"] != 1 {
+		t.Errorf("SetFromDefaultTags defaults should not be shared")
+	}
+}
@@ -34,6 +34,9 @@
 // determining if a given type embeds another type (directly or indirectly),
 // and iterating over fields to flatten the otherwise nested nature of the
 // field encoding in embedded types.
+//
+// * typeinfo.go: TypeInfo returns cached descriptors of the fields of a struct
+// type, flattened over embedded types, with parsed tags and default values.
 package kit
 
 import (
@@ -43,6 +46,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // TypeRegistry contains several maps where properties of types
@@ -147,6 +151,7 @@ func (tr *TypeRegistry) AddType(obj any, props map[string]any) reflect.Type {
 			nwprops[key] = val
 		}
 		tr.Props[lnm] = nwprops
+		TypePropsChanged()
 	}
 	// tr.InheritTypeProps(typ) // not actually that useful due to order dependencies.
 	return typ
@@ -260,6 +265,23 @@ func SetTypeProp(props map[string]any, key string, val any) {
 	TypesMu.Lock()
 	props[key] = val
 	TypesMu.Unlock()
+	TypePropsChanged()
+}
+
+// typePropsVersion counts changes to type properties, see TypePropsVersion
+var typePropsVersion int64
+
+// TypePropsVersion returns a counter that is incremented whenever type
+// properties are changed through AddType, SetProps or SetTypeProp, which
+// can be used to invalidate values cached from type properties.
+func TypePropsVersion() int64 {
+	return atomic.LoadInt64(&typePropsVersion)
+}
+
+// TypePropsChanged increments the TypePropsVersion -- must be called after
+// changing type property maps by other means than SetTypeProp.
+func TypePropsChanged() {
+	atomic.AddInt64(&typePropsVersion, 1)
 }
 
 // PropByName safely finds a type property from type name (using the long,
@@ -289,6 +311,7 @@ func (tr *TypeRegistry) SetProps(typ reflect.Type, props map[string]any) {
 	TypesMu.Lock()
 	defer TypesMu.Unlock()
 	tr.Props[LongTypeName(typ)] = props
+	TypePropsChanged()
 }
 
 // AllImplementersOf returns a list of all registered types that implement the
@@ -0,0 +1,381 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kit
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldRules are the validation rules for a field, parsed from its tags
+// (see Validate):
+//   - min:"1" max:"10" -- range of numbers, or of the length of strings,
+//     slices and maps.
+//   - def:"1:10" or def:"0.5,0.1:1" -- if the def tag of a number has
+//     ranges, the value must be one of its values or within one of its
+//     ranges, as for FieldInfo.IsDefault.
+//   - step:"0.5" -- numbers must be a multiple of step above the min (or 0).
+//   - required:"+" -- the value must not be the zero value (empty string,
+//     nil pointer, etc).
+//   - regex:"^[a-z]+$" -- strings must match the regular expression.
+//   - enum:"a,b,c" -- the value (as a string) must be one of the list.
+//
+// In addition, values of enum types registered in Enums must be valid
+// values of the enum.
+type FieldRules struct {
+
+	// minimum value, for numbers, or length, for strings, slices and maps -- only if HasMin
+	Min float64 `desc:"minimum value, for numbers, or length, for strings, slices and maps -- only if HasMin"`
+
+	// maximum value, for numbers, or length, for strings, slices and maps -- only if HasMax
+	Max float64 `desc:"maximum value, for numbers, or length, for strings, slices and maps -- only if HasMax"`
+
+	// numbers must be a multiple of step above the min (or 0) -- only if HasStep
+	Step float64 `desc:"numbers must be a multiple of step above the min (or 0) -- only if HasStep"`
+
+	// whether there is a min value
+	HasMin bool `desc:"whether there is a min value"`
+
+	// whether there is a max value
+	HasMax bool `desc:"whether there is a max value"`
+
+	// whether there is a step value
+	HasStep bool `desc:"whether there is a step value"`
+
+	// the value must not be the zero value
+	Required bool `desc:"the value must not be the zero value"`
+
+	// strings must match this regular expression
+	Regex *regexp.Regexp `desc:"strings must match this regular expression"`
+
+	// the value, as a string, must be one of these
+	Enum []string `desc:"the value, as a string, must be one of these"`
+
+	// numbers must be one of these default values or within one of DefRanges -- only if there are DefRanges
+	DefOptions []any `desc:"numbers must be one of these default values or within one of DefRanges -- only if there are DefRanges"`
+
+	// numbers must be within one of these ranges of default values from the def tag, or one of DefOptions
+	DefRanges []DefRange `desc:"numbers must be within one of these ranges of default values from the def tag, or one of DefOptions"`
+
+	// the def tag, for messages
+	def string
+}
+
+// setRules sets the validation rules from the field tags -- Rules is
+// nil if there are none, and RulesErr is set if the tags are not valid
+func (fi *FieldInfo) setRules() {
+	fr := &FieldRules{}
+	has := false
+	var errs []string
+	if len(fi.DefRanges) > 0 {
+		fr.DefOptions, fr.DefRanges, fr.def = fi.DefOptions, fi.DefRanges, fi.Tags["def"]
+		has = true
+	}
+	if s, ok := fi.Tags["min"]; ok {
+		fr.Min, fr.HasMin = parseRuleFloat("min", s, &errs)
+		has = true
+	}
+	if s, ok := fi.Tags["max"]; ok {
+		fr.Max, fr.HasMax = parseRuleFloat("max", s, &errs)
+		has = true
+	}
+	if s, ok := fi.Tags["step"]; ok {
+		fr.Step, fr.HasStep = parseRuleFloat("step", s, &errs)
+		if fr.HasStep && fr.Step <= 0 {
+			errs = append(errs, fmt.Sprintf("step must be > 0: %v", s))
+			fr.HasStep = false
+		}
+		has = true
+	}
+	if s, ok := fi.Tags["required"]; ok {
+		fr.Required = s != "-" && s != "false"
+		has = true
+	}
+	if s, ok := fi.Tags["regex"]; ok {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("regex: %v", err))
+		} else {
+			fr.Regex = re
+		}
+		has = true
+	}
+	if s, ok := fi.Tags["enum"]; ok {
+		for _, es := range strings.Split(s, ",") {
+			fr.Enum = append(fr.Enum, strings.TrimSpace(es))
+		}
+		has = true
+	}
+	if has {
+		fi.Rules = fr
+	}
+	if len(errs) > 0 {
+		fi.RulesErr = fmt.Errorf("invalid validation tags: %v", strings.Join(errs, "; "))
+	}
+}
+
+// parseRuleFloat parses a number from given tag, adding any error to errs
+func parseRuleFloat(tag, s string, errs *[]string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		*errs = append(*errs, fmt.Sprintf("%v: %q is not a number", tag, s))
+		return 0, false
+	}
+	return f, true
+}
+
+// ValidationError is an invalid value of a field, as returned by Validate
+type ValidationError struct {
+
+	// path to the field from the object validated, e.g., Pos.X or Items[2].Name
+	Path string `desc:"path to the field from the object validated, e.g., Pos.X or Items[2].Name"`
+
+	// the invalid value
+	Value any `desc:"the invalid value"`
+
+	// what is wrong with the value
+	Msg string `desc:"what is wrong with the value"`
+}
+
+// Error returns the path qualified message of the error
+func (ve *ValidationError) Error() string {
+	if ve.Path == "" {
+		return ve.Msg
+	}
+	return ve.Path + ": " + ve.Msg
+}
+
+// Validate checks the values of the exported fields of given struct (or
+// pointer to it) against the validation rules in their tags (see
+// FieldRules), recursing through struct fields, pointers to structs, and
+// slices, arrays and maps of them.  Ki fields and pointers to Ki nodes
+// are not included (see ki.ValidateFields).  Returns all of the errors
+// found, in field order (nil if valid).
+func Validate(obj any) []ValidationError {
+	if IfaceIsNil(obj) {
+		return nil
+	}
+	v := validator{visited: make(map[uintptr]bool)}
+	v.value(NonPtrValue(reflect.ValueOf(obj)), "")
+	return v.errs
+}
+
+// ValidateValue checks given value for the field against its validation
+// rules (see FieldRules) -- the value is converted to the type of the
+// field -- returns nil if valid.  Nested structs within the value are not
+// checked.
+func (fi *FieldInfo) ValidateValue(val any) error {
+	if fi.RulesErr != nil {
+		return &ValidationError{Path: fi.Path, Value: val, Msg: fi.RulesErr.Error()}
+	}
+	nv := reflect.New(fi.Type)
+	if !IfaceIsNil(val) && !SetRobust(nv.Interface(), val) {
+		return &ValidationError{Path: fi.Path, Value: val, Msg: fmt.Sprintf("value cannot be converted to type: %v", fi.Type)}
+	}
+	if msg := fi.Rules.check(nv.Elem()); msg != "" {
+		return &ValidationError{Path: fi.Path, Value: val, Msg: msg}
+	}
+	return nil
+}
+
+// validator accumulates errors for Validate
+type validator struct {
+	errs    []ValidationError
+	visited map[uintptr]bool
+}
+
+// value validates the fields of value at given path, if it is a struct,
+// or the elements of it for pointers, slices, arrays and maps
+func (v *validator) value(val reflect.Value, path string) {
+	switch val.Kind() {
+	case reflect.Pointer:
+		if val.IsNil() || isKiValType(val.Type()) || v.visited[val.Pointer()] {
+			return
+		}
+		v.visited[val.Pointer()] = true
+		v.value(val.Elem(), path)
+	case reflect.Struct:
+		v.fields(val, path)
+	case reflect.Slice, reflect.Array:
+		if !hasNestedFields(val.Type().Elem()) {
+			return
+		}
+		for i := 0; i < val.Len(); i++ {
+			v.value(val.Index(i), fmt.Sprintf("%v[%v]", path, i))
+		}
+	case reflect.Map:
+		if !hasNestedFields(val.Type().Elem()) {
+			return
+		}
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return ToString(keys[i].Interface()) < ToString(keys[j].Interface())
+		})
+		for _, k := range keys {
+			v.value(val.MapIndex(k), fmt.Sprintf("%v[%v]", path, ToString(k.Interface())))
+		}
+	}
+}
+
+// fields validates the fields of given struct value at given path
+func (v *validator) fields(val reflect.Value, path string) {
+	si := TypeInfo(val.Type())
+	for _, fi := range si.Fields {
+		if !fi.Exported || fi.IsKi {
+			continue
+		}
+		fv := val.FieldByIndex(fi.Index)
+		if !fv.CanInterface() { // in an unexported embedded struct
+			continue
+		}
+		fpath := fi.Path
+		if path != "" {
+			fpath = path + "." + fi.Path
+		}
+		switch {
+		case fi.RulesErr != nil:
+			v.errs = append(v.errs, ValidationError{Path: fpath, Value: fv.Interface(), Msg: fi.RulesErr.Error()})
+		default:
+			if msg := fi.Rules.check(fv); msg != "" {
+				v.errs = append(v.errs, ValidationError{Path: fpath, Value: fv.Interface(), Msg: msg})
+			}
+		}
+		v.value(fv, fpath)
+	}
+}
+
+// check returns a message if given value does not satisfy the rules,
+// or the value is not a valid enum value -- empty if valid.  Rules can
+// be nil, to only check enums.
+func (fr *FieldRules) check(val reflect.Value) string {
+	if fr != nil && fr.Required && val.IsZero() {
+		return "value is required"
+	}
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return ""
+		}
+		val = val.Elem()
+	}
+	typ := val.Type()
+	if typ.Name() != "" && Enums.TypeRegistered(typ) {
+		if msg := checkEnum(val); msg != "" {
+			return msg
+		}
+	}
+	if fr == nil {
+		return ""
+	}
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		f, _ := ToFloat(val.Interface())
+		if msg := fr.checkRange("value", f, val.Interface()); msg != "" {
+			return msg
+		}
+		if len(fr.DefRanges) > 0 && !inDefaults(fr.DefOptions, fr.DefRanges, val.Interface()) {
+			return fmt.Sprintf("value %v is not one of the default values or ranges: %v", val.Interface(), fr.def)
+		}
+		if fr.HasStep {
+			base := 0.0
+			if fr.HasMin {
+				base = fr.Min
+			}
+			r := (f - base) / fr.Step
+			if math.Abs(r-math.Round(r)) > 1.0e-6 {
+				return fmt.Sprintf("value %v is not a multiple of step %v", val.Interface(), fr.Step)
+			}
+		}
+	case reflect.String:
+		s := val.String()
+		if msg := fr.checkRange("length", float64(utf8.RuneCountInString(s)), s); msg != "" {
+			return msg
+		}
+		if fr.Regex != nil && !fr.Regex.MatchString(s) {
+			return fmt.Sprintf("value %q does not match regex: %v", s, fr.Regex)
+		}
+	case reflect.Slice, reflect.Map, reflect.Array:
+		if msg := fr.checkRange("length", float64(val.Len()), val.Len()); msg != "" {
+			return msg
+		}
+	}
+	if len(fr.Enum) > 0 {
+		s := ToString(val.Interface())
+		for _, es := range fr.Enum {
+			if s == es {
+				return ""
+			}
+		}
+		return fmt.Sprintf("value %q is not one of: %v", s, strings.Join(fr.Enum, ", "))
+	}
+	return ""
+}
+
+// checkRange returns a message if given number is outside of the min,
+// max range -- what is value or length, and val is the value to report
+func (fr *FieldRules) checkRange(what string, f float64, val any) string {
+	if fr.HasMin && f < fr.Min {
+		return fmt.Sprintf("%v %v is below min: %v", what, val, fr.Min)
+	}
+	if fr.HasMax && f > fr.Max {
+		return fmt.Sprintf("%v %v is above max: %v", what, val, fr.Max)
+	}
+	return ""
+}
+
+// checkEnum returns a message if given value of a registered enum type is
+// not a valid value -- bit flags can only have the defined bits set
+func checkEnum(val reflect.Value) string {
+	n := Enums.NVals(val.Interface())
+	if n <= 0 {
+		return ""
+	}
+	iv := EnumIfaceToInt64(val.Interface())
+	if Enums.IsBitFlag(val.Type()) {
+		if n < 64 && iv&^((int64(1)<<n)-1) != 0 {
+			return fmt.Sprintf("value %v has bits set that are not valid flags of enum type: %v", iv, ShortTypeName(val.Type()))
+		}
+		return ""
+	}
+	if iv < 0 || iv >= n {
+		return fmt.Sprintf("value %v is not a valid value of enum type: %v", iv, ShortTypeName(val.Type()))
+	}
+	return ""
+}
+
+// isKiValType returns true if given type is a Ki node or pointer to one
+func isKiValType(typ reflect.Type) bool {
+	typeInfos.mu.RLock()
+	kitype := KiType
+	typeInfos.mu.RUnlock()
+	if kitype == nil {
+		return false
+	}
+	typ = NonPtrType(typ)
+	return typ.Kind() == reflect.Struct && reflect.PtrTo(typ).Implements(kitype)
+}
+
+// hasNestedFields returns true if values of given element type can have
+// fields to validate: structs, or pointers, slices, arrays or maps of them,
+// other than Ki nodes
+func hasNestedFields(typ reflect.Type) bool {
+	for {
+		switch typ.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			typ = typ.Elem()
+		case reflect.Struct:
+			return !isKiValType(typ)
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type vtItem struct {
+	Name string `required:"+" regex:"^[a-z]+$"`
+}
+
+type vtStruct struct {
+	Size   int       `min:"1" max:"10"`
+	Ranged float32   `def:"0.5,0.1:1"`
+	Step   float64   `min:"1" step:"0.5"`
+	Mode   string    `enum:"fast, slow"`
+	Flag   TestFlags `desc:"registered enum"`
+	Items  []vtItem  `max:"3"`
+	Ptr    *vtItem
+	Map    map[string]vtItem
+	Bad    int `min:"x"`
+	Ok     int
+}
+
+func TestValidate(t *testing.T) {
+	vs := &vtStruct{Size: 5, Ranged: 0.5, Step: 2.5, Mode: "fast", Flag: TestFlag1, Items: []vtItem{{"a"}}, Ptr: &vtItem{"b"}, Map: map[string]vtItem{"x": {"c"}}}
+	errs := Validate(vs)
+	if len(errs) != 1 || errs[0].Path != "Bad" {
+		t.Errorf("only the bad tag should be reported: %v", errs)
+	}
+
+	vs.Size = 11
+	vs.Ranged = 0
+	vs.Step = 1.7
+	vs.Mode = "medium"
+	vs.Flag = TestFlagsN
+	vs.Items = []vtItem{{"a"}, {""}, {"B"}, {"d"}}
+	vs.Ptr.Name = "1"
+	vs.Map["y"] = vtItem{}
+	errs = Validate(vs)
+	var paths []string
+	for _, e := range errs {
+		paths = append(paths, e.Path)
+	}
+	want := "Size Ranged Step Mode Flag Items Items[1].Name Items[2].Name Ptr.Name Map[y].Name Bad"
+	if got := strings.Join(paths, " "); got != want {
+		t.Errorf("wrong error paths:\ngot:  %v\nwant: %v", got, want)
+	}
+	for _, e := range errs {
+		if e.Path == "Size" && e.Error() != "Size: value 11 is above max: 10" {
+			t.Errorf("wrong error message: %v", e.Error())
+		}
+	}
+
+	si := TypeInfo(reflect.TypeOf(vs))
+	if fi := si.Field("Size"); fi.ValidateValue("7") != nil || fi.ValidateValue(0) == nil {
+		t.Errorf("ValidateValue should check converted values against the range")
+	}
+	if fi := si.FieldByPath("Ptr.Name"); fi == nil || fi.ValidateValue("") == nil {
+		t.Errorf("FieldByPath should find the nested field, which is required")
+	}
+	if si.Field("Ok").Rules != nil || si.Field("Bad").RulesErr == nil {
+		t.Errorf("fields without rules should have nil Rules, and bad tags should have RulesErr")
+	}
+}
+
+type vtDefaults struct {
+	Opts  float32 `def:"1,2,5:10"`
+	Multi float64 `def:"0:1,5:10"`
+	Only  int     `def:"3:"`
+}
+
+func TestValidateDefaults(t *testing.T) {
+	vd := &vtDefaults{}
+	if err := SetFromDefaultTags(vd); err != nil {
+		t.Error(err)
+	}
+	if errs := Validate(vd); errs != nil {
+		t.Errorf("defaults should be valid: %v %v", errs, vd)
+	}
+	si := TypeInfo(reflect.TypeOf(vd))
+	for _, tv := range []struct {
+		field string
+		val   any
+		valid bool
+	}{{"Opts", 2, true}, {"Opts", 7, true}, {"Opts", 3, false}, {"Multi", 0.5, true}, {"Multi", 5, true}, {"Multi", 3, false}, {"Only", 2, false}, {"Only", 30, true}} {
+		fi := si.Field(tv.field)
+		nv := reflect.New(fi.Type)
+		SetRobust(nv.Interface(), tv.val)
+		if valid := fi.ValidateValue(tv.val) == nil; valid != tv.valid || fi.IsDefault(nv.Elem().Interface()) != tv.valid {
+			t.Errorf("%v = %v: valid %v, IsDefault %v, expected %v", tv.field, tv.val, valid, fi.IsDefault(nv.Elem().Interface()), tv.valid)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package toml
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/goki/ki/kit"
+)
+
+// ConfigSources are the layers of configuration that a value can come
+// from in LoadConfig, in the order they are applied.
+type ConfigSources int32
+
+//go:generate stringer -type=ConfigSources
+
+var KiT_ConfigSources = kit.Enums.AddEnum(ConfigSourcesN, kit.NotBitFlag, nil)
+
+const (
+	// ConfigDefault means the value is the default, from the def tag of
+	// the field, or the value the struct already had if there is none.
+	ConfigDefault ConfigSources = iota
+
+	// ConfigSystem means the value was set in the system TOML file.
+	ConfigSystem
+
+	// ConfigUser means the value was set in the user TOML file.
+	ConfigUser
+
+	// ConfigEnv means the value was set from an environment variable.
+	ConfigEnv
+
+	// ConfigFlag means the value was set from a command-line flag.
+	ConfigFlag
+
+	ConfigSourcesN
+)
+
+// ConfigOpts are the options for LoadConfig
+type ConfigOpts struct {
+
+	// the system-wide TOML config file, e.g., /etc/myapp.toml -- skipped if empty or it does not exist
+	SystemFile string `desc:"the system-wide TOML config file, e.g., /etc/myapp.toml -- skipped if empty or it does not exist"`
+
+	// the user TOML config file, e.g., ~/.config/myapp.toml -- skipped if empty or it does not exist
+	UserFile string `desc:"the user TOML config file, e.g., ~/.config/myapp.toml -- skipped if empty or it does not exist"`
+
+	// prefix for environment variables, which are named prefix + the upper-case field path with _ for ., e.g., MYAPP_ for MYAPP_POS_X for field Pos.X -- no environment variables are used if empty
+	EnvPrefix string `desc:"prefix for environment variables, which are named prefix + the upper-case field path with _ for ., e.g., MYAPP_ for MYAPP_POS_X for field Pos.X -- no environment variables are used if empty"`
+
+	// command-line arguments to parse flags from -- os.Args[1:] if nil
+	Args []string `desc:"command-line arguments to parse flags from -- os.Args[1:] if nil"`
+
+	// do not parse command-line flags
+	NoFlags bool `desc:"do not parse command-line flags"`
+
+	// the name of the program for the flag usage message -- os.Args[0] if empty
+	Name string `desc:"the name of the program for the flag usage message -- os.Args[0] if empty"`
+
+	// where the flag usage and error messages are written -- os.Stderr if nil
+	Output io.Writer `desc:"where the flag usage and error messages are written -- os.Stderr if nil"`
+}
+
+// ConfigSource records where the final value of a config field came from
+type ConfigSource struct {
+
+	// path of the field, with embedded struct fields flattened, e.g., Pos.X
+	Path string `desc:"path of the field, with embedded struct fields flattened, e.g., Pos.X"`
+
+	// the final value of the field
+	Value any `desc:"the final value of the field"`
+
+	// the layer the value came from
+	Source ConfigSources `desc:"the layer the value came from"`
+
+	// the file, environment variable or flag the value came from -- empty for ConfigDefault
+	From string `desc:"the file, environment variable or flag the value came from -- empty for ConfigDefault"`
+}
+
+// String returns a line for the field, e.g., Pos.X = 2 (ConfigEnv: MYAPP_POS_X)
+func (cs *ConfigSource) String() string {
+	if cs.From == "" {
+		return fmt.Sprintf("%v = %v (%v)", cs.Path, cs.Value, cs.Source)
+	}
+	return fmt.Sprintf("%v = %v (%v: %v)", cs.Path, cs.Value, cs.Source, cs.From)
+}
+
+// ConfigReport reports where each of the final values of the fields came
+// from, as returned by LoadConfig
+type ConfigReport struct {
+
+	// sources of all of the config fields, in field order
+	Fields []ConfigSource `desc:"sources of all of the config fields, in field order"`
+
+	// the remaining command-line arguments after the flags
+	Args []string `desc:"the remaining command-line arguments after the flags"`
+}
+
+// Source returns the source of the field at given path -- nil if not found
+func (cr *ConfigReport) Source(path string) *ConfigSource {
+	for i := range cr.Fields {
+		if cr.Fields[i].Path == path {
+			return &cr.Fields[i]
+		}
+	}
+	return nil
+}
+
+// String returns the report, with one line per field
+func (cr *ConfigReport) String() string {
+	var sb strings.Builder
+	for i := range cr.Fields {
+		sb.WriteString(cr.Fields[i].String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// configField is a leaf field of a config struct, which is also the
+// flag.Value for its flag
+type configField struct {
+	path   string
+	toml   []string // toml key names of the path
+	fi     *kit.FieldInfo
+	val    reflect.Value
+	source ConfigSources
+	from   string
+}
+
+// LoadConfig loads the config struct obj (a pointer) in layers, each
+// overriding the values set by the previous ones: the def tags of the
+// fields (see kit.SetFromDefaultTags), the system TOML file, the user TOML
+// file, environment variables and command-line flags.  Every field gets a
+// flag named as the lower-case field path, e.g., -pos.x for Pos.X, with
+// the desc tag as its help.  Fields of nested structs are included, and
+// fields with a toml:"-" tag are skipped.  Returns a report of where each
+// value came from -- the error is flag.ErrHelp if -h or -help was given.
+func LoadConfig(obj any, opts *ConfigOpts) (*ConfigReport, error) {
+	ov := reflect.ValueOf(obj)
+	if ov.Kind() != reflect.Pointer || ov.IsNil() || ov.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("toml.LoadConfig: obj must be a non-nil pointer to a struct, not: %T", obj)
+	}
+	if opts == nil {
+		opts = &ConfigOpts{}
+	}
+	if err := kit.SetFromDefaultTags(obj); err != nil {
+		return nil, err
+	}
+	var flds []*configField
+	configFields(ov.Elem(), "", nil, &flds)
+
+	cr := &ConfigReport{}
+	if err := loadConfigFile(obj, opts.SystemFile, ConfigSystem, flds); err != nil {
+		return cr, err
+	}
+	if err := loadConfigFile(obj, opts.UserFile, ConfigUser, flds); err != nil {
+		return cr, err
+	}
+	if opts.EnvPrefix != "" {
+		for _, cf := range flds {
+			env := opts.EnvPrefix + strings.ToUpper(strings.ReplaceAll(cf.path, ".", "_"))
+			if s, ok := os.LookupEnv(env); ok {
+				if err := setConfigString(cf.val, s); err != nil {
+					return cr, fmt.Errorf("toml.LoadConfig: environment variable %v: %v", env, err)
+				}
+				cf.source, cf.from = ConfigEnv, env
+			}
+		}
+	}
+	if !opts.NoFlags {
+		args, err := parseConfigFlags(opts, flds)
+		cr.Args = args
+		if err != nil {
+			return cr, err
+		}
+	}
+	for _, cf := range flds {
+		cr.Fields = append(cr.Fields, ConfigSource{Path: cf.path, Value: cf.val.Interface(), Source: cf.source, From: cf.from})
+	}
+	return cr, nil
+}
+
+// configFields adds the leaf fields of given struct value to flds
+func configFields(val reflect.Value, path string, keys []string, flds *[]*configField) {
+	si := kit.TypeInfo(val.Type())
+	for _, fi := range si.Fields {
+		if !fi.Exported || fi.Tags["toml"] == "-" {
+			continue
+		}
+		fv := val.FieldByIndex(fi.Index)
+		if !fv.CanSet() {
+			continue
+		}
+		fpath := fi.Name
+		if path != "" {
+			fpath = path + "." + fi.Name
+		}
+		key := append(append([]string(nil), keys...), fi.Name)
+		if tn, _, _ := strings.Cut(fi.Tags["toml"], ","); tn != "" {
+			key[len(key)-1] = tn
+		}
+		if fv.Kind() == reflect.Struct && !isTextType(fv.Type()) {
+			configFields(fv, fpath, key, flds)
+			continue
+		}
+		*flds = append(*flds, &configField{path: fpath, toml: key, fi: fi, val: fv})
+	}
+}
+
+// isTextType returns true if values of given type set themselves from
+// text, e.g., time.Time
+func isTextType(typ reflect.Type) bool {
+	return reflect.PtrTo(typ).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+// loadConfigFile loads obj from given TOML file if it exists, setting the
+// source of the fields it defines
+func loadConfigFile(obj any, file string, src ConfigSources, flds []*configField) error {
+	if file == "" {
+		return nil
+	}
+	b, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("toml.LoadConfig: %w", err)
+	}
+	md, err := toml.Decode(string(b), obj)
+	if err != nil {
+		return fmt.Errorf("toml.LoadConfig: file %v: %w", file, err)
+	}
+	for _, key := range md.Keys() {
+		for _, cf := range flds {
+			if configKeyMatch(cf.toml, key) {
+				cf.source, cf.from = src, file
+			}
+		}
+	}
+	return nil
+}
+
+// configKeyMatch returns true if the toml key is the key of the field
+// with given key names, matched without case, as in toml decoding --
+// the keys of the tables containing the field do not match
+func configKeyMatch(fkey []string, key toml.Key) bool {
+	if len(key) > len(fkey) {
+		return false
+	}
+	for i, k := range key {
+		if !strings.EqualFold(k, fkey[i]) {
+			return false
+		}
+	}
+	return len(key) == len(fkey)
+}
+
+// Set sets the field from the flag value
+func (cf *configField) Set(s string) error {
+	return setConfigString(cf.val, s)
+}
+
+// String returns the field value as a string
+func (cf *configField) String() string {
+	if cf == nil || !cf.val.IsValid() {
+		return ""
+	}
+	return kit.ToString(cf.val.Interface())
+}
+
+// IsBoolFlag allows bool fields to be set by the flag alone, e.g., -verbose
+func (cf *configField) IsBoolFlag() bool {
+	return cf.val.Kind() == reflect.Bool
+}
+
+// parseConfigFlags parses the command-line flags into the fields,
+// returning the remaining arguments
+func parseConfigFlags(opts *ConfigOpts, flds []*configField) ([]string, error) {
+	args := opts.Args
+	if args == nil {
+		args = os.Args[1:]
+	}
+	name := opts.Name
+	if name == "" {
+		name = os.Args[0]
+	}
+	flgs := flag.NewFlagSet(name, flag.ContinueOnError)
+	if opts.Output != nil {
+		flgs.SetOutput(opts.Output)
+	}
+	byName := make(map[string]*configField, len(flds))
+	for _, cf := range flds {
+		fnm := strings.ToLower(cf.path)
+		byName[fnm] = cf
+		flgs.Var(cf, fnm, cf.fi.Tag("desc"))
+	}
+	err := flgs.Parse(args)
+	flgs.Visit(func(f *flag.Flag) {
+		cf := byName[f.Name]
+		cf.source, cf.from = ConfigFlag, "-"+f.Name
+	})
+	return flgs.Args(), err
+}
+
+// setConfigString sets given field value from a string, for environment
+// variables and flags -- enums can be set by name
+func setConfigString(fv reflect.Value, s string) error {
+	pv := fv.Addr()
+	switch {
+	case isTextType(fv.Type()):
+		return pv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	case kit.Enums.TypeRegistered(fv.Type()):
+		return kit.Enums.SetAnyEnumValueFromString(pv, s)
+	case !kit.SetRobust(pv.Interface(), s):
+		return fmt.Errorf("could not set value of type %v from: %q", fv.Type(), s)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2023, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package toml
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type cfgPos struct {
+	X float32 `def:"1" desc:"horizontal position"`
+	Y float32 `def:"2"`
+}
+
+type cfgTest struct {
+	Name    string `def:"app" desc:"name of the app"`
+	Size    int    `def:"10"`
+	Verbose bool
+	Pos     cfgPos
+	Source  ConfigSources `toml:"src"`
+	Skip    int           `toml:"-"`
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	sys := filepath.Join(dir, "sys.toml")
+	usr := filepath.Join(dir, "user.toml")
+	os.WriteFile(sys, []byte("Name = \"sys\"\nSize = 20\n[Pos]\nx = 3\n"), 0644)
+	os.WriteFile(usr, []byte("Size = 30\nsrc = 2\n"), 0644)
+	t.Setenv("CFGTEST_POS_Y", "4")
+	t.Setenv("CFGTEST_NAME", "env")
+
+	cfg := &cfgTest{}
+	cr, err := LoadConfig(cfg, &ConfigOpts{SystemFile: sys, UserFile: usr, EnvPrefix: "CFGTEST_", Args: []string{"-name", "flag", "-verbose", "-source", "ConfigEnv", "rest"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "flag" || cfg.Size != 30 || !cfg.Verbose || cfg.Pos.X != 3 || cfg.Pos.Y != 4 || cfg.Source != ConfigEnv {
+		t.Errorf("wrong config values: %+v", cfg)
+	}
+	want := `Name = flag (ConfigFlag: -name)
+Size = 30 (ConfigUser: ` + usr + `)
+Verbose = true (ConfigFlag: -verbose)
+Pos.X = 3 (ConfigSystem: ` + sys + `)
+Pos.Y = 4 (ConfigEnv: CFGTEST_POS_Y)
+Source = ConfigEnv (ConfigFlag: -source)
+`
+	if got := cr.String(); got != want {
+		t.Errorf("wrong report:\ngot:\n%v\nwant:\n%v", got, want)
+	}
+	if len(cr.Args) != 1 || cr.Args[0] != "rest" {
+		t.Errorf("wrong remaining args: %v", cr.Args)
+	}
+
+	cfg = &cfgTest{}
+	cr, err = LoadConfig(cfg, &ConfigOpts{SystemFile: filepath.Join(dir, "none.toml"), Args: []string{}})
+	if err != nil || cfg.Size != 10 || cfg.Pos.Y != 2 || cr.Source("Size").Source != ConfigDefault || cr.Source("Skip") != nil {
+		t.Errorf("missing files should leave defaults: %+v %v", cfg, err)
+	}
+
+	cfg = &cfgTest{}
+	_, err = LoadConfig(cfg, &ConfigOpts{Args: []string{"-size", "x"}, Name: "cfgtest", Output: &bytes.Buffer{}})
+	if err == nil {
+		t.Errorf("invalid flag value should fail")
+	}
+}
+
+func TestLoadConfigHelp(t *testing.T) {
+	var b bytes.Buffer
+	cfg := &cfgTest{}
+	_, err := LoadConfig(cfg, &ConfigOpts{Args: []string{"-help"}, Name: "cfgtest", Output: &b})
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("expected flag.ErrHelp: %v", err)
+	}
+	if out := b.String(); !strings.Contains(out, "-pos.x") || !strings.Contains(out, "horizontal position") {
+		t.Errorf("help should list field flags with desc:\n%v", out)
+	}
+}